@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// maxCachedResponse is the size of the largest response body stored in
+// the API cache
+const maxCachedResponse = 16 << 20
+
+// CachedResponse is a forge API response kept to revalidate it with a
+// conditional request
+type CachedResponse struct {
+	// ETag and LastModified are the validators sent by the server
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+
+	// ContentType is the media type of the body
+	ContentType string `json:"content_type,omitempty"`
+
+	// Body is the data of the response
+	Body []byte `json:"body"`
+}
+
+// APICache stores the responses of forge APIs. Cached responses are
+// revalidated on each request, the server answers 304 (Not Modified)
+// instead of sending the data again when it did not change.
+// Implementations must be safe for concurrent use.
+type APICache interface {
+	// Get returns the response stored under key, if any
+	Get(key string) (*CachedResponse, bool)
+
+	// Put stores a response under key
+	Put(key string, resp *CachedResponse) error
+}
+
+// memoryAPICache keeps the cached responses in memory
+type memoryAPICache struct {
+	responses sync.Map
+}
+
+// NewMemoryAPICache returns an API cache keeping the responses in memory,
+// to share among the calls of a long running process.
+func NewMemoryAPICache() APICache {
+	return &memoryAPICache{}
+}
+
+func (m *memoryAPICache) Get(key string) (*CachedResponse, bool) {
+	v, ok := m.responses.Load(key)
+	if !ok {
+		return nil, false
+	}
+	return v.(*CachedResponse), true //nolint:forcetypeassert
+}
+
+func (m *memoryAPICache) Put(key string, resp *CachedResponse) error {
+	m.responses.Store(key, resp)
+	return nil
+}
+
+// dirAPICache writes the cached responses to files in a directory
+type dirAPICache struct {
+	dir string
+}
+
+// NewDirAPICache returns an API cache writing the responses to dir, so
+// they are reused across runs. The directory is created if needed.
+func NewDirAPICache(dir string) APICache {
+	return &dirAPICache{dir: dir}
+}
+
+func (d *dirAPICache) Get(key string) (*CachedResponse, bool) {
+	data, err := os.ReadFile(filepath.Join(d.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	resp := &CachedResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, false
+	}
+	return resp, true
+}
+
+func (d *dirAPICache) Put(key string, resp *CachedResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("marshaling cached response: %w", err)
+	}
+	if err := os.MkdirAll(d.dir, 0o700); err != nil {
+		return fmt.Errorf("creating API cache directory: %w", err)
+	}
+
+	// Responses are renamed into place so concurrent readers never see
+	// partial files
+	f, err := os.CreateTemp(d.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating cache file: %w", err)
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+	if _, err := f.Write(data); err != nil {
+		f.Close() //nolint:errcheck,gosec
+		return fmt.Errorf("writing cache file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing cache file: %w", err)
+	}
+	if err := os.Rename(f.Name(), filepath.Join(d.dir, key+".json")); err != nil {
+		return fmt.Errorf("storing cache file: %w", err)
+	}
+	return nil
+}
+
+// apiCacheKey returns the key of the responses to a request. The media
+// type requested is part of the key, as APIs serve different
+// representations of the same URL.
+func apiCacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept")))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachingTransport makes the requests conditional when a response to them
+// is cached, serving the cached data when the server answers 304.
+type cachingTransport struct {
+	base  http.RoundTripper
+	cache APICache
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+
+	key := apiCacheKey(req)
+	cached, ok := t.cache.Get(key)
+	if ok && (cached.ETag != "" || cached.LastModified != "") {
+		req = req.Clone(req.Context())
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	} else {
+		ok = false
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case ok && resp.StatusCode == http.StatusNotModified:
+		resp.Body.Close() //nolint:errcheck,gosec
+		header := resp.Header.Clone()
+		if cached.ContentType != "" {
+			header.Set("Content-Type", cached.ContentType)
+		}
+		header.Set("Content-Length", strconv.Itoa(len(cached.Body)))
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+			ContentLength: int64(len(cached.Body)),
+			Request:       req,
+			TLS:           resp.TLS,
+		}, nil
+	case resp.StatusCode == http.StatusOK && resp.ContentLength <= maxCachedResponse &&
+		(resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""):
+		resp.Body = &cacheRecorder{
+			ReadCloser: resp.Body,
+			cache:      t.cache,
+			key:        key,
+			resp: &CachedResponse{
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+				ContentType:  resp.Header.Get("Content-Type"),
+			},
+		}
+	}
+	return resp, nil
+}
+
+// cacheRecorder copies the data read from a response body, storing the
+// response in the cache once it is read to the end
+type cacheRecorder struct {
+	io.ReadCloser
+	cache APICache
+	key   string
+	resp  *CachedResponse
+	buf   bytes.Buffer
+	done  bool // set once stored or too large to store
+}
+
+func (r *cacheRecorder) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if r.done {
+		return n, err
+	}
+	if r.buf.Len()+n > maxCachedResponse {
+		r.done = true
+		r.buf = bytes.Buffer{}
+		return n, err
+	}
+	r.buf.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		r.done = true
+		r.resp.Body = r.buf.Bytes()
+		// The cache is an optimization, failing to write it is not an error
+		r.cache.Put(r.key, r.resp) //nolint:errcheck,gosec
+	}
+	return n, err
+}
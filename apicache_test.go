@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPICache(t *testing.T) {
+	t.Parallel()
+	modified := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat)
+
+	// newServer serves the GitHub contents API of example.com, using
+	// etags or modification times as validators
+	newServer := func(t *testing.T, useETag bool) (*http.Client, *atomic.Int32, *atomic.Int32) {
+		t.Helper()
+		var full, notModified atomic.Int32
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasSuffix(r.URL.Path, "/contents/SECURITY.md") {
+				http.NotFound(w, r)
+				return
+			}
+			if useETag {
+				w.Header().Set("ETag", `"v1"`)
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					notModified.Add(1)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			} else {
+				w.Header().Set("Last-Modified", modified)
+				if r.Header.Get("If-Modified-Since") == modified {
+					notModified.Add(1)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			}
+			full.Add(1)
+			w.Header().Set("Content-Type", gitHubRawMediaType)
+			w.Write([]byte("report issues privately\n")) //nolint:errcheck,gosec
+		}))
+		t.Cleanup(srv.Close)
+		return forgeTestClient(srv), &full, &notModified
+	}
+
+	locator := "git+https://example.com/org/repo@" + testCommit + "#SECURITY.md"
+	for _, tc := range []struct {
+		name    string
+		cache   func(t *testing.T) APICache
+		useETag bool
+	}{
+		{"memory-etag", func(*testing.T) APICache { return NewMemoryAPICache() }, true},
+		{"memory-last-modified", func(*testing.T) APICache { return NewMemoryAPICache() }, false},
+		{"dir-etag", func(t *testing.T) APICache { return NewDirAPICache(t.TempDir()) }, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			client, full, notModified := newServer(t, tc.useETag)
+			cache := tc.cache(t)
+			for range 3 {
+				var b bytes.Buffer
+				require.NoError(t, CopyFile(
+					locator, &b, WithHttpClient(client), WithGitHubEnterprise("example.com"), WithAPICache(cache),
+				))
+				require.Equal(t, "report issues privately\n", b.String())
+			}
+			require.Equal(t, int32(1), full.Load())
+			require.Equal(t, int32(2), notModified.Load())
+		})
+	}
+
+	t.Run("no-cache", func(t *testing.T) {
+		t.Parallel()
+		client, full, notModified := newServer(t, true)
+		for range 2 {
+			require.NoError(t, CopyFile(locator, &bytes.Buffer{}, WithHttpClient(client), WithGitHubEnterprise("example.com")))
+		}
+		require.Equal(t, int32(2), full.Load())
+		require.Zero(t, notModified.Load())
+	})
+
+	t.Run("dir-reopen", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		cache := NewDirAPICache(dir)
+		resp := &CachedResponse{ETag: `"v1"`, ContentType: "text/plain", Body: []byte("data")}
+		require.NoError(t, cache.Put("key", resp))
+
+		got, ok := NewDirAPICache(dir).Get("key")
+		require.True(t, ok)
+		require.Equal(t, resp, got)
+
+		_, ok = cache.Get("missing")
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,327 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// archiveEntry is a file, directory or symbolic link exported from a tree
+type archiveEntry struct {
+	// Path is the slash separated path of the entry in the repository
+	Path string
+	Mode filemode.FileMode
+	Hash plumbing.Hash
+}
+
+// isDir returns true if the entry is a directory
+func (e *archiveEntry) isDir() bool {
+	return e.Mode == filemode.Dir
+}
+
+// archiveEntries lists the entries of the subtree referenced by the
+// locator of a cloned repository, applying the include/exclude filters and
+// export-ignore attributes in the options. Directories are listed before
+// their contents and only if they contain a listed file.
+func archiveEntries(cloned *clonedRepo, opts *options) ([]archiveEntry, error) {
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+	root, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit tree: %w", err)
+	}
+
+	var ignore *exportIgnoreMatcher
+	if opts.ExportIgnore {
+		if ignore, err = newExportIgnoreMatcher(cloned.FS); err != nil {
+			return nil, err
+		}
+	}
+
+	subpath := strings.Trim(cloned.Components.SubPath, "/")
+	tree := root
+	if subpath != "" {
+		entry, err := root.FindEntry(subpath)
+		if err != nil {
+			return nil, fmt.Errorf("looking up %q: %w", subpath, err)
+		}
+		switch entry.Mode {
+		case filemode.Dir:
+			if tree, err = root.Tree(subpath); err != nil {
+				return nil, fmt.Errorf("reading tree %q: %w", subpath, err)
+			}
+		case filemode.Submodule:
+			return nil, fmt.Errorf("%q is a submodule", subpath)
+		default:
+			if !matchFilters(path.Base(subpath), opts.Include, opts.Exclude) || ignore.ignored(subpath) {
+				return []archiveEntry{}, nil
+			}
+			return withParentDirs([]archiveEntry{{Path: subpath, Mode: entry.Mode, Hash: entry.Hash}}), nil
+		}
+	}
+
+	entries := []archiveEntry{}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("walking tree: %w", err)
+		}
+
+		// Submodule commits are not part of the repository
+		if entry.Mode == filemode.Submodule || entry.Mode == filemode.Dir {
+			continue
+		}
+
+		p := path.Join(subpath, name)
+		if !matchFilters(name, opts.Include, opts.Exclude) || ignore.ignored(p) {
+			continue
+		}
+		entries = append(entries, archiveEntry{Path: p, Mode: entry.Mode, Hash: entry.Hash})
+	}
+	return withParentDirs(entries), nil
+}
+
+// withParentDirs adds the entries of the directories containing the files
+// in the list, each one before the first file in it.
+func withParentDirs(files []archiveEntry) []archiveEntry {
+	seen := map[string]struct{}{}
+	ret := make([]archiveEntry, 0, len(files))
+	for _, f := range files {
+		dirs := []archiveEntry{}
+		for dir := path.Dir(f.Path); dir != "."; dir = path.Dir(dir) {
+			if _, ok := seen[dir]; ok {
+				break
+			}
+			seen[dir] = struct{}{}
+			dirs = append([]archiveEntry{{Path: dir, Mode: filemode.Dir}}, dirs...)
+		}
+		ret = append(ret, dirs...)
+		ret = append(ret, f)
+	}
+	return ret
+}
+
+// prepareArchive clones the repository referenced by the locator and lists
+// the entries to archive. It also returns the commit date to timestamp them.
+// The caller must close the returned repository.
+func prepareArchive(l Locator, funcs ...fnOpt) (*clonedRepo, []archiveEntry, time.Time, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, nil, time.Time{}, err
+		}
+	}
+
+	cloned, err := cloneRepo(l, &opts, funcs...)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("cloning repository: %w", err)
+	}
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		cloned.Close() //nolint:errcheck,gosec
+		return nil, nil, time.Time{}, fmt.Errorf("reading commit: %w", err)
+	}
+
+	entries, err := archiveEntries(cloned, &opts)
+	if err != nil {
+		cloned.Close() //nolint:errcheck,gosec
+		return nil, nil, time.Time{}, err
+	}
+
+	// Archives are reproducible: entries are sorted by path and all of them
+	// get the same timestamp, in UTC and with second precision.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	mtime := commit.Committer.When
+	if !opts.ArchiveMtime.IsZero() {
+		mtime = opts.ArchiveMtime
+	}
+	return cloned, entries, mtime.UTC().Truncate(time.Second), nil
+}
+
+// openBlob opens the data of a file entry returning the blob size
+func openBlob(cloned *clonedRepo, entry *archiveEntry) (io.ReadCloser, int64, error) {
+	blob, err := cloned.Repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading blob of %q: %w", entry.Path, err)
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening blob of %q: %w", entry.Path, err)
+	}
+	return r, blob.Size, nil
+}
+
+// CopyTar writes the subtree referenced by the locator to w as a tar
+// stream. The archive preserves the executable bits and symbolic links of
+// the repository. Locators without a subpath export the whole tree. The
+// include/exclude filters and export-ignore options are honored.
+//
+// The output is reproducible: archives of the same tree are byte for byte
+// identical. Entries are sorted, owned by root and timestamped with the
+// commit date unless a date is set with WithArchiveMtime.
+func CopyTar[T ~string](locator T, w io.Writer, funcs ...fnOpt) error {
+	cloned, entries, mtime, err := prepareArchive(Locator(locator), funcs...)
+	if err != nil {
+		return err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	tw := tar.NewWriter(w)
+	for i := range entries {
+		if err := writeTarEntry(tw, cloned, &entries[i], mtime); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing tar stream: %w", err)
+	}
+	return nil
+}
+
+// stableTarHeader sets the fields of the header that could vary between
+// runs to fixed values: the owner is always root and only the modification
+// time is recorded.
+func stableTarHeader(hdr *tar.Header, mtime time.Time) *tar.Header {
+	hdr.ModTime = mtime
+	hdr.Uid = 0
+	hdr.Gid = 0
+	hdr.Uname = "root"
+	hdr.Gname = "root"
+	hdr.Format = tar.FormatPAX
+	return hdr
+}
+
+// writeTarEntry writes an entry and its data to the tar stream
+func writeTarEntry(tw *tar.Writer, cloned *clonedRepo, entry *archiveEntry, mtime time.Time) error {
+	if entry.isDir() {
+		if err := tw.WriteHeader(stableTarHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     entry.Path + "/",
+			Mode:     0o755,
+		}, mtime)); err != nil {
+			return fmt.Errorf("writing header of %q: %w", entry.Path, err)
+		}
+		return nil
+	}
+
+	r, size, err := openBlob(cloned, entry)
+	if err != nil {
+		return err
+	}
+	defer r.Close() //nolint:errcheck
+
+	if entry.Mode == filemode.Symlink {
+		target, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("reading link %q: %w", entry.Path, err)
+		}
+		if err := tw.WriteHeader(stableTarHeader(&tar.Header{
+			Typeflag: tar.TypeSymlink,
+			Name:     entry.Path,
+			Linkname: string(target),
+			Mode:     0o777,
+		}, mtime)); err != nil {
+			return fmt.Errorf("writing header of %q: %w", entry.Path, err)
+		}
+		return nil
+	}
+
+	if err := tw.WriteHeader(stableTarHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     entry.Path,
+		Size:     size,
+		Mode:     int64(entryPerm(entry.Mode)),
+	}, mtime)); err != nil {
+		return fmt.Errorf("writing header of %q: %w", entry.Path, err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("writing data of %q: %w", entry.Path, err)
+	}
+	return nil
+}
+
+// CopyZip writes the subtree referenced by the locator to w as a zip
+// archive. It exports the same entries as CopyTar: executable bits are
+// recorded in the file modes and symbolic links are stored as links with
+// their target as contents, as Info-ZIP does. Zip archives are reproducible
+// too.
+func CopyZip[T ~string](locator T, w io.Writer, funcs ...fnOpt) error {
+	cloned, entries, mtime, err := prepareArchive(Locator(locator), funcs...)
+	if err != nil {
+		return err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	zw := zip.NewWriter(w)
+	for i := range entries {
+		if err := writeZipEntry(zw, cloned, &entries[i], mtime); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("closing zip archive: %w", err)
+	}
+	return nil
+}
+
+// writeZipEntry writes an entry and its data to the zip archive
+func writeZipEntry(zw *zip.Writer, cloned *clonedRepo, entry *archiveEntry, mtime time.Time) error {
+	hdr := &zip.FileHeader{
+		Name:     entry.Path,
+		Method:   zip.Deflate,
+		Modified: mtime,
+	}
+	switch entry.Mode {
+	case filemode.Dir:
+		hdr.Name += "/"
+		hdr.Method = zip.Store
+		hdr.SetMode(fs.ModeDir | 0o755)
+	case filemode.Symlink:
+		hdr.SetMode(fs.ModeSymlink | 0o777)
+	default:
+		hdr.SetMode(entryPerm(entry.Mode))
+	}
+
+	fw, err := zw.CreateHeader(hdr)
+	if err != nil {
+		return fmt.Errorf("writing header of %q: %w", entry.Path, err)
+	}
+	if entry.isDir() {
+		return nil
+	}
+
+	r, _, err := openBlob(cloned, entry)
+	if err != nil {
+		return err
+	}
+	defer r.Close() //nolint:errcheck
+
+	if _, err := io.Copy(fw, r); err != nil {
+		return fmt.Errorf("writing data of %q: %w", entry.Path, err)
+	}
+	return nil
+}
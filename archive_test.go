@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// initArchiveTestRepo creates a repository with an executable, a symbolic
+// link and nested directories to test the archive exporters.
+func initArchiveTestRepo(t *testing.T) (repoDir, commit string) {
+	t.Helper()
+	repoDir, _ = initTestRepoWithFiles(t, map[string]string{
+		"README.md":            "readme",
+		"pkg/main.go":          "package main\n",
+		"pkg/sub/lib.go":       "package sub\n",
+		"pkg/sub/data.bin":     "binary",
+		"pkg/testdata/in.yaml": "in",
+	})
+	if runtime.GOOS != "windows" {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "pkg", "run.sh"), nil, 0o700)) //nolint:gosec
+	}
+	commitTestFile(t, repoDir, "pkg/run.sh", "#!/bin/sh\n")
+	commit = commitTestSymlinks(t, repoDir, map[string]string{"pkg/link": "main.go"})
+	return repoDir, commit
+}
+
+// tarContents reads a tar stream into a map of headers and a map of data
+func tarContents(t *testing.T, data []byte) (names []string, headers map[string]*tar.Header, contents map[string]string) {
+	t.Helper()
+	headers = map[string]*tar.Header{}
+	contents = map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+		headers[hdr.Name] = hdr
+		b, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		contents[hdr.Name] = string(b)
+	}
+	return names, headers, contents
+}
+
+func TestCopyTar(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commit := initArchiveTestRepo(t)
+
+	t.Run("subtree", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, CopyTar(fileLocator(repoDir, commit, "pkg"), &buf, noAuth))
+
+		names, headers, contents := tarContents(t, buf.Bytes())
+		require.Equal(t, []string{
+			"pkg/", "pkg/link", "pkg/main.go", "pkg/run.sh", "pkg/sub/", "pkg/sub/data.bin",
+			"pkg/sub/lib.go", "pkg/testdata/", "pkg/testdata/in.yaml",
+		}, names)
+		require.Equal(t, "package main\n", contents["pkg/main.go"])
+		require.Equal(t, byte(tar.TypeDir), headers["pkg/sub/"].Typeflag)
+		require.Equal(t, byte(tar.TypeSymlink), headers["pkg/link"].Typeflag)
+		require.Equal(t, "main.go", headers["pkg/link"].Linkname)
+		require.Equal(t, int64(0o644), headers["pkg/main.go"].Mode)
+		if runtime.GOOS != "windows" {
+			require.Equal(t, int64(0o755), headers["pkg/run.sh"].Mode)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, CopyTar(
+			fileLocator(repoDir, commit, "pkg"), &buf, noAuth,
+			WithInclude("**/*.go"), WithExclude("sub/**"),
+		))
+		names, _, _ := tarContents(t, buf.Bytes())
+		require.Equal(t, []string{"pkg/", "pkg/main.go"}, names)
+	})
+
+	t.Run("single-file", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, CopyTar(fileLocator(repoDir, commit, "pkg/sub/lib.go"), &buf, noAuth))
+		names, _, contents := tarContents(t, buf.Bytes())
+		require.Equal(t, []string{"pkg/", "pkg/sub/", "pkg/sub/lib.go"}, names)
+		require.Equal(t, "package sub\n", contents["pkg/sub/lib.go"])
+	})
+
+	t.Run("whole-repo", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, CopyTar(fileLocator(repoDir, commit, ""), &buf, noAuth))
+		names, _, _ := tarContents(t, buf.Bytes())
+		require.Contains(t, names, "README.md")
+		require.Contains(t, names, "pkg/sub/lib.go")
+	})
+}
+
+func TestCopyZip(t *testing.T) {
+	t.Parallel()
+
+	repoDir, commit := initArchiveTestRepo(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, CopyZip(fileLocator(repoDir, commit, "pkg"), &buf, WithSystemCredentials(false)))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	names := []string{}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		files[f.Name] = f
+	}
+	require.Equal(t, []string{
+		"pkg/", "pkg/link", "pkg/main.go", "pkg/run.sh", "pkg/sub/", "pkg/sub/data.bin",
+		"pkg/sub/lib.go", "pkg/testdata/", "pkg/testdata/in.yaml",
+	}, names)
+
+	require.True(t, files["pkg/sub/"].Mode().IsDir())
+	require.Equal(t, fs.ModeSymlink, files["pkg/link"].Mode().Type())
+	require.Equal(t, fs.FileMode(0o644), files["pkg/main.go"].Mode())
+	if runtime.GOOS != "windows" {
+		require.Equal(t, fs.FileMode(0o755), files["pkg/run.sh"].Mode())
+	}
+
+	for name, expected := range map[string]string{"pkg/link": "main.go", "pkg/sub/lib.go": "package sub\n"} {
+		r, err := files[name].Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.Equal(t, expected, string(data))
+	}
+}
+
+func TestReproducibleArchives(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commit := initArchiveTestRepo(t)
+	locator := fileLocator(repoDir, commit, "")
+
+	for name, copyFn := range map[string]func(string, io.Writer, ...fnOpt) error{
+		"tar": CopyTar[string],
+		"zip": CopyZip[string],
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var first, second bytes.Buffer
+			require.NoError(t, copyFn(locator, &first, noAuth))
+			require.NoError(t, copyFn(locator, &second, noAuth))
+			require.Equal(t, first.Bytes(), second.Bytes())
+
+			var fixed bytes.Buffer
+			require.NoError(t, copyFn(locator, &fixed, noAuth, WithArchiveMtime(time.Unix(1700000000, 0))))
+			require.NotEqual(t, first.Bytes(), fixed.Bytes())
+		})
+	}
+
+	var buf bytes.Buffer
+	mtime := time.Unix(1700000000, 500)
+	require.NoError(t, CopyTar(locator, &buf, noAuth, WithArchiveMtime(mtime)))
+	names, headers, _ := tarContents(t, buf.Bytes())
+	require.True(t, sort.StringsAreSorted(names))
+	for _, hdr := range headers {
+		require.True(t, hdr.ModTime.Equal(time.Unix(1700000000, 0)), hdr.Name)
+		require.Zero(t, hdr.Uid)
+		require.Zero(t, hdr.Gid)
+		require.Equal(t, "root", hdr.Uname)
+	}
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	xssh "golang.org/x/crypto/ssh"
+)
+
+// askPassPrompts are the credential prompts of the askpass programs, kept
+// to remember their answers across operations
+var askPassPrompts sync.Map
+
+// askPass runs an askpass program with a prompt and returns the line it
+// prints to stdout
+func askPass(program, prompt string) (string, error) {
+	cmd := exec.Command(program, prompt)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("running askpass program %q: %w", program, err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// askPassCredentials returns the credential prompt running an askpass
+// program. Like git, the program is asked for the username first and then
+// for the password.
+func askPassCredentials(program string) *credentialPrompt {
+	if p, ok := askPassPrompts.Load(program); ok {
+		return p.(*credentialPrompt) //nolint:forcetypeassert
+	}
+	p, _ := askPassPrompts.LoadOrStore(program, newCredentialPrompt(func(host string) (string, string, error) {
+		user, err := askPass(program, fmt.Sprintf("Username for 'https://%s': ", host))
+		if err != nil {
+			return "", "", err
+		}
+		password, err := askPass(program, fmt.Sprintf("Password for 'https://%s@%s': ", user, host))
+		if err != nil {
+			return "", "", err
+		}
+		return user, password, nil
+	}))
+	return p.(*credentialPrompt) //nolint:forcetypeassert
+}
+
+// keyNeedsPassphrase checks if a private key is encrypted
+func keyNeedsPassphrase(pemBytes []byte) bool {
+	_, err := xssh.ParseRawPrivateKey(pemBytes)
+	var missing *xssh.PassphraseMissingError
+	return errors.As(err, &missing)
+}
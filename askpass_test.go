@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/require"
+	xssh "golang.org/x/crypto/ssh"
+)
+
+// writeAskPass writes an askpass script answering the prompts of git and ssh
+func writeAskPass(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("askpass test script needs a POSIX shell")
+	}
+	program := filepath.Join(t.TempDir(), "askpass")
+	require.NoError(t, os.WriteFile(program, []byte(`#!/bin/sh
+case "$1" in
+  Username*) echo user ;;
+  Password*) echo pass ;;
+  "Enter passphrase"*) echo secret ;;
+  *) exit 1 ;;
+esac
+`), 0o700))
+	return program
+}
+
+func TestAskPassHTTP(t *testing.T) {
+	t.Parallel()
+	program := writeAskPass(t)
+	for _, tc := range []struct {
+		name   string
+		funcs  []fnOpt
+		expect any
+	}{
+		{"askpass", []fnOpt{WithAskPass(program)}, &githttp.BasicAuth{Username: "user", Password: "pass"}},
+		{"no-askpass", nil, nil},
+		{"prompt", []fnOpt{WithAskPass(program), WithCredentialPrompt(func(string) (string, string, error) {
+			return "other", "secret", nil
+		})}, &githttp.BasicAuth{Username: "other", Password: "secret"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			auth, err := promptedAuth(t, "git+https://git.example.com/org/private", func(auth transport.AuthMethod) error {
+				if auth == nil && tc.expect != nil {
+					return transport.ErrAuthenticationRequired
+				}
+				return nil
+			}, append([]fnOpt{WithSystemCredentials(false)}, tc.funcs...)...)
+			require.NoError(t, err)
+			if tc.expect == nil {
+				require.Nil(t, auth)
+				return
+			}
+			require.Equal(t, tc.expect, auth)
+		})
+	}
+}
+
+func TestLoadSSHKeyAskPass(t *testing.T) {
+	t.Parallel()
+	program := writeAskPass(t)
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := xssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("secret"))
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+
+	_, err = loadSSHKey(keyPath, "")
+	require.Error(t, err)
+
+	auth, err := loadSSHKey(keyPath, program)
+	require.NoError(t, err)
+	require.NotNil(t, auth)
+}
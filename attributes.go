@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+)
+
+// attrExportIgnore is the attribute git archive uses to leave paths out
+const attrExportIgnore = "export-ignore"
+
+// exportIgnoreMatcher reads the .gitattributes files in a checked out tree
+// to match the paths excluded from exports.
+type exportIgnoreMatcher struct {
+	matcher gitattributes.Matcher
+}
+
+// newExportIgnoreMatcher loads the attribute files in the filesystem
+func newExportIgnoreMatcher(fsys billy.Filesystem) (*exportIgnoreMatcher, error) {
+	patterns, err := gitattributes.ReadPatterns(fsys, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading gitattributes: %w", err)
+	}
+	return &exportIgnoreMatcher{matcher: gitattributes.NewMatcher(patterns)}, nil
+}
+
+// ignored returns true if the path or any of its parent directories is
+// marked with the export-ignore attribute. A nil matcher ignores nothing.
+func (m *exportIgnoreMatcher) ignored(p string) bool {
+	if m == nil {
+		return false
+	}
+	elements := strings.Split(p, "/")
+	for i := range elements {
+		attrs, _ := m.matcher.Match(elements[:i+1], []string{attrExportIgnore})
+		if attr, ok := attrs[attrExportIgnore]; ok && attr.IsSet() {
+			return true
+		}
+	}
+	return false
+}
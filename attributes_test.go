@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadExportIgnore(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		".gitattributes":          "*.bin export-ignore\ntestdata export-ignore\n",
+		"src/main.go":             "package main\n",
+		"src/blob.bin":            "binary",
+		"src/testdata/in.txt":     "fixture",
+		"src/docs/.gitattributes": "draft.md export-ignore\n",
+		"src/docs/draft.md":       "draft",
+		"src/docs/guide.md":       "guide",
+	})
+	locator := fileLocator(repoDir, commit, "src")
+
+	dest := t.TempDir()
+	require.NoError(t, Download(locator, dest, noAuth, WithExportIgnore(true)))
+	require.FileExists(t, filepath.Join(dest, "src", "main.go"))
+	require.FileExists(t, filepath.Join(dest, "src", "docs", "guide.md"))
+	require.NoFileExists(t, filepath.Join(dest, "src", "blob.bin"))
+	require.NoDirExists(t, filepath.Join(dest, "src", "testdata"))
+	require.NoFileExists(t, filepath.Join(dest, "src", "docs", "draft.md"))
+
+	dest = t.TempDir()
+	require.NoError(t, Download(locator, dest, noAuth))
+	require.FileExists(t, filepath.Join(dest, "src", "blob.bin"))
+	require.FileExists(t, filepath.Join(dest, "src", "testdata", "in.txt"))
+	require.FileExists(t, filepath.Join(dest, "src", "docs", "draft.md"))
+}
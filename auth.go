@@ -4,6 +4,7 @@
 package vcslocator
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -14,6 +15,10 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 )
 
+// tokenUsername is the username sent with bare tokens, forges only check
+// the token
+const tokenUsername = "x-access-token"
+
 // getAuthMethod returns an appropriate auth method based on the transport type
 // and available credentials.
 //
@@ -27,19 +32,29 @@ func GetAuthMethod[T ~string](locator T, funcs ...fnOpt) (transport.AuthMethod,
 		}
 	}
 
-	l := Locator(locator)
-	components, err := l.Parse()
+	components, err := Locator(locator).Parse(funcs...)
 	if err != nil {
 		return nil, err
 	}
+	return authMethod(components, &opts)
+}
+
+// authMethod returns the auth method to talk to the remote of the parsed
+// components. The method set with WithAuthMethod takes precedence over the
+// detected ones.
+func authMethod(components *Components, opts *options) (transport.AuthMethod, error) {
+	if components.Transport == TransportFile {
+		return nil, nil // No auth needed for local file:// repos
+	}
+	if opts.AuthMethod != nil {
+		return opts.AuthMethod, nil
+	}
 
 	switch components.Transport {
 	case TransportSSH:
-		return getSSHAuth()
+		return getSSHAuth(opts.AskPass)
 	case TransportHTTPS:
-		return getHTTPAuth(&opts), nil
-	case TransportFile:
-		return nil, nil // No auth needed for local file:// repos
+		return getHTTPAuth(opts), nil
 	default:
 		return nil, nil
 	}
@@ -48,7 +63,9 @@ func GetAuthMethod[T ~string](locator T, funcs ...fnOpt) (transport.AuthMethod,
 // getSSHAuth returns SSH authentication, trying in order:
 // 1. SSH agent
 // 2. Default SSH keys (~/.ssh/id_rsa, ~/.ssh/id_ed25519, ~/.ssh/id_ecdsa)
-func getSSHAuth() (transport.AuthMethod, error) {
+//
+// The passphrase of encrypted keys is read from the askpass program, if set.
+func getSSHAuth(askpass string) (transport.AuthMethod, error) {
 	// Try SSH agent first (like git does)
 	auth, err := ssh.NewSSHAgentAuth("git")
 	if err == nil {
@@ -77,8 +94,7 @@ func getSSHAuth() (transport.AuthMethod, error) {
 			continue
 		}
 
-		// Try to load the key (without password first)
-		auth, err := ssh.NewPublicKeysFromFile("git", keyPath, "")
+		auth, err := loadSSHKey(keyPath, askpass)
 		if err == nil {
 			return auth, nil
 		}
@@ -93,6 +109,34 @@ func getSSHAuth() (transport.AuthMethod, error) {
 	return nil, errors.New("no SSH authentication method available")
 }
 
+// loadSSHKey loads a private key file. Like ssh, the passphrase of encrypted
+// keys is read from the askpass program, if one is set.
+func loadSSHKey(keyPath, askpass string) (transport.AuthMethod, error) {
+	pemBytes, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading key: %w", err)
+	}
+
+	passphrase := ""
+	if askpass != "" && keyNeedsPassphrase(pemBytes) {
+		passphrase, err = askPass(askpass, fmt.Sprintf("Enter passphrase for key '%s': ", keyPath))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ssh.NewPublicKeys("git", pemBytes, passphrase)
+}
+
+// tokenSourceAuth gets a fresh token from the token source set in the
+// options
+func tokenSourceAuth(opts *options) (transport.AuthMethod, error) {
+	token, err := opts.TokenSource(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("getting token from source: %w", err)
+	}
+	return &http.BasicAuth{Username: tokenUsername, Password: token}, nil
+}
+
 // getHTTPAuth returns HTTP an authenticator using the credentials configured
 // in the options
 func getHTTPAuth(opts *options) transport.AuthMethod {
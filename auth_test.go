@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAuthMethod(t *testing.T) {
+	t.Parallel()
+	token := &githttp.TokenAuth{Token: "secret"}
+	for _, tc := range []struct {
+		name    string
+		locator string
+		funcs   []fnOpt
+		expect  transport.AuthMethod
+	}{
+		{"https-no-credentials", "git+https://git.example.com/org/repo", nil, nil},
+		{"https-basic", "git+https://git.example.com/org/repo", []fnOpt{WithHttpAuth("user", "pass")}, &githttp.BasicAuth{Username: "user", Password: "pass"}},
+		{"https-override", "git+https://git.example.com/org/repo", []fnOpt{WithHttpAuth("user", "pass"), WithAuthMethod(token)}, token},
+		{"ssh-override", "git+ssh://git.example.com/org/repo", []fnOpt{WithAuthMethod(token)}, token},
+		{"file", "git+file:///srv/repo", []fnOpt{WithAuthMethod(token)}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			auth, err := GetAuthMethod(tc.locator, tc.funcs...)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, auth)
+		})
+	}
+}
+
+func TestTokenSource(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	source := func(context.Context) (string, error) {
+		return fmt.Sprintf("token-%d", calls.Add(1)), nil
+	}
+	auth := func(locator string, funcs ...fnOpt) (transport.AuthMethod, error) {
+		opts := defaultOptions
+		for _, fn := range funcs {
+			require.NoError(t, fn(&opts))
+		}
+		l := Locator(locator)
+		c, err := l.Parse()
+		require.NoError(t, err)
+		return prepareRemote(l, c, &opts)
+	}
+
+	// The source is called again on each operation
+	for i := 1; i <= 2; i++ {
+		a, err := auth("git+https://git.example.com/org/repo", WithHttpAuth("user", "pass"), WithTokenSource(source))
+		require.NoError(t, err)
+		require.Equal(t, &githttp.BasicAuth{Username: "x-access-token", Password: fmt.Sprintf("token-%d", i)}, a)
+	}
+
+	// Errors of the source are returned
+	_, err := auth("git+https://git.example.com/org/repo", WithTokenSource(func(context.Context) (string, error) {
+		return "", errors.New("token expired")
+	}))
+	require.ErrorContains(t, err, "token expired")
+
+	// Local repositories don't need a token
+	a, err := auth("git+file:///srv/repo", WithTokenSource(source))
+	require.NoError(t, err)
+	require.Nil(t, a)
+	require.Equal(t, int32(2), calls.Load())
+}
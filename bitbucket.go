@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+const (
+	// bitbucketCloudHost is the hostname of Bitbucket Cloud, its API is
+	// served from api.bitbucket.org
+	bitbucketCloudHost = "bitbucket.org"
+
+	// bitbucketCloudAPI is the base URL of the Bitbucket Cloud API
+	bitbucketCloudAPI = "https://api.bitbucket.org/2.0"
+)
+
+// BitbucketCloudProvider fetches data through the Bitbucket Cloud API
+type BitbucketCloudProvider struct{}
+
+// Name returns the name of the forge
+func (BitbucketCloudProvider) Name() string {
+	return "bitbucket-cloud"
+}
+
+// BitbucketServerProvider fetches data through the REST API of Bitbucket
+// Server and Data Center instances
+type BitbucketServerProvider struct{}
+
+// Name returns the name of the forge
+func (BitbucketServerProvider) Name() string {
+	return "bitbucket-server"
+}
+
+// OpenFile requests a file from the source endpoint of the
+// Bitbucket Cloud API
+func (BitbucketCloudProvider) OpenFile(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	workspace, repo, err := ownerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf(
+		"%s/repositories/%s/%s/src/%s/%s", bitbucketCloudAPI,
+		url.PathEscape(workspace), url.PathEscape(repo), url.PathEscape(c.Commit), escapePath(c.SubPath),
+	)
+	resp, err := forgeGet(client, auth, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Directories are returned as JSON listings of their entries
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt == "application/json" {
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("%q is not a file", c.SubPath)
+	}
+	return resp, nil
+}
+
+// OpenArchive requests the tarball of a commit, served from
+// the web host instead of the API
+func (BitbucketCloudProvider) OpenArchive(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	workspace, repo, err := ownerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"https://%s/%s/%s/get/%s.tar.gz", bitbucketCloudHost,
+		url.PathEscape(workspace), url.PathEscape(repo), url.PathEscape(c.Commit),
+	)
+	return forgeGet(client, auth, u, nil)
+}
+
+// bitbucketServerRepo returns the project key and repository name of the
+// components. Repositories are cloned from /scm/PROJECT/repo paths,
+// personal repositories have the project key ~user.
+func bitbucketServerRepo(c *Components) (project, repo string, err error) {
+	project, repo, ok := strings.Cut(strings.TrimPrefix(strings.Trim(c.RepoPath, "/"), "scm/"), "/")
+	if !ok || project == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("%q is not a Bitbucket Server repository path", c.RepoPath)
+	}
+	return project, strings.TrimSuffix(repo, ".git"), nil
+}
+
+// OpenFile requests a file from the raw endpoint of the
+// Bitbucket Server REST API
+func (BitbucketServerProvider) OpenFile(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	project, repo, err := bitbucketServerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"https://%s/rest/api/1.0/projects/%s/repos/%s/raw/%s?at=%s", c.Hostname,
+		url.PathEscape(project), url.PathEscape(repo), escapePath(c.SubPath), url.QueryEscape(c.Commit),
+	)
+	return forgeGet(client, auth, u, nil)
+}
+
+// OpenArchive requests the tarball of a commit from the
+// Bitbucket Server REST API. Its archives have no top level directory
+// unless a prefix is requested.
+func (BitbucketServerProvider) OpenArchive(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	project, repo, err := bitbucketServerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"https://%s/rest/api/1.0/projects/%s/repos/%s/archive?at=%s&format=tar.gz&prefix=%s", c.Hostname,
+		url.PathEscape(project), url.PathEscape(repo), url.QueryEscape(c.Commit), url.QueryEscape(repo),
+	)
+	return forgeGet(client, auth, u, nil)
+}
@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestBitbucketFastPath(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET api.bitbucket.org/2.0/repositories/workspace/repo/src/{commit}/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.PathValue("commit") != testCommit:
+			http.NotFound(w, r)
+		case r.PathValue("path") == "README.md":
+			io.WriteString(w, "hello from bitbucket cloud\n") //nolint:errcheck,gosec
+		case r.PathValue("path") == "dir":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"values":[]}`) //nolint:errcheck,gosec
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	mux.HandleFunc("GET bitbucket.example.com/rest/api/1.0/projects/{project}/repos/repo/raw/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("at") != testCommit || r.PathValue("path") != "docs/a b.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "hello from "+r.PathValue("project")+"\n") //nolint:errcheck,gosec
+	})
+	srv := newForgeTestServer(t, mux)
+	srv.run(t, copyForgeFile, []fnOpt{WithBitbucketServerHosts("bitbucket.example.com")}, []forgeTest{
+		{"cloud", "git+https://bitbucket.org/workspace/repo.git@" + testCommit + "#README.md", nil, "hello from bitbucket cloud\n", false},
+		{"server", "git+https://bitbucket.example.com/scm/PROJ/repo.git@" + testCommit + "#docs/a b.txt", nil, "hello from PROJ\n", false},
+		{"server-personal", "git+https://bitbucket.example.com/scm/~user/repo.git@" + testCommit + "#docs/a b.txt", nil, "hello from ~user\n", false},
+		{"cloud-directory", "git+https://bitbucket.org/workspace/repo@" + testCommit + "#dir", nil, nil, true},
+		{"server-not-found", "git+https://bitbucket.example.com/scm/PROJ/repo.git@" + testCommit + "#missing.txt", nil, nil, true},
+	})
+}
@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// GetBlob returns the contents of the blob identified by oid in the
+// repository referenced by the locator, like git cat-file blob does. The
+// objects are fetched without checking out a worktree.
+//
+// The blob must be reachable from the locator ref. Locators without a ref
+// search the history of the remote HEAD and locators pointing to a commit
+// search all the branches of the remote.
+func GetBlob[T ~string](locator T, oid string, funcs ...fnOpt) ([]byte, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if !plumbing.IsHash(oid) {
+		return nil, fmt.Errorf("invalid object hash %q", oid)
+	}
+
+	l := Locator(locator)
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing locator: %w", err)
+	}
+
+	if isRefQuery(components.RefString) {
+		if err := resolveRefQuery(l, components, &opts, funcs...); err != nil {
+			return nil, fmt.Errorf("resolving version query: %w", err)
+		}
+	}
+
+	var refspec config.RefSpec
+	switch {
+	case components.Branch != "":
+		name := plumbing.NewBranchReferenceName(components.Branch)
+		refspec = config.RefSpec(fmt.Sprintf("%s:%s", name, name))
+	case components.Tag != "":
+		name := plumbing.NewTagReferenceName(components.Tag)
+		refspec = config.RefSpec(fmt.Sprintf("%s:%s", name, name))
+	case components.Commit != "":
+		refspec = "+refs/heads/*:refs/heads/*"
+	case components.RefString != "":
+		refspec = config.RefSpec(fmt.Sprintf("%s:%s", components.refName(), components.refName()))
+	default:
+		refspec = config.RefSpec(fmt.Sprintf("%s:%s", plumbing.HEAD, remoteHeadRef))
+	}
+
+	var cleanup func() error
+	repo, err := withCredentialPrompt(components, &opts, func() (*git.Repository, error) {
+		auth, err := prepareRemote(l, components, &opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := requireOnline(l, &opts); err != nil {
+			return nil, err
+		}
+
+		st, done, err := newStorage(&opts, components)
+		if err != nil {
+			return nil, err
+		}
+		repo, err := fetchRefSpecs(st, nil, components.fetchURL(), []config.RefSpec{refspec}, auth, 0)
+		if err != nil {
+			done() //nolint:errcheck,gosec
+			return nil, fmt.Errorf("fetching %q: %w", refspec, err)
+		}
+		cleanup = done
+		return repo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup() //nolint:errcheck
+
+	blob, err := repo.BlobObject(plumbing.NewHash(oid))
+	if err != nil {
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil, fmt.Errorf("blob %s not found in %s: %w", oid, components.fetchURL(), err)
+		}
+		return nil, fmt.Errorf("reading blob %s: %w", oid, err)
+	}
+
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("opening blob %s: %w", oid, err)
+	}
+	defer r.Close() //nolint:errcheck
+
+	return io.ReadAll(r)
+}
+
+// openRepoFile opens a file of the commit checked out in a cloned
+// repository reading its blob from the object store, so it works on clones
+// without a worktree. Symbolic links are followed within the repository and
+// files in submodules are read from the submodule repository.
+func openRepoFile(cloned *clonedRepo, p string) (io.ReadCloser, error) {
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit tree: %w", err)
+	}
+
+	entry, err := resolveTreeSymlink(tree, strings.Trim(p, "/"))
+	var sbe *submoduleBoundaryError
+	if errors.As(err, &sbe) {
+		return openSubmoduleFile(cloned, tree, sbe)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch entry.Mode {
+	case filemode.Dir:
+		return nil, fmt.Errorf("%q is a directory", p)
+	case filemode.Submodule:
+		return nil, fmt.Errorf("%q is a submodule", p)
+	}
+
+	blob, err := cloned.Repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading blob of %q: %w", p, err)
+	}
+	r, err := blob.Reader()
+	if err != nil || !cloned.opts.LFS {
+		return r, err
+	}
+
+	lfs, err := newLFSClient(cloned, tree)
+	if err != nil {
+		r.Close() //nolint:errcheck,gosec
+		return nil, err
+	}
+	r, _, err = lfs.smudge(r)
+	return r, err
+}
+
+// closingReader closes another resource, such as the cloned repository it
+// reads from, along with the reader.
+type closingReader struct {
+	io.ReadCloser
+	closer io.Closer
+}
+
+// Close closes the reader and then the other resource
+func (r *closingReader) Close() error {
+	err := r.ReadCloser.Close()
+	if cerr := r.closer.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// resolveTreeSymlink looks up a path in a tree following the symbolic links
+// in it, just as resolveRepoSymlink does in a worktree. It returns the entry
+// of the final target.
+func resolveTreeSymlink(tree *object.Tree, p string) (*object.TreeEntry, error) {
+	root := &object.TreeEntry{Mode: filemode.Dir, Hash: tree.Hash}
+	resolved := ""
+	entry := root
+	parts := strings.Split(p, "/")
+	hops := 0
+	for len(parts) > 0 {
+		part := parts[0]
+		parts = parts[1:]
+
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if resolved == "" {
+				return nil, fmt.Errorf("resolving %q: %w", p, errSymlinkEscapes)
+			}
+			resolved = strings.TrimPrefix(path.Dir(resolved), ".")
+			entry = root
+			if resolved != "" {
+				e, err := tree.FindEntry(resolved)
+				if err != nil {
+					return nil, fmt.Errorf("looking up %q: %w", resolved, err)
+				}
+				entry = e
+			}
+			continue
+		}
+
+		next := path.Join(resolved, part)
+		e, err := tree.FindEntry(next)
+		if err != nil {
+			if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+				return nil, fmt.Errorf("%q: %w", p, fs.ErrNotExist)
+			}
+			return nil, fmt.Errorf("looking up %q: %w", next, err)
+		}
+		if e.Mode == filemode.Submodule && len(parts) > 0 {
+			// The rest of the path is in the submodule repository
+			if rest := path.Clean(strings.Join(parts, "/")); rest != "." && !strings.HasPrefix(rest, "..") {
+				return nil, &submoduleBoundaryError{Path: next, Commit: e.Hash, Rest: rest}
+			}
+		}
+		if e.Mode != filemode.Symlink {
+			resolved, entry = next, e
+			continue
+		}
+
+		hops++
+		if hops > maxSymlinkHops {
+			return nil, fmt.Errorf("resolving %q: too many levels of symbolic links", p)
+		}
+		link, err := readLinkTarget(tree, next)
+		if err != nil {
+			return nil, err
+		}
+		if path.IsAbs(link) {
+			return nil, fmt.Errorf("resolving %q: %w", p, errSymlinkEscapes)
+		}
+		parts = append(strings.Split(link, "/"), parts...)
+	}
+	return entry, nil
+}
+
+// readLinkTarget reads the target of a symbolic link from its blob
+func readLinkTarget(tree *object.Tree, p string) (string, error) {
+	f, err := tree.File(p)
+	if err != nil {
+		return "", fmt.Errorf("reading link %q: %w", p, err)
+	}
+	target, err := f.Contents()
+	if err != nil {
+		return "", fmt.Errorf("reading link %q: %w", p, err)
+	}
+	return target, nil
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"io"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBlob(t *testing.T) {
+	t.Parallel()
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"a.txt": "first version\n",
+	})
+	tagTestRepo(t, repoDir, "v1.0.0", first, "")
+	last := commitTestFile(t, repoDir, "a.txt", "second version\n")
+
+	oldBlob := plumbing.ComputeHash(plumbing.BlobObject, []byte("first version\n")).String()
+	newBlob := plumbing.ComputeHash(plumbing.BlobObject, []byte("second version\n")).String()
+
+	for _, tc := range []struct {
+		name     string
+		ref      string
+		oid      string
+		expected string
+		mustErr  bool
+	}{
+		{"head-history", "", oldBlob, "first version\n", false},
+		{"branch", "refs/heads/master", newBlob, "second version\n", false},
+		{"tag", "v1.0.0", oldBlob, "first version\n", false},
+		{"tag-unreachable", "v1.0.0", newBlob, "", true},
+		{"commit", last, oldBlob, "first version\n", false},
+		{"missing", "", plumbing.ComputeHash(plumbing.BlobObject, []byte("nope")).String(), "", true},
+		{"invalid-oid", "", "abc", "", true},
+		{"commit-oid", "", last, "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			locator := fileLocator(repoDir, tc.ref, "")
+			if tc.ref == "" {
+				locator = "file://" + repoDir
+			}
+			data, err := GetBlob(locator, tc.oid)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(data))
+		})
+	}
+}
+
+func TestOpenRepoFile(t *testing.T) {
+	t.Parallel()
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		"docs/guide.md": "guide\n",
+		"src/main.go":   "package main\n",
+	})
+	commit := commitTestSymlinks(t, repoDir, map[string]string{
+		"guide.md":     "docs/guide.md",
+		"docs/link":    "../src",
+		"escape":       "../outside",
+		"docs/loop":    "loop",
+		"docs/main.go": "link/main.go",
+	})
+
+	opts := defaultOptions
+	opts.noCheckout = true
+	cloned, err := cloneRepo(Locator(fileLocator(repoDir, commit, "")), &opts)
+	require.NoError(t, err)
+	require.Nil(t, cloned.FS)
+
+	for _, tc := range []struct {
+		name     string
+		path     string
+		expected string
+		mustErr  bool
+	}{
+		{"file", "docs/guide.md", "guide\n", false},
+		{"symlink", "guide.md", "guide\n", false},
+		{"dir-symlink", "docs/link/main.go", "package main\n", false},
+		{"chained", "docs/main.go", "package main\n", false},
+		{"escape", "escape", "", true},
+		{"loop", "docs/loop", "", true},
+		{"dir", "docs", "", true},
+		{"missing", "docs/nope.md", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := openRepoFile(cloned, tc.path)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer r.Close() //nolint:errcheck
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(data))
+		})
+	}
+}
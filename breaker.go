@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// defaultBreakerThreshold is the number of consecutive failures of a host
+// that trip its circuit breaker in group operations
+const defaultBreakerThreshold = 5
+
+// HostUnavailableError is returned by group operations for the locators of
+// a host that were skipped after it failed repeatedly
+type HostUnavailableError struct {
+	// Hostname is the host that failed
+	Hostname string
+
+	// Failures is the number of consecutive failures that tripped the
+	// circuit breaker of the host
+	Failures int
+
+	// Err is the last error returned by the host
+	Err error
+}
+
+func (e *HostUnavailableError) Error() string {
+	return fmt.Sprintf(
+		"skipped as %s failed %d consecutive times (circuit breaker open), last error: %v",
+		e.Hostname, e.Failures, e.Err,
+	)
+}
+
+func (e *HostUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// hostBreaker stops contacting the hosts failing consecutively during a
+// group operation, so the rest of their locators fail right away instead
+// of each waiting for its own error. A nil breaker never trips.
+type hostBreaker struct {
+	threshold int
+	mu        sync.Mutex
+	hosts     map[string]*breakerState
+}
+
+// breakerState tracks the failures of a host
+type breakerState struct {
+	failures int
+	lastErr  error
+}
+
+// newHostBreaker returns a breaker tripping after threshold consecutive
+// failures of a host. It returns nil if the threshold is zero.
+func newHostBreaker(threshold int) *hostBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &hostBreaker{threshold: threshold, hosts: map[string]*breakerState{}}
+}
+
+// check returns a HostUnavailableError if the breaker of the host tripped
+func (b *hostBreaker) check(host string) error {
+	if b == nil || host == "" {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st, ok := b.hosts[strings.ToLower(host)]
+	if !ok || st.failures < b.threshold {
+		return nil
+	}
+	return &HostUnavailableError{Hostname: host, Failures: st.failures, Err: st.lastErr}
+}
+
+// record registers the result of contacting a host. Errors caused by the
+// locator (missing data, credentials, policies) are not failures of the
+// host and are ignored.
+func (b *hostBreaker) record(host string, err error) {
+	if b == nil || host == "" {
+		return
+	}
+	if err != nil && ErrorCode(err) != "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	host = strings.ToLower(host)
+	st, ok := b.hosts[host]
+	if !ok {
+		st = &breakerState{}
+		b.hosts[host] = st
+	}
+	// Once tripped, the breaker stays open for the rest of the operation
+	if st.failures >= b.threshold {
+		return
+	}
+	if err == nil {
+		st.failures = 0
+		return
+	}
+	st.failures++
+	st.lastErr = err
+}
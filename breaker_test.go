@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostBreaker(t *testing.T) {
+	t.Parallel()
+	down := errors.New("connection refused")
+	for _, tc := range []struct {
+		name      string
+		threshold int
+		results   []error
+		open      bool
+	}{
+		{"closed", 2, []error{down}, false},
+		{"tripped", 2, []error{down, down}, true},
+		{"reset", 2, []error{down, nil, down}, false},
+		{"stays-open", 2, []error{down, down, nil}, true},
+		{"locator-errors", 2, []error{fs.ErrNotExist, &PolicyViolationError{}}, false},
+		{"disabled", 0, []error{down, down, down}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			b := newHostBreaker(tc.threshold)
+			for _, err := range tc.results {
+				b.record("Example.com", err)
+			}
+			err := b.check("example.com")
+			if !tc.open {
+				require.NoError(t, err)
+				return
+			}
+			var hue *HostUnavailableError
+			require.ErrorAs(t, err, &hue)
+			require.ErrorIs(t, err, down)
+			require.Equal(t, CodeHostUnavailable, ErrorCode(err))
+			require.NoError(t, b.check("other.example.com"))
+		})
+	}
+}
+
+func TestCopyFileGroupBreaker(t *testing.T) {
+	t.Parallel()
+	// Hosts under .invalid never resolve, the first API fetches fail and
+	// trip the breaker for the rest
+	locators := []string{}
+	for i := range 10 {
+		locators = append(locators, fmt.Sprintf("git+https://down.invalid/org/repo%d@%s#README.md", i, testCommit))
+	}
+	writers := make([]io.Writer, len(locators))
+	for i := range writers {
+		writers[i] = io.Discard
+	}
+
+	err := CopyFileGroup(locators, writers, WithGiteaHosts("down.invalid"), WithCircuitBreaker(2))
+	var el *ErrorList
+	require.ErrorAs(t, err, &el)
+	skipped := 0
+	for _, err := range el.Errors {
+		require.Error(t, err)
+		if ErrorCode(err) == CodeHostUnavailable {
+			skipped++
+		}
+	}
+	// Four fetches run in parallel, at most five reach the host
+	require.GreaterOrEqual(t, skipped, 5)
+
+	err = CopyFileGroup(locators, writers, WithGiteaHosts("down.invalid"), WithCircuitBreaker(0))
+	require.ErrorAs(t, err, &el)
+	for _, err := range el.Errors {
+		require.NotEqual(t, CodeHostUnavailable, ErrorCode(err))
+	}
+	require.Error(t, WithCircuitBreaker(-1)(&options{}))
+}
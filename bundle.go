@@ -0,0 +1,229 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// bundleSignature is the header of version 2 git bundles
+const bundleSignature = "# v2 git bundle\n"
+
+// CopyBundle writes a git bundle with the history of the locator ref to w.
+// The bundle can be cloned or fetched from (git clone file.bundle) to
+// transfer the repository to hosts without access to the remote. It
+// contains the locator branch or tag (or only HEAD for commit locators) and
+// HEAD, pointing to the commit the locator resolves to.
+//
+// WithBundleDepth limits the history in the bundle. Like in bundles created
+// with git bundle create --depth, the parents of the oldest commits are then
+// listed as prerequisites that the receiving repository must have.
+func CopyBundle[T ~string](locator T, w io.Writer, funcs ...fnOpt) error {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return err
+		}
+	}
+
+	cloned, err := cloneHistory(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return fmt.Errorf("cloning repository: %w", err)
+	}
+	defer cloned.Close() //nolint:errcheck
+	repo := cloned.Repo
+	commit := plumbing.NewHash(cloned.Commit)
+
+	// Annotated tags are bundled with their tag objects
+	refs := map[plumbing.ReferenceName]plumbing.Hash{plumbing.HEAD: commit}
+	tip := commit
+	var tagObjects []plumbing.Hash
+	switch {
+	case cloned.Components.Tag != "":
+		name := plumbing.NewTagReferenceName(cloned.Components.Tag)
+		ref, err := repo.Reference(name, true)
+		if err != nil {
+			return fmt.Errorf("reading tag reference: %w", err)
+		}
+		if tagObjects, err = tagChain(repo, ref.Hash()); err != nil {
+			return err
+		}
+		refs[name] = ref.Hash()
+	case cloned.Components.Branch != "":
+		refs[plumbing.NewBranchReferenceName(cloned.Components.Branch)] = tip
+	}
+
+	commits, prerequisites, err := bundleCommits(repo, tip, opts.BundleDepth)
+	if err != nil {
+		return err
+	}
+	objects, err := bundleObjects(repo, commits, prerequisites)
+	if err != nil {
+		return err
+	}
+	objects = append(tagObjects, objects...)
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(bundleSignature); err != nil {
+		return fmt.Errorf("writing bundle header: %w", err)
+	}
+	for _, c := range prerequisites {
+		if _, err := fmt.Fprintf(bw, "-%s\n", c.Hash); err != nil {
+			return fmt.Errorf("writing bundle header: %w", err)
+		}
+	}
+	// HEAD goes last so clients pick the named ref first
+	for _, name := range []plumbing.ReferenceName{
+		plumbing.NewTagReferenceName(cloned.Components.Tag),
+		plumbing.NewBranchReferenceName(cloned.Components.Branch),
+		plumbing.HEAD,
+	} {
+		if hash, ok := refs[name]; ok {
+			if _, err := fmt.Fprintf(bw, "%s %s\n", hash, name); err != nil {
+				return fmt.Errorf("writing bundle header: %w", err)
+			}
+		}
+	}
+	if err := bw.WriteByte('\n'); err != nil {
+		return fmt.Errorf("writing bundle header: %w", err)
+	}
+
+	if _, err := packfile.NewEncoder(bw, repo.Storer, false).Encode(objects, 10); err != nil {
+		return fmt.Errorf("encoding packfile: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	return nil
+}
+
+// tagChain returns the tag objects found while peeling hash to a commit
+func tagChain(repo *git.Repository, hash plumbing.Hash) ([]plumbing.Hash, error) {
+	ret := []plumbing.Hash{}
+	for range 10 {
+		tag, err := repo.TagObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return ret, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tag object %s: %w", hash, err)
+		}
+		ret = append(ret, hash)
+		hash = tag.Target
+	}
+	return ret, nil
+}
+
+// bundleCommits walks the history from tip returning the commits to bundle
+// and the parents left out of it. A depth of zero walks the whole history.
+// Commits at the shallow boundary of the repository are treated as roots.
+func bundleCommits(repo *git.Repository, tip plumbing.Hash, depth int) (commits, prerequisites []*object.Commit, err error) {
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading shallow commits: %w", err)
+	}
+	boundary := map[plumbing.Hash]struct{}{}
+	for _, h := range shallow {
+		boundary[h] = struct{}{}
+	}
+
+	tipCommit, err := repo.CommitObject(tip)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading commit %s: %w", tip, err)
+	}
+
+	seen := map[plumbing.Hash]struct{}{tip: {}}
+	level := []*object.Commit{tipCommit}
+	for d := 1; len(level) > 0; d++ {
+		commits = append(commits, level...)
+		next := []*object.Commit{}
+		for _, c := range level {
+			if _, ok := boundary[c.Hash]; ok {
+				continue
+			}
+			for _, p := range c.ParentHashes {
+				if _, ok := seen[p]; ok {
+					continue
+				}
+				seen[p] = struct{}{}
+				parent, err := repo.CommitObject(p)
+				if err != nil {
+					return nil, nil, fmt.Errorf("reading commit %s: %w", p, err)
+				}
+				if depth > 0 && d >= depth {
+					prerequisites = append(prerequisites, parent)
+					continue
+				}
+				next = append(next, parent)
+			}
+		}
+		level = next
+	}
+	return commits, prerequisites, nil
+}
+
+// bundleObjects lists the commits and all the trees and blobs they
+// reference, leaving out the objects the prerequisites already reference.
+func bundleObjects(repo *git.Repository, commits, prerequisites []*object.Commit) ([]plumbing.Hash, error) {
+	excluded := map[plumbing.Hash]struct{}{}
+	for _, c := range prerequisites {
+		if err := collectTree(repo, c.TreeHash, excluded, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	ret := []plumbing.Hash{}
+	for _, c := range commits {
+		ret = append(ret, c.Hash)
+		if err := collectTree(repo, c.TreeHash, excluded, &ret); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// collectTree adds a tree and the objects below it not in seen to seen
+// and, if not nil, to list.
+func collectTree(repo *git.Repository, hash plumbing.Hash, seen map[plumbing.Hash]struct{}, list *[]plumbing.Hash) error {
+	if _, ok := seen[hash]; ok {
+		return nil
+	}
+	seen[hash] = struct{}{}
+	if list != nil {
+		*list = append(*list, hash)
+	}
+
+	tree, err := repo.TreeObject(hash)
+	if err != nil {
+		return fmt.Errorf("reading tree %s: %w", hash, err)
+	}
+	for _, entry := range tree.Entries {
+		switch entry.Mode {
+		case filemode.Submodule:
+			continue
+		case filemode.Dir:
+			if err := collectTree(repo, entry.Hash, seen, list); err != nil {
+				return err
+			}
+		default:
+			if _, ok := seen[entry.Hash]; ok {
+				continue
+			}
+			seen[entry.Hash] = struct{}{}
+			if list != nil {
+				*list = append(*list, entry.Hash)
+			}
+		}
+	}
+	return nil
+}
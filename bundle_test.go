@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyBundle(t *testing.T) {
+	t.Parallel()
+
+	gitBin, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git binary not found")
+	}
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	second := commitTestFile(t, repoDir, "hello.txt", "hello again")
+	third := commitTestFile(t, repoDir, "other.txt", "other")
+	tagTestRepo(t, repoDir, "v1.0.0", second, "release")
+
+	// gitRun runs git in dir returning its trimmed output
+	gitRun := func(t *testing.T, dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command(gitBin, args...) //nolint:gosec
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	writeBundle := func(t *testing.T, locator string, funcs ...fnOpt) string {
+		t.Helper()
+		var buf bytes.Buffer
+		require.NoError(t, CopyBundle(locator, &buf, append(funcs, noAuth)...))
+		path := filepath.Join(t.TempDir(), "repo.bundle")
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+		return path
+	}
+
+	t.Run("branch", func(t *testing.T) {
+		t.Parallel()
+		bundle := writeBundle(t, fileLocator(repoDir, "refs/heads/master", ""))
+		dir := t.TempDir()
+		gitRun(t, dir, "clone", "-q", bundle, "clone")
+		clone := filepath.Join(dir, "clone")
+		require.Equal(t, third, gitRun(t, clone, "rev-parse", "HEAD"))
+		require.Equal(t, first, gitRun(t, clone, "rev-list", "--max-parents=0", "HEAD"))
+		data, err := os.ReadFile(filepath.Join(clone, "other.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "other", string(data))
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		t.Parallel()
+		bundle := writeBundle(t, fileLocator(repoDir, "v1.0.0", ""))
+		heads := gitRun(t, filepath.Dir(bundle), "bundle", "list-heads", bundle)
+		require.Contains(t, heads, "refs/tags/v1.0.0")
+		dir := t.TempDir()
+		gitRun(t, dir, "clone", "-q", bundle, "clone")
+		clone := filepath.Join(dir, "clone")
+		require.Equal(t, second, gitRun(t, clone, "rev-parse", "HEAD"))
+		require.Equal(t, "tag", gitRun(t, clone, "cat-file", "-t", "v1.0.0"))
+	})
+
+	t.Run("shallow", func(t *testing.T) {
+		t.Parallel()
+		bundle := writeBundle(t, fileLocator(repoDir, third, ""), WithBundleDepth(1))
+		data, err := os.ReadFile(bundle)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "-"+second+"\n")
+
+		// The bundle applies on top of a repository with the prerequisites
+		dir := t.TempDir()
+		gitRun(t, dir, "clone", "-q", repoDir, "clone")
+		clone := filepath.Join(dir, "clone")
+		gitRun(t, clone, "reset", "-q", "--hard", second)
+		gitRun(t, clone, "bundle", "verify", bundle)
+	})
+}
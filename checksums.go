@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ChecksumManifest is the inventory of the files written by Download
+type ChecksumManifest struct {
+	// Locator is the locator the files were downloaded from
+	Locator string `json:"locator"`
+
+	// Commit is the full hash of the commit the locator resolved to
+	Commit string `json:"commit"`
+
+	// Files lists the downloaded files, sorted by path
+	Files []ChecksumEntry `json:"files"`
+}
+
+// ChecksumEntry is a file in a checksum manifest
+type ChecksumEntry struct {
+	// Path is the slash separated path of the file relative to the
+	// download directory
+	Path string `json:"path"`
+
+	// SHA256 is the hex encoded SHA-256 digest of the file contents
+	SHA256 string `json:"sha256"`
+}
+
+// newChecksumManifest hashes the files at paths, relative to root. Symbolic
+// links are not listed.
+func newChecksumManifest(root string, paths []string, locator, commit string) (*ChecksumManifest, error) {
+	manifest := &ChecksumManifest{
+		Locator: locator,
+		Commit:  commit,
+		Files:   []ChecksumEntry{},
+	}
+	for _, p := range paths {
+		full := filepath.Join(root, p)
+		info, err := os.Lstat(full)
+		if err != nil {
+			return nil, fmt.Errorf("reading file info: %w", err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		digest, err := sha256File(full)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, ChecksumEntry{
+			Path:   filepath.ToSlash(p),
+			SHA256: digest,
+		})
+	}
+	slices.SortFunc(manifest.Files, func(a, b ChecksumEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return manifest, nil
+}
+
+// sha256File returns the hex encoded SHA-256 digest of a file
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %q: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadChecksumManifest(t *testing.T) {
+	t.Parallel()
+
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		"docs/b.md":     "b",
+		"docs/sub/a.md": "a",
+		"other.txt":     "other",
+	})
+	locator := fileLocator(repoDir, commit, "docs")
+
+	dest := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "checksums.json")
+	require.NoError(t, Download(locator, dest, WithSystemCredentials(false), WithChecksumManifest(manifestPath)))
+
+	data, err := os.ReadFile(manifestPath)
+	require.NoError(t, err)
+	manifest := &ChecksumManifest{}
+	require.NoError(t, json.Unmarshal(data, manifest))
+
+	digest := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	require.Equal(t, locator, manifest.Locator)
+	require.Equal(t, commit, manifest.Commit)
+	require.Equal(t, []ChecksumEntry{
+		{Path: "docs/b.md", SHA256: digest("b")},
+		{Path: "docs/sub/a.md", SHA256: digest("a")},
+	}, manifest.Files)
+}
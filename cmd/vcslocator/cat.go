@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+// runCat prints the file referenced by a locator
+func runCat(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(
+		"cat", "<locator>",
+		"Fetches the file referenced by the locator and writes it to stdout.",
+		stderr,
+	)
+	var opts optionFlags
+	opts.register(fs)
+	output := fs.String("o", "", "write the file to `path` instead of stdout")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return &usageError{msg: "expected one locator"}
+	}
+
+	if *output == "" {
+		return vcslocator.CopyFile(positional[0], stdout, opts.options()...)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	if err := vcslocator.CopyFile(positional[0], f, opts.options()...); err != nil {
+		f.Close()          //nolint:errcheck,gosec
+		os.Remove(*output) //nolint:errcheck,gosec
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCat(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepo(t, map[string]string{"README.md": "hello"})
+
+	for _, name := range []string{"cat", "get"} {
+		code, stdout, stderr := runTest(name, fileLocator(repoDir, commit, "README.md"), "--system-credentials=false")
+		require.Equal(t, 0, code, stderr)
+		require.Equal(t, "hello", stdout)
+	}
+
+	t.Run("output", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "out.md")
+		code, stdout, stderr := runTest("cat", "-o", path, "--system-credentials=false", fileLocator(repoDir, commit, "README.md"))
+		require.Equal(t, 0, code, stderr)
+		require.Empty(t, stdout)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(data))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "out.md")
+		code, _, stderr := runTest("cat", "-o", path, "--system-credentials=false", fileLocator(repoDir, commit, "nope.md"))
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "vcslocator cat: [VL404]")
+		require.NoFileExists(t, path)
+	})
+}
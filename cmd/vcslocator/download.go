@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"io"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+// runDownload copies the files referenced by a locator to a directory
+func runDownload(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(
+		"download", "<locator> <dir>",
+		"Downloads the file or directory referenced by the locator to dir. Locators\n"+
+			"without a subpath download the whole repository tree.",
+		stderr,
+	)
+	var opts optionFlags
+	opts.register(fs)
+	var include, exclude listFlag
+	fs.Var(&include, "include", "only download the files matching the glob `pattern` (repeatable)")
+	fs.Var(&exclude, "exclude", "skip the files matching the glob `pattern` (repeatable)")
+	keepGit := fs.Bool("keep-git", false, "write the repository to dir/.git, making it a working clone")
+	quiet := fs.Bool("q", false, "do not print progress messages")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return &usageError{msg: "expected a locator and a destination directory"}
+	}
+
+	funcs := append(opts.options(), vcslocator.WithKeepGitDir(*keepGit))
+	if len(include) > 0 {
+		funcs = append(funcs, vcslocator.WithInclude(include...))
+	}
+	if len(exclude) > 0 {
+		funcs = append(funcs, vcslocator.WithExclude(exclude...))
+	}
+	if !*quiet {
+		funcs = append(funcs, vcslocator.WithProgress(stderr))
+	}
+	return vcslocator.Download(positional[0], positional[1], funcs...)
+}
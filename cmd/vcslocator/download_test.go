@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownload(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepo(t, map[string]string{
+		"README.md":        "hello",
+		"docs/guide.md":    "guide",
+		"docs/api.yaml":    "api",
+		"docs/draft/x.md":  "draft",
+		"src/main.go":      "package main",
+		"src/main_test.go": "package main",
+	})
+
+	for _, tc := range []struct {
+		name     string
+		locator  string
+		args     []string
+		expected []string
+	}{
+		{"tree", fileLocator(repoDir, commit, ""), nil, []string{"README.md", "docs/api.yaml", "docs/draft/x.md", "docs/guide.md", "src/main.go", "src/main_test.go"}},
+		{"subpath", fileLocator(repoDir, commit, "docs"), nil, []string{"docs/api.yaml", "docs/draft/x.md", "docs/guide.md"}},
+		{"include", fileLocator(repoDir, commit, "docs"), []string{"--include", "**/*.md"}, []string{"docs/draft/x.md", "docs/guide.md"}},
+		{"exclude", fileLocator(repoDir, commit, ""), []string{"--exclude", "docs/**", "--exclude", "**/*_test.go"}, []string{"README.md", "src/main.go"}},
+		{"keep-git", fileLocator(repoDir, commit, "src"), []string{"--keep-git"}, []string{".git", "src/main.go", "src/main_test.go"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			dest := t.TempDir()
+			args := append([]string{"download", "--system-credentials=false", tc.locator, dest}, tc.args...)
+			code, stdout, stderr := runTest(args...)
+			require.Equal(t, 0, code, stderr)
+			require.Empty(t, stdout)
+			require.Contains(t, stderr, "fetching file://")
+
+			var files []string
+			require.NoError(t, filepath.WalkDir(dest, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(dest, path)
+				if err != nil {
+					return err
+				}
+				if d.IsDir() && d.Name() == ".git" {
+					files = append(files, ".git")
+					return filepath.SkipDir
+				}
+				if !d.IsDir() {
+					files = append(files, filepath.ToSlash(rel))
+					require.Contains(t, stderr, "writing "+filepath.ToSlash(rel)+"\n")
+				}
+				return nil
+			}))
+			require.Equal(t, tc.expected, files)
+		})
+	}
+
+	t.Run("quiet", func(t *testing.T) {
+		t.Parallel()
+		code, _, stderr := runTest("download", "-q", "--system-credentials=false", fileLocator(repoDir, commit, "README.md"), t.TempDir())
+		require.Equal(t, 0, code, stderr)
+		require.Empty(t, stderr)
+	})
+}
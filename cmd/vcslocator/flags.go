@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+// passwordEnv is the environment variable read when --password is not set
+const passwordEnv = "VCSLOCATOR_PASSWORD"
+
+// newFlagSet returns the flag set of a command. Its usage message shows the
+// positional arguments and description of the command.
+func newFlagSet(name, args, description string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: vcslocator %s [flags] %s\n\n%s\n\nFlags:\n", name, args, description) //nolint:errcheck
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses the flags in args and returns the positional arguments.
+// Unlike flag.Parse, flags may follow the positional arguments. Everything
+// after a "--" argument is positional.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{msg: err.Error()}
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// listFlag is a repeatable flag collecting its values
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// mapFlag is a repeatable flag collecting key=value pairs
+type mapFlag map[string]string
+
+func (m mapFlag) String() string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m mapFlag) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok || k == "" || v == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	m[k] = v
+	return nil
+}
+
+// optionFlags are the flags configuring how the commands fetch data
+type optionFlags struct {
+	systemCredentials bool
+	user              string
+	password          string
+	keychain          bool
+	offline           bool
+	inPlace           bool
+	reference         string
+	mirrors           mapFlag
+	lfs               bool
+	submodules        bool
+	forgeAPI          bool
+	apiCache          string
+	hostAliases       mapFlag
+}
+
+// register defines the option flags in fs
+func (o *optionFlags) register(fs *flag.FlagSet) {
+	o.mirrors = mapFlag{}
+	o.hostAliases = mapFlag{}
+	fs.BoolVar(&o.systemCredentials, "system-credentials", true, "use the git credentials configured in the system")
+	fs.StringVar(&o.user, "user", "", "`username` for http basic authentication")
+	fs.StringVar(&o.password, "password", "", "`password` or token for http basic authentication (defaults to $"+passwordEnv+")")
+	fs.BoolVar(&o.keychain, "keychain", false, "read the credentials of the hosts from the OS keychain")
+	fs.BoolVar(&o.offline, "offline", false, "serve data from local copies only, never contacting the remote")
+	fs.BoolVar(&o.inPlace, "in-place", false, "read file:// repositories where they are instead of cloning them")
+	fs.StringVar(&o.reference, "reference", "", "borrow objects from the local repository at `path`")
+	fs.Var(o.mirrors, "mirror", "fetch repositories of a host from a mirror (`host=mirror`, repeatable)")
+	fs.BoolVar(&o.lfs, "lfs", true, "fetch the contents of git LFS files")
+	fs.BoolVar(&o.submodules, "submodules", false, "recurse into submodules")
+	fs.BoolVar(&o.forgeAPI, "forge-api", true, "fetch files at pinned commits through the forge API when possible")
+	fs.Var(o.hostAliases, "host-alias", "treat a host as a known forge, ie github or gitlab (`host=forge`, repeatable)")
+	fs.StringVar(&o.apiCache, "api-cache", "", "cache the forge API responses in `dir`, revalidating them on each fetch")
+}
+
+// options returns the library options set by the flags
+func (o *optionFlags) options() []vcslocator.Option {
+	opts := []vcslocator.Option{
+		vcslocator.WithSystemCredentials(o.systemCredentials),
+		vcslocator.WithKeychain(o.keychain),
+		vcslocator.WithOffline(o.offline),
+		vcslocator.WithOpenInPlace(o.inPlace),
+		vcslocator.WithLFS(o.lfs),
+		vcslocator.WithSubmodules(o.submodules, 0),
+		vcslocator.WithForgeAPI(o.forgeAPI),
+	}
+	if o.user != "" {
+		password := o.password
+		if password == "" {
+			password = os.Getenv(passwordEnv)
+		}
+		opts = append(opts, vcslocator.WithHttpAuth(o.user, password))
+	}
+	// Like git, ask the askpass program in the environment for credentials
+	if program := cmp.Or(os.Getenv("GIT_ASKPASS"), os.Getenv("SSH_ASKPASS")); program != "" && o.systemCredentials {
+		opts = append(opts, vcslocator.WithAskPass(program))
+	}
+	if o.reference != "" {
+		opts = append(opts, vcslocator.WithReference(o.reference))
+	}
+	if o.apiCache != "" {
+		opts = append(opts, vcslocator.WithAPICache(vcslocator.NewDirAPICache(o.apiCache)))
+	}
+	for host, forge := range o.hostAliases {
+		opts = append(opts, vcslocator.WithHostAlias(host, forge))
+	}
+	if len(o.mirrors) > 0 {
+		opts = append(opts, vcslocator.WithMirrors(o.mirrors))
+	}
+	return opts
+}
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"crypto/sha1" //nolint:gosec // Used to match git-style digests, not for security
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+// algoGitOID computes the gitoid of the file or tree of the locator
+const algoGitOID = "gitoid"
+
+// hashAlgorithms are the content digest algorithms supported by hash
+var hashAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// runHash prints the digests of the file or subtree of a locator
+func runHash(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(
+		"hash", "<locator>",
+		"Prints the digests of the file referenced by the locator. Directories are\n"+
+			"hashed as a listing of the path, mode and digest of their files (see the\n"+
+			"HashTree function of the library). The gitoid algorithm prints the git\n"+
+			"object id of the file or directory as a gitoid URI.",
+		stderr,
+	)
+	var opts optionFlags
+	opts.register(fs)
+	algos := fs.String("algo", "sha256", "comma separated `list` of algorithms: sha1, sha256, sha384, sha512, gitoid")
+	asJSON := fs.Bool("json", false, "print the digests as a JSON digest set")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return &usageError{msg: "expected one locator"}
+	}
+
+	var algorithms []string
+	for _, algo := range strings.Split(*algos, ",") {
+		algo = strings.ToLower(strings.TrimSpace(algo))
+		if _, ok := hashAlgorithms[algo]; !ok && algo != algoGitOID {
+			return &usageError{msg: fmt.Sprintf("unsupported algorithm %q", algo)}
+		}
+		algorithms = append(algorithms, algo)
+	}
+
+	digests, err := computeDigests(positional[0], algorithms, opts.options())
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		// Gitoids are kept as URIs, they carry the object type
+		set := map[string]string{}
+		for i, algo := range algorithms {
+			set[algo] = digests[i]
+			if algo != algoGitOID {
+				set[algo] = strings.TrimPrefix(digests[i], algo+":")
+			}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(set)
+	}
+	for _, digest := range digests {
+		fmt.Fprintln(stdout, digest) //nolint:errcheck
+	}
+	return nil
+}
+
+// computeDigests returns the digests of the locator data, in the order of
+// the algorithms, in the form algorithm:hex. Gitoids are returned as URIs.
+func computeDigests(locator string, algorithms []string, funcs []vcslocator.Option) ([]string, error) {
+	info, err := vcslocator.Stat(locator, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	isFile := info.Type == vcslocator.EntryTypeFile || info.Type == vcslocator.EntryTypeSymlink
+
+	// File contents are hashed in a single pass
+	hashers := map[string]hash.Hash{}
+	if isFile {
+		writers := []io.Writer{}
+		for _, algo := range algorithms {
+			if newHash, ok := hashAlgorithms[algo]; ok && hashers[algo] == nil {
+				hashers[algo] = newHash()
+				writers = append(writers, hashers[algo])
+			}
+		}
+		if len(writers) > 0 {
+			if err := vcslocator.CopyFile(locator, io.MultiWriter(writers...), funcs...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	digests := make([]string, 0, len(algorithms))
+	for _, algo := range algorithms {
+		switch {
+		case algo == algoGitOID:
+			oids, err := vcslocator.GetGitOIDs(locator, funcs...)
+			if err != nil {
+				return nil, err
+			}
+			digests = append(digests, oids[0].URI())
+		case isFile:
+			digests = append(digests, algo+":"+hex.EncodeToString(hashers[algo].Sum(nil)))
+		default:
+			digest, err := vcslocator.HashTree(locator, algo, funcs...)
+			if err != nil {
+				return nil, err
+			}
+			digests = append(digests, digest)
+		}
+	}
+	return digests, nil
+}
@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepo(t, map[string]string{
+		"README.md":     "hello",
+		"docs/guide.md": "guide",
+	})
+	sum := sha256.Sum256([]byte("hello"))
+	fileSHA256 := "sha256:" + hex.EncodeToString(sum[:])
+	fileGitOID := "gitoid:blob:sha1:" + plumbing.ComputeHash(plumbing.BlobObject, []byte("hello")).String()
+	treeSHA256, err := vcslocator.HashTree(fileLocator(repoDir, commit, "docs"), "sha256", vcslocator.WithSystemCredentials(false))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"default", []string{fileLocator(repoDir, commit, "README.md")}, fileSHA256 + "\n"},
+		{"algos", []string{"--algo", "sha256,gitoid", fileLocator(repoDir, commit, "README.md")}, fileSHA256 + "\n" + fileGitOID + "\n"},
+		{"tree", []string{fileLocator(repoDir, commit, "docs")}, treeSHA256 + "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			code, stdout, stderr := runTest(append([]string{"hash", "--system-credentials=false"}, tc.args...)...)
+			require.Equal(t, 0, code, stderr)
+			require.Equal(t, tc.expected, stdout)
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		code, stdout, stderr := runTest("hash", "--system-credentials=false", "--json", "--algo", "sha256,gitoid", fileLocator(repoDir, commit, "README.md"))
+		require.Equal(t, 0, code, stderr)
+		set := map[string]string{}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &set))
+		require.Equal(t, map[string]string{"sha256": fileSHA256[len("sha256:"):], "gitoid": fileGitOID}, set)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+		code, _, _ := runTest("hash", "--algo", "md5", fileLocator(repoDir, commit, "README.md"))
+		require.Equal(t, 2, code)
+	})
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+// runLs lists the files under a locator subpath or the remote references
+func runLs(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(
+		"ls", "<locator>",
+		"Lists the entries of the directory referenced by the locator subpath (the\n"+
+			"repository root without one). With --refs, lists the branches and tags of\n"+
+			"the remote instead.",
+		stderr,
+	)
+	var opts optionFlags
+	opts.register(fs)
+	long := fs.Bool("l", false, "print the type, mode, size and hash of the entries")
+	refs := fs.Bool("refs", false, "list the remote references")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return &usageError{msg: "expected one locator"}
+	}
+
+	if *refs {
+		return listRefs(stdout, positional[0], opts.options())
+	}
+	return listEntries(stdout, positional[0], *long, opts.options())
+}
+
+// listRefs prints the references advertised by the remote as git ls-remote
+func listRefs(w io.Writer, locator string, funcs []vcslocator.Option) error {
+	refs, err := vcslocator.ListRemoteRefs(locator, funcs...)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		switch {
+		case ref.Target != "":
+			fmt.Fprintf(w, "%s\t%s -> %s\n", ref.Commit, ref.Name, ref.Target) //nolint:errcheck
+		default:
+			fmt.Fprintf(w, "%s\t%s\n", ref.Commit, ref.Name) //nolint:errcheck
+		}
+	}
+	return nil
+}
+
+// listEntries prints the entries of a directory in the repository. When the
+// locator points to a file, only the file is printed.
+func listEntries(w io.Writer, locator string, long bool, funcs []vcslocator.Option) error {
+	info, err := vcslocator.Stat(locator, funcs...)
+	if err != nil {
+		return err
+	}
+	entries := []vcslocator.EntryInfo{*info}
+	if info.Type == vcslocator.EntryTypeDir {
+		if entries, err = vcslocator.List(locator, funcs...); err != nil {
+			return err
+		}
+	}
+
+	for i := range entries {
+		name := entries[i].Name
+		if entries[i].Type == vcslocator.EntryTypeDir {
+			name += "/"
+		}
+		if !long {
+			fmt.Fprintln(w, name) //nolint:errcheck
+			continue
+		}
+		fmt.Fprintf( //nolint:errcheck
+			w, "%-9s %s %8d %s %s\n",
+			entries[i].Type, entries[i].Mode, entries[i].Size, shortHash(entries[i].Hash), name,
+		)
+	}
+	return nil
+}
+
+// shortHash abbreviates an object hash for display
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
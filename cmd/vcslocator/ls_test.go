@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLs(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepo(t, map[string]string{
+		"README.md":       "hello",
+		"docs/guide.md":   "guide",
+		"docs/api/v1.txt": "v1",
+	})
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.0.0", plumbing.NewHash(commit), nil)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"root", []string{fileLocator(repoDir, commit, "")}, "README.md\ndocs/\n"},
+		{"subpath", []string{fileLocator(repoDir, commit, "docs")}, "api/\nguide.md\n"},
+		{"file", []string{fileLocator(repoDir, commit, "docs/guide.md")}, "guide.md\n"},
+		{"long", []string{"-l", fileLocator(repoDir, commit, "docs/guide.md")}, "file      -rw-r--r--        5 "},
+		{"refs", []string{"--refs", fileLocator(repoDir, "", "")}, commit + "\tHEAD -> refs/heads/master\n" + commit + "\trefs/heads/master\n" + commit + "\trefs/tags/v1.0.0\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			code, stdout, stderr := runTest(append([]string{"ls", "--system-credentials=false"}, tc.args...)...)
+			require.Equal(t, 0, code, stderr)
+			require.True(t, strings.HasPrefix(stdout, tc.expected), stdout)
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		code, _, stderr := runTest("ls", "--system-credentials=false", fileLocator(repoDir, commit, "nope"))
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "vcslocator ls:")
+	})
+}
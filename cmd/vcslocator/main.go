@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+// Command vcslocator fetches the data referenced by SPDX VCS locators from
+// the command line. Run it without arguments to list its subcommands.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+// command is a subcommand of the program
+type command struct {
+	name    string
+	aliases []string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) error
+}
+
+// matches checks if the command is invoked by name
+func (c *command) matches(name string) bool {
+	return c.name == name || slices.Contains(c.aliases, name)
+}
+
+// commands lists the subcommands of the program
+var commands = []command{
+	{"cat", []string{"get"}, "print the file referenced by a locator", runCat},
+	{"parse", nil, "print the components of a locator", runParse},
+	{"resolve", []string{"pin"}, "pin locators to the commit their refs point to", runResolve},
+	{"download", nil, "download the files referenced by a locator", runDownload},
+	{"ls", nil, "list the files under a locator or the remote references", runLs},
+	{"hash", nil, "print the digests of the file or subtree of a locator", runHash},
+	{"verify", nil, "check the digests and signature of the data of a locator", runVerify},
+}
+
+// usageError is returned by commands invoked with invalid arguments
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the subcommand in args and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if !cmd.matches(args[0]) {
+			continue
+		}
+		err := cmd.run(args[1:], stdout, stderr)
+		var ue *usageError
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.As(err, &ue):
+			fmt.Fprintf(stderr, "vcslocator %s: %s\nRun 'vcslocator %s -h' for usage.\n", cmd.name, ue.msg, cmd.name) //nolint:errcheck
+			return 2
+		default:
+			// Errors with a code are prefixed with it for scripts to match
+			if code := vcslocator.ErrorCode(err); code != "" {
+				fmt.Fprintf(stderr, "vcslocator %s: [%s] %s\n", cmd.name, code, err) //nolint:errcheck
+				return 1
+			}
+			fmt.Fprintf(stderr, "vcslocator %s: %s\n", cmd.name, err) //nolint:errcheck
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stderr, "vcslocator: unknown command %q\n\n", args[0]) //nolint:errcheck
+	printUsage(stderr)
+	return 2
+}
+
+// printUsage lists the subcommands of the program
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: vcslocator <command> [flags] <locator>") //nolint:errcheck
+	fmt.Fprintln(w, "\nCommands:")                                   //nolint:errcheck
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary) //nolint:errcheck
+	}
+	fmt.Fprintln(w, "\nRun vcslocator <command> -h for the flags of a command.") //nolint:errcheck
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// initTestRepo creates a local git repository with files committed,
+// returning the repository path and the commit hash.
+func initTestRepo(t *testing.T, files map[string]string) (repoDir, commitHash string) {
+	t.Helper()
+	repoDir = t.TempDir()
+
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	for relPath, content := range files {
+		abs := filepath.Join(repoDir, relPath)
+		require.NoError(t, os.MkdirAll(filepath.Dir(abs), 0o750))
+		require.NoError(t, os.WriteFile(abs, []byte(content), 0o600))
+		_, err := wt.Add(relPath)
+		require.NoError(t, err)
+	}
+
+	hash, err := wt.Commit("test commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return repoDir, hash.String()
+}
+
+// fileLocator builds a file:// locator for a local repository
+func fileLocator(repoDir, ref, fragment string) string {
+	p := filepath.ToSlash(repoDir)
+	if p != "" && p[0] != '/' {
+		p = "/" + p
+	}
+	loc := "file://" + p
+	if ref != "" {
+		loc += "@" + ref
+	}
+	if fragment != "" {
+		loc += "#" + fragment
+	}
+	return loc
+}
+
+// runTest runs the program returning its exit code and outputs
+func runTest(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no-args", nil, 2},
+		{"help", []string{"help"}, 0},
+		{"unknown", []string{"nope"}, 2},
+		{"command-help", []string{"cat", "-h"}, 0},
+		{"bad-flag", []string{"cat", "--nope"}, 2},
+		{"missing-args", []string{"cat"}, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			code, _, stderr := runTest(tc.args...)
+			require.Equal(t, tc.code, code, stderr)
+			require.Contains(t, stderr, "vcslocator")
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name       string
+		args       []string
+		positional []string
+		output     string
+	}{
+		{"flags-first", []string{"-o", "out", "a", "b"}, []string{"a", "b"}, "out"},
+		{"flags-last", []string{"a", "b", "-o", "out"}, []string{"a", "b"}, "out"},
+		{"interleaved", []string{"a", "-o", "out", "b"}, []string{"a", "b"}, "out"},
+		{"terminator", []string{"a", "--", "-o", "out"}, []string{"a", "-o", "out"}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fs := newFlagSet("test", "", "", &bytes.Buffer{})
+			output := fs.String("o", "", "")
+			positional, err := parseArgs(fs, tc.args)
+			require.NoError(t, err)
+			require.Equal(t, tc.positional, positional)
+			require.Equal(t, tc.output, *output)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+// Severities of the validation findings
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// finding is an issue found when validating a locator
+type finding struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+
+	// Code is the error code of errors returned by the library
+	Code string `json:"code,omitempty"`
+}
+
+// warning returns a warning finding
+func warning(msg string) finding {
+	return finding{Severity: severityWarning, Message: msg}
+}
+
+// parsedComponents are the components of a locator as printed by parse
+type parsedComponents struct {
+	Tool      string     `json:"tool"`
+	Transport string     `json:"transport"`
+	Hostname  string     `json:"hostname"`
+	RepoPath  string     `json:"repoPath"`
+	RepoURL   string     `json:"repoURL,omitempty"`
+	RefString string     `json:"refString"`
+	Commit    string     `json:"commit"`
+	Tag       string     `json:"tag"`
+	Branch    string     `json:"branch"`
+	SubPath   string     `json:"subPath"`
+	AsOf      *time.Time `json:"asOf,omitempty"`
+	LineStart int        `json:"lineStart,omitempty"`
+	LineEnd   int        `json:"lineEnd,omitempty"`
+}
+
+// parseResult is the output of the parse command
+type parseResult struct {
+	Locator    string            `json:"locator"`
+	Components *parsedComponents `json:"components,omitempty"`
+	Findings   []finding         `json:"findings"`
+}
+
+// runParse prints the components of a locator
+func runParse(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(
+		"parse", "<locator>",
+		"Parses the locator and prints its components and validation findings.",
+		stderr,
+	)
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	asBranch := fs.Bool("ref-as-branch", false, "parse the locator ref as a branch instead of a tag")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return &usageError{msg: "expected one locator"}
+	}
+
+	result := parseLocator(positional[0], vcslocator.WithRefAsBranch(*asBranch))
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return fmt.Errorf("encoding result: %w", err)
+		}
+	} else {
+		printParseResult(stdout, result)
+	}
+
+	for _, f := range result.Findings {
+		if f.Severity == severityError {
+			return errors.New("locator is not valid")
+		}
+	}
+	return nil
+}
+
+// parseLocator parses a locator and validates it
+func parseLocator(locator string, funcs ...vcslocator.Option) *parseResult {
+	result := &parseResult{Locator: locator, Findings: []finding{}}
+	c, err := vcslocator.Locator(locator).Parse(funcs...)
+	if err != nil {
+		result.Findings = append(result.Findings, finding{
+			Severity: severityError,
+			Message:  err.Error(),
+			Code:     vcslocator.ErrorCode(err),
+		})
+		return result
+	}
+
+	result.Components = &parsedComponents{
+		Tool:      c.Tool,
+		Transport: c.Transport,
+		Hostname:  c.Hostname,
+		RepoPath:  c.RepoPath,
+		RepoURL:   c.RepoURL(),
+		RefString: c.RefString,
+		Commit:    c.Commit,
+		Tag:       c.Tag,
+		Branch:    c.Branch,
+		SubPath:   c.SubPath,
+		LineStart: c.LineStart,
+		LineEnd:   c.LineEnd,
+	}
+	if !c.AsOf.IsZero() {
+		result.Components.AsOf = &c.AsOf
+	}
+	result.Findings = append(result.Findings, validate(locator, c)...)
+	return result
+}
+
+// validate checks a parsed locator for issues making it unfit as an SPDX
+// download location
+func validate(locator string, c *vcslocator.Components) []finding {
+	var findings []finding
+	switch {
+	case !strings.Contains(locator, "://"):
+		findings = append(findings, warning("short repository slugs are not SPDX VCS locators, use the full git+https form"))
+	case c.Tool == "":
+		findings = append(findings, warning("locator scheme has no VCS tool (ie git+"+c.Transport+")"))
+	}
+	if c.Transport == vcslocator.TransportFile {
+		findings = append(findings, warning("file:// locators only point to data on the local machine"))
+	}
+
+	switch {
+	case c.Commit == "":
+		findings = append(findings, warning("locator is not pinned to a commit, the data it points to can change"))
+	case len(c.Commit) != 40 && len(c.Commit) != 64:
+		findings = append(findings, warning("commit hash is abbreviated"))
+	}
+	if !c.AsOf.IsZero() {
+		findings = append(findings, warning("date refs are not part of the SPDX VCS locator syntax"))
+	}
+	if c.LineStart != 0 {
+		findings = append(findings, warning("line ranges are not part of the SPDX VCS locator syntax"))
+	}
+	return findings
+}
+
+// printParseResult prints the components and findings as text
+func printParseResult(w io.Writer, result *parseResult) {
+	if c := result.Components; c != nil {
+		fields := []struct{ name, value string }{
+			{"tool", c.Tool},
+			{"transport", c.Transport},
+			{"hostname", c.Hostname},
+			{"repoPath", c.RepoPath},
+			{"repoURL", c.RepoURL},
+			{"refString", c.RefString},
+			{"commit", c.Commit},
+			{"tag", c.Tag},
+			{"branch", c.Branch},
+			{"subPath", c.SubPath},
+		}
+		if c.AsOf != nil {
+			fields = append(fields, struct{ name, value string }{"asOf", c.AsOf.Format(time.RFC3339)})
+		}
+		if c.LineStart != 0 {
+			fields = append(fields, struct{ name, value string }{"lines", fmt.Sprintf("%d-%d", c.LineStart, c.LineEnd)})
+		}
+		for _, f := range fields {
+			if f.value != "" {
+				fmt.Fprintf(w, "%-10s %s\n", f.name+":", f.value) //nolint:errcheck
+			}
+		}
+	}
+	for _, f := range result.Findings {
+		fmt.Fprintf(w, "%s: %s\n", f.Severity, f.Message) //nolint:errcheck
+	}
+}
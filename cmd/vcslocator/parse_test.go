@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	const commit = "25c779ba165d1f4fac6fc2ce938bf40c1f8ab1a6"
+	for _, tc := range []struct {
+		name     string
+		locator  string
+		code     int
+		tag      string
+		findings []string
+	}{
+		{"pinned", "git+https://github.com/example/test@" + commit + "#file.txt", 0, "", []string{}},
+		{"tag", "git+https://github.com/example/test@v1#file.txt", 0, "v1", []string{severityWarning}},
+		{"slug", "example/test@" + commit, 0, "", []string{severityWarning}},
+		{"abbreviated", "git+https://github.com/example/test@25c779ba", 0, "", []string{severityWarning}},
+		{"invalid", "ftp://example.com/test", 1, "", []string{severityError}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			code, stdout, stderr := runTest("parse", tc.locator, "--json")
+			require.Equal(t, tc.code, code, stderr)
+
+			var result parseResult
+			require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+			require.Equal(t, tc.locator, result.Locator)
+			severities := []string{}
+			for _, f := range result.Findings {
+				severities = append(severities, f.Severity)
+			}
+			require.Equal(t, tc.findings, severities)
+			if tc.code == 0 {
+				require.Equal(t, tc.tag, result.Components.Tag)
+			} else {
+				require.Equal(t, "VL001", result.Findings[0].Code)
+			}
+		})
+	}
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+		code, stdout, stderr := runTest("parse", "git+https://github.com/example/test@v1#file.txt")
+		require.Equal(t, 0, code, stderr)
+		require.Contains(t, stdout, "hostname:  github.com\n")
+		require.Contains(t, stdout, "warning: locator is not pinned")
+	})
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+// runResolve prints the locators pinned to the commit their refs point to
+func runResolve(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(
+		"resolve", "<locator>...",
+		"Contacts the remotes and prints the locators with their branch, tag or\n"+
+			"version query replaced by the commit it points to, one per line.",
+		stderr,
+	)
+	var opts optionFlags
+	opts.register(fs)
+	commitOnly := fs.Bool("commit", false, "print only the commit hashes")
+	asBranch := fs.Bool("ref-as-branch", false, "resolve the locator refs as branches instead of tags")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return &usageError{msg: "expected at least one locator"}
+	}
+
+	funcs := append(opts.options(), vcslocator.WithRefAsBranch(*asBranch))
+	for _, locator := range positional {
+		l := vcslocator.Locator(locator)
+		var resolved string
+		if *commitOnly {
+			resolved, err = l.Resolve(funcs...)
+		} else {
+			var pinned vcslocator.Locator
+			pinned, err = l.Pin(funcs...)
+			resolved = string(pinned)
+		}
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", locator, err)
+		}
+		fmt.Fprintln(stdout, resolved) //nolint:errcheck
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepo(t, map[string]string{"README.md": "hello"})
+
+	for _, tc := range []struct {
+		name     string
+		command  string
+		args     []string
+		expected string
+	}{
+		{"pin", "resolve", []string{fileLocator(repoDir, "master", "README.md"), "--ref-as-branch"}, fileLocator(repoDir, commit, "README.md") + "\n"},
+		{"alias", "pin", []string{fileLocator(repoDir, "", "README.md")}, fileLocator(repoDir, commit, "README.md") + "\n"},
+		{"commit", "resolve", []string{fileLocator(repoDir, "", ""), "--commit"}, commit + "\n"},
+		{"many", "resolve", []string{"--commit", fileLocator(repoDir, "", ""), fileLocator(repoDir, commit, "")}, commit + "\n" + commit + "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			code, stdout, stderr := runTest(append([]string{tc.command, "--system-credentials=false"}, tc.args...)...)
+			require.Equal(t, 0, code, stderr)
+			require.Equal(t, tc.expected, stdout)
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		code, _, stderr := runTest("resolve", "--system-credentials=false", fileLocator(repoDir, "nope", ""))
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "vcslocator resolve:")
+	})
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/carabiner-dev/vcslocator"
+)
+
+// errVerification is returned when the data of a locator fails a check
+var errVerification = errors.New("verification failed")
+
+// runVerify checks the data of a locator against the expected digests and
+// optionally the signature of its commit or tag
+func runVerify(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(
+		"verify", "<locator>",
+		"Fetches the data referenced by the locator and checks it matches the expected\n"+
+			"digests (as printed by the hash command). With --signature, the PGP signature\n"+
+			"of the tag or commit of the locator is verified too. Exits with a nonzero\n"+
+			"status if any check fails.",
+		stderr,
+	)
+	var opts optionFlags
+	opts.register(fs)
+	var digests listFlag
+	fs.Var(&digests, "digest", "expected `digest` in the form algorithm:hex or a gitoid URI (repeatable)")
+	signature := fs.Bool("signature", false, "verify the signature of the tag or commit")
+	keyring := fs.String("keyring", "", "armored PGP keyring `file` with the keys trusted to sign")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return &usageError{msg: "expected one locator"}
+	}
+	if len(digests) == 0 && !*signature {
+		return &usageError{msg: "nothing to verify, specify a --digest or --signature"}
+	}
+	if *signature && *keyring == "" {
+		return &usageError{msg: "--signature requires a --keyring"}
+	}
+
+	locator := positional[0]
+	failed := false
+
+	if len(digests) > 0 {
+		algorithms := make([]string, 0, len(digests))
+		for _, digest := range digests {
+			algo := algoGitOID
+			if !strings.HasPrefix(digest, algoGitOID+":") {
+				a, _, ok := strings.Cut(digest, ":")
+				if _, supported := hashAlgorithms[strings.ToLower(a)]; !ok || !supported {
+					return &usageError{msg: fmt.Sprintf("invalid digest %q", digest)}
+				}
+				algo = strings.ToLower(a)
+			}
+			algorithms = append(algorithms, algo)
+		}
+
+		actual, err := computeDigests(locator, algorithms, opts.options())
+		if err != nil {
+			return err
+		}
+		for i, digest := range digests {
+			if strings.EqualFold(digest, actual[i]) {
+				fmt.Fprintf(stdout, "ok: %s\n", actual[i]) //nolint:errcheck
+				continue
+			}
+			failed = true
+			fmt.Fprintf(stdout, "mismatch: expected %s, got %s\n", digest, actual[i]) //nolint:errcheck
+		}
+	}
+
+	if *signature {
+		data, err := os.ReadFile(*keyring)
+		if err != nil {
+			return fmt.Errorf("reading keyring: %w", err)
+		}
+		result, err := vcslocator.VerifySignature(locator, string(data), opts.options()...)
+		if err != nil {
+			return err
+		}
+		if result.Verified {
+			fmt.Fprintf(stdout, "ok: %s %s signed by %s\n", result.ObjectType, result.Hash, result.Fingerprint) //nolint:errcheck
+		} else {
+			failed = true
+			fmt.Fprintf(stdout, "signature: %s %s: %s\n", result.ObjectType, result.Hash, result.Reason) //nolint:errcheck
+		}
+	}
+
+	if failed {
+		return errVerification
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestKeyring generates a PGP key and writes its armored public key to
+// a file, returning the key and the file path
+func writeTestKeyring(t *testing.T, name string) (entity *openpgp.Entity, path string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	path = filepath.Join(t.TempDir(), name+".asc")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+	return entity, path
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+	signer, signerKeyring := writeTestKeyring(t, "signer")
+	_, otherKeyring := writeTestKeyring(t, "other")
+
+	repoDir, _ := initTestRepo(t, map[string]string{"README.md": "hello"})
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("signed"), 0o600))
+	_, err = wt.Add("README.md")
+	require.NoError(t, err)
+	hash, err := wt.Commit("signed commit", &git.CommitOptions{
+		Author:  &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()},
+		SignKey: signer,
+	})
+	require.NoError(t, err)
+	commit := hash.String()
+
+	sum := sha256.Sum256([]byte("signed"))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	locator := fileLocator(repoDir, commit, "README.md")
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"digest", []string{"--digest", digest}, 0},
+		{"digest-mismatch", []string{"--digest", "sha256:" + hex.EncodeToString(make([]byte, 32))}, 1},
+		{"gitoid", []string{"--digest", "gitoid:blob:sha1:" + "0000000000000000000000000000000000000000"}, 1},
+		{"signature", []string{"--signature", "--keyring", signerKeyring}, 0},
+		{"digest-and-signature", []string{"--digest", digest, "--signature", "--keyring", signerKeyring}, 0},
+		{"wrong-key", []string{"--digest", digest, "--signature", "--keyring", otherKeyring}, 1},
+		{"nothing", nil, 2},
+		{"no-keyring", []string{"--signature"}, 2},
+		{"bad-digest", []string{"--digest", "md5:abc"}, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			code, _, stderr := runTest(append([]string{"verify", "--system-credentials=false", locator}, tc.args...)...)
+			require.Equal(t, tc.code, code, stderr)
+		})
+	}
+}
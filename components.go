@@ -6,6 +6,7 @@ package vcslocator
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Components captures the parsed pieces of a VCS locator.
@@ -19,6 +20,21 @@ type Components struct {
 	Tag       string
 	Branch    string
 	SubPath   string
+
+	// AsOf is set when the locator points to the state of a ref at a point
+	// in time (ie main@{2025-01-01}). The ref resolves to the newest commit
+	// not after the date.
+	AsOf time.Time
+
+	// LineStart and LineEnd are set when the fragment selects a range of
+	// lines of the subpath file (ie #main.go#L10-L42). Both are zero when
+	// the whole file is selected.
+	LineStart int
+	LineEnd   int
+
+	// mirror is where the repository is fetched from when its host is
+	// mapped to a mirror (see WithMirrors)
+	mirror string
 }
 
 // RepoURL forms the repository URL to clone based on the defined components
@@ -32,3 +48,72 @@ func (c *Components) RepoURL() string {
 		return ""
 	}
 }
+
+// fetchURL returns the URL passed to go-git when talking to the remote. For
+// file: transports we return the full file:// URL so go-git uses its local
+// transport. Passing a bare path can cause go-git to misinterpret it (e.g. on
+// Windows, D:/path looks like an SCP-style SSH URL host:path).
+func (c *Components) fetchURL() string {
+	if c.Transport == TransportFile {
+		return "file://" + c.RepoPath
+	}
+	if c.mirror != "" {
+		return mirrorURL(c.mirror, c)
+	}
+	return c.RepoURL()
+}
+
+// setMirror maps the locator host to its mirror, if one is configured
+func (c *Components) setMirror(opts *options) {
+	if c.Transport == TransportFile {
+		return
+	}
+	c.mirror = opts.Mirrors[strings.ToLower(c.Hostname)]
+}
+
+// mirrorURL returns the URL of the repository in a mirror. Mirrors set as a
+// base URL (ie file:///srv/mirrors) get the repository path appended to
+// it, otherwise the mirror is a hostname, optionally followed by a path
+// prefix, replacing the locator host.
+func mirrorURL(mirror string, c *Components) string {
+	repoPath := strings.Trim(c.RepoPath, "/")
+	if strings.Contains(mirror, "://") {
+		return strings.TrimSuffix(mirror, "/") + "/" + repoPath
+	}
+	mc := *c
+	mc.Hostname, mc.RepoPath, _ = strings.Cut(mirror, "/")
+	if mc.RepoPath != "" {
+		repoPath = strings.Trim(mc.RepoPath, "/") + "/" + repoPath
+	}
+	mc.RepoPath = repoPath
+	return mc.RepoURL()
+}
+
+// String assembles the components back into a VCS locator string.
+func (c *Components) String() string {
+	var sb strings.Builder
+	if c.Transport == TransportFile {
+		sb.WriteString("file://" + c.RepoPath)
+	} else {
+		scheme := c.Transport
+		if c.Tool != "" {
+			scheme = c.Tool + "+" + c.Transport
+		}
+		sb.WriteString(scheme + "://" + c.Hostname)
+		if c.RepoPath != "" {
+			sb.WriteString("/" + strings.TrimPrefix(c.RepoPath, "/"))
+		}
+	}
+
+	if c.RefString != "" {
+		sb.WriteString("@" + c.RefString)
+	}
+
+	if c.SubPath != "" {
+		sb.WriteString("#" + c.SubPath)
+		if c.LineStart != 0 {
+			sb.WriteString("#" + formatLineRange(c.LineStart, c.LineEnd))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Description captures the nearest tag reachable from a commit, following
+// the semantics of git describe --tags.
+type Description struct {
+	// Tag is the name of the nearest tag reachable from the commit
+	Tag string
+
+	// Distance is the number of commits reachable from the commit that are
+	// not reachable from the tag. It is zero when the commit is tagged.
+	Distance int
+
+	// Commit is the full hash of the described commit
+	Commit string
+}
+
+// String returns the description formatted like git describe does: the tag
+// name when the commit is tagged, otherwise tag-distance-gabbrev
+// (ie v1.2.0-3-g25c779b).
+func (d *Description) String() string {
+	if d.Distance == 0 {
+		return d.Tag
+	}
+	return fmt.Sprintf("%s-%d-g%s", d.Tag, d.Distance, d.Commit[:7])
+}
+
+// Describe clones the repository referenced by the locator and returns the
+// nearest tag reachable from the commit the locator resolves to, along with
+// its distance in commits. Both lightweight and annotated tags are
+// considered.
+func Describe[T ~string](locator T, funcs ...fnOpt) (*Description, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	cloned, err := cloneHistory(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	return describeCommit(cloned.Repo, plumbing.NewHash(cloned.Commit))
+}
+
+// describeCommit finds the nearest tag reachable from a commit
+func describeCommit(repo *git.Repository, hash plumbing.Hash) (*Description, error) {
+	// Index the tags by the commit they point to
+	tagged := map[plumbing.Hash][]string{}
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+		commit, err := peelToCommit(repo, ref.Hash())
+		if err != nil {
+			// Tags pointing to trees or blobs can't describe commits
+			return nil //nolint:nilerr
+		}
+		tagged[commit.Hash] = append(tagged[commit.Hash], ref.Name().Short())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Walk the history breadth first to find the closest tagged commit
+	start, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", hash, err)
+	}
+
+	var tagCommit *object.Commit
+	queue := []*object.Commit{start}
+	seen := map[plumbing.Hash]struct{}{hash: {}}
+	for len(queue) > 0 && tagCommit == nil {
+		commit := queue[0]
+		queue = queue[1:]
+		if _, ok := tagged[commit.Hash]; ok {
+			tagCommit = commit
+			break
+		}
+		if err := commit.Parents().ForEach(func(p *object.Commit) error {
+			if _, ok := seen[p.Hash]; !ok {
+				seen[p.Hash] = struct{}{}
+				queue = append(queue, p)
+			}
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("reading parents of %s: %w", commit.Hash, err)
+		}
+	}
+
+	if tagCommit == nil {
+		return nil, errors.New("no tags can describe the commit")
+	}
+
+	// Distance is the number of commits not reachable from the tag
+	tagAncestors, err := ancestors(tagCommit)
+	if err != nil {
+		return nil, err
+	}
+	commitAncestors, err := ancestors(start)
+	if err != nil {
+		return nil, err
+	}
+
+	distance := 0
+	for h := range commitAncestors {
+		if _, ok := tagAncestors[h]; !ok {
+			distance++
+		}
+	}
+
+	// When several tags point to the same commit, pick the highest name
+	names := tagged[tagCommit.Hash]
+	slices.Sort(names)
+
+	return &Description{
+		Tag:      names[len(names)-1],
+		Distance: distance,
+		Commit:   hash.String(),
+	}, nil
+}
+
+// ancestors returns the set of commits reachable from a commit, including
+// the commit itself.
+func ancestors(commit *object.Commit) (map[plumbing.Hash]struct{}, error) {
+	ret := map[plumbing.Hash]struct{}{}
+	iter := object.NewCommitPreorderIter(commit, nil, nil)
+	if err := iter.ForEach(func(c *object.Commit) error {
+		ret[c.Hash] = struct{}{}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("walking history: %w", err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	second := commitTestFile(t, repoDir, "hello.txt", "second")
+	tagTestRepo(t, repoDir, "v1.0.0", second, "")
+	third := commitTestFile(t, repoDir, "hello.txt", "third")
+	fourth := commitTestFile(t, repoDir, "hello.txt", "fourth")
+	tagTestRepo(t, repoDir, "v1.1.0", fourth, "annotated")
+	fifth := commitTestFile(t, repoDir, "hello.txt", "fifth")
+
+	for _, tc := range []struct {
+		name     string
+		commit   string
+		tag      string
+		distance int
+		mustErr  bool
+	}{
+		{"tagged", second, "v1.0.0", 0, false},
+		{"after-tag", third, "v1.0.0", 1, false},
+		{"annotated", fourth, "v1.1.0", 0, false},
+		{"after-annotated", fifth, "v1.1.0", 1, false},
+		{"before-tags", first, "", 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			d, err := Describe(fileLocator(repoDir, tc.commit, ""), noAuth)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.tag, d.Tag)
+			require.Equal(t, tc.distance, d.Distance)
+			require.Equal(t, tc.commit, d.Commit)
+		})
+	}
+
+	d := &Description{Tag: "v1.0.0", Distance: 3, Commit: "25c779ba165d1f4fac6fc2ce938bf40c1f8ab1a6"}
+	require.Equal(t, "v1.0.0-3-g25c779b", d.String())
+	d.Distance = 0
+	require.Equal(t, "v1.0.0", d.String())
+}
@@ -0,0 +1,327 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/utils/binary"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Actions of the changes returned by Diff
+const (
+	ChangeAdded    = "added"
+	ChangeModified = "modified"
+	ChangeDeleted  = "deleted"
+)
+
+// FileChange describes a file that differs between two locators
+type FileChange struct {
+	// Action is the kind of change: added, modified or deleted
+	Action string
+
+	// From and To are the paths of the file in each repository. From is
+	// empty for added files and To for deleted ones.
+	From string
+	To   string
+
+	// FromHash and ToHash are the blob hashes of the file on each side
+	FromHash string
+	ToHash   string
+}
+
+// DiffResult is the difference between the trees of two locators
+type DiffResult struct {
+	// Changes lists the files that differ, sorted by path
+	Changes []FileChange
+
+	// Patch is the unified diff of the changes in git format
+	Patch string
+}
+
+// Diff compares the subpaths of two locators and returns the files that
+// changed along with a unified diff of their contents. The locators may
+// point to the same path at two revisions or to different paths and
+// repositories: files are matched by their path relative to each locator
+// subpath. When both subpaths point to files, they are compared directly.
+// The include/exclude filters and export-ignore options are honored.
+func Diff[T ~string](from, to T, funcs ...fnOpt) (*DiffResult, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	fromTree, err := diffTree(Locator(from), &opts, funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", from, err)
+	}
+	defer fromTree.cloned.Close() //nolint:errcheck
+	toTree, err := diffTree(Locator(to), &opts, funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", to, err)
+	}
+	defer toTree.cloned.Close() //nolint:errcheck
+
+	res := &DiffResult{Changes: diffChanges(fromTree, toTree)}
+	patch := &filesPatch{}
+	for _, change := range res.Changes {
+		fp := &filePatch{}
+		if change.From != "" {
+			fp.from = &patchFile{entry: fromTree.byPath[change.From]}
+		}
+		if change.To != "" {
+			fp.to = &patchFile{entry: toTree.byPath[change.To]}
+		}
+		if err := fp.load(fromTree.cloned, toTree.cloned); err != nil {
+			return nil, err
+		}
+		patch.files = append(patch.files, fp)
+	}
+
+	var buf strings.Builder
+	if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(patch); err != nil {
+		return nil, fmt.Errorf("encoding patch: %w", err)
+	}
+	res.Patch = buf.String()
+	return res, nil
+}
+
+// diffChanges compares the files of two trees and returns the changes
+// sorted by their path relative to the locator subpaths.
+func diffChanges(fromTree, toTree *diffSide) []FileChange {
+	keys := map[string]struct{}{}
+	for k := range fromTree.files {
+		keys[k] = struct{}{}
+	}
+	for k := range toTree.files {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	changes := []FileChange{}
+	for _, k := range sorted {
+		a, inFrom := fromTree.files[k]
+		b, inTo := toTree.files[k]
+		if inFrom && inTo && a.Hash == b.Hash && a.Mode == b.Mode {
+			continue
+		}
+
+		change := FileChange{Action: ChangeModified}
+		if inFrom {
+			change.From, change.FromHash = a.Path, a.Hash.String()
+		} else {
+			change.Action = ChangeAdded
+		}
+		if inTo {
+			change.To, change.ToHash = b.Path, b.Hash.String()
+		} else {
+			change.Action = ChangeDeleted
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// ChangedFiles lists the files added, modified or deleted in the locator
+// subpath between two refs of its repository. The locator ref is ignored,
+// the trees at fromRef and toRef are compared. Only the tree entries are
+// compared, file contents are not read.
+func ChangedFiles[T ~string](locator T, fromRef, toRef string, funcs ...fnOpt) ([]FileChange, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	fromTree, err := diffTreeAt(Locator(locator), fromRef, &opts, funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", fromRef, err)
+	}
+	defer fromTree.cloned.Close() //nolint:errcheck
+	toTree, err := diffTreeAt(Locator(locator), toRef, &opts, funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", toRef, err)
+	}
+	defer toTree.cloned.Close() //nolint:errcheck
+	return diffChanges(fromTree, toTree), nil
+}
+
+// diffTreeAt lists the files to compare in the locator subpath at ref
+func diffTreeAt(l Locator, ref string, opts *options, funcs ...fnOpt) (*diffSide, error) {
+	if ref == "" {
+		return nil, errors.New("ref to compare is empty")
+	}
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing locator: %w", err)
+	}
+	if err := components.setRef(ref, opts); err != nil {
+		return nil, err
+	}
+	return diffTree(Locator(components.String()), opts, funcs...)
+}
+
+// diffSide is one of the trees compared by Diff
+type diffSide struct {
+	cloned *clonedRepo
+
+	// files are the file entries of the tree keyed by their path relative
+	// to the locator subpath
+	files map[string]*archiveEntry
+
+	// byPath indexes the file entries by their path in the repository
+	byPath map[string]*archiveEntry
+}
+
+// diffTree clones the repository of a locator and lists the files to compare
+func diffTree(l Locator, opts *options, funcs ...fnOpt) (*diffSide, error) {
+	cloned, err := cloneRepo(l, opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := archiveEntries(cloned, opts)
+	if err != nil {
+		cloned.Close() //nolint:errcheck,gosec
+		return nil, err
+	}
+
+	subpath := strings.Trim(cloned.Components.SubPath, "/")
+	side := &diffSide{
+		cloned: cloned,
+		files:  map[string]*archiveEntry{},
+		byPath: map[string]*archiveEntry{},
+	}
+	for i := range entries {
+		if entries[i].isDir() {
+			continue
+		}
+		// Files are keyed relative to the subpath, a file subpath is ""
+		key := entries[i].Path
+		if subpath != "" {
+			key = strings.TrimPrefix(strings.TrimPrefix(key, subpath), "/")
+		}
+		side.files[key] = &entries[i]
+		side.byPath[entries[i].Path] = &entries[i]
+	}
+	return side, nil
+}
+
+// filesPatch implements the go-git diff.Patch interface to encode the
+// changes as a unified diff.
+type filesPatch struct {
+	files []fdiff.FilePatch
+}
+
+func (p *filesPatch) FilePatches() []fdiff.FilePatch { return p.files }
+func (p *filesPatch) Message() string                { return "" }
+
+// filePatch is the diff of a single file
+type filePatch struct {
+	from, to *patchFile
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+// load reads the contents of both sides of the patch and computes the chunks
+func (fp *filePatch) load(fromRepo, toRepo *clonedRepo) error {
+	fromContent, fromBinary, err := fp.from.content(fromRepo)
+	if err != nil {
+		return err
+	}
+	toContent, toBinary, err := fp.to.content(toRepo)
+	if err != nil {
+		return err
+	}
+	if fromBinary || toBinary {
+		fp.binary = true
+		return nil
+	}
+
+	for _, d := range diff.Do(fromContent, toContent) {
+		var op fdiff.Operation
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			op = fdiff.Equal
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		}
+		fp.chunks = append(fp.chunks, &patchChunk{content: d.Text, op: op})
+	}
+	return nil
+}
+
+func (fp *filePatch) IsBinary() bool { return fp.binary }
+
+func (fp *filePatch) Chunks() []fdiff.Chunk { return fp.chunks }
+
+func (fp *filePatch) Files() (from, to fdiff.File) {
+	// Avoid returning typed nils in the interfaces
+	if fp.from != nil {
+		from = fp.from
+	}
+	if fp.to != nil {
+		to = fp.to
+	}
+	return from, to
+}
+
+// patchFile is one side of a file patch
+type patchFile struct {
+	entry *archiveEntry
+}
+
+func (f *patchFile) Hash() plumbing.Hash     { return f.entry.Hash }
+func (f *patchFile) Mode() filemode.FileMode { return f.entry.Mode }
+func (f *patchFile) Path() string            { return f.entry.Path }
+
+// content reads the data of the file and checks if it is binary. Missing
+// files (nil) are empty.
+func (f *patchFile) content(cloned *clonedRepo) (string, bool, error) {
+	if f == nil {
+		return "", false, nil
+	}
+	r, _, err := openBlob(cloned, f.entry)
+	if err != nil {
+		return "", false, err
+	}
+	defer r.Close() //nolint:errcheck
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", false, fmt.Errorf("reading %q: %w", f.entry.Path, err)
+	}
+	isBinary, err := binary.IsBinary(bytes.NewReader(data))
+	if err != nil {
+		return "", false, fmt.Errorf("checking %q: %w", f.entry.Path, err)
+	}
+	return string(data), isBinary, nil
+}
+
+// patchChunk is a chunk of a file diff
+type patchChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c *patchChunk) Content() string       { return c.content }
+func (c *patchChunk) Type() fdiff.Operation { return c.op }
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"policy/a.yaml": "a: 1\nb: 2\n",
+		"policy/b.yaml": "b\n",
+		"README.md":     "readme\n",
+	})
+	commitTestFile(t, repoDir, "policy/a.yaml", "a: 1\nb: 3\n")
+	commitTestFile(t, repoDir, "policy/c.yaml", "c\n")
+	commitTestFile(t, repoDir, "README.md", "readme v2\n")
+
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(repoDir, "policy", "b.yaml")))
+	_, err = wt.Add("policy/b.yaml")
+	require.NoError(t, err)
+	last := commitTestFile(t, repoDir, "other/a.yaml", "a: 1\nb: 2\n")
+
+	t.Run("directory", func(t *testing.T) {
+		t.Parallel()
+		res, err := Diff(fileLocator(repoDir, first, "policy"), fileLocator(repoDir, last, "policy"))
+		require.NoError(t, err)
+		require.Len(t, res.Changes, 3)
+		require.Equal(t, ChangeModified, res.Changes[0].Action)
+		require.Equal(t, "policy/a.yaml", res.Changes[0].From)
+		require.Equal(t, "policy/a.yaml", res.Changes[0].To)
+		require.Equal(t, ChangeDeleted, res.Changes[1].Action)
+		require.Equal(t, "policy/b.yaml", res.Changes[1].From)
+		require.Empty(t, res.Changes[1].To)
+		require.Equal(t, ChangeAdded, res.Changes[2].Action)
+		require.Equal(t, "policy/c.yaml", res.Changes[2].To)
+		require.Empty(t, res.Changes[2].FromHash)
+
+		require.Contains(t, res.Patch, "diff --git a/policy/a.yaml b/policy/a.yaml\n")
+		require.Contains(t, res.Patch, "-b: 2\n+b: 3\n")
+		require.Contains(t, res.Patch, "deleted file mode 100644\n")
+		require.Contains(t, res.Patch, "+c\n")
+		require.NotContains(t, res.Patch, "README.md")
+	})
+
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		res, err := Diff(fileLocator(repoDir, first, "README.md"), fileLocator(repoDir, last, "README.md"))
+		require.NoError(t, err)
+		require.Len(t, res.Changes, 1)
+		require.Contains(t, res.Patch, "-readme\n+readme v2\n")
+	})
+
+	t.Run("different-paths", func(t *testing.T) {
+		t.Parallel()
+		res, err := Diff(fileLocator(repoDir, first, "policy/a.yaml"), fileLocator(repoDir, last, "other/a.yaml"))
+		require.NoError(t, err)
+		require.Empty(t, res.Changes)
+		require.Empty(t, res.Patch)
+	})
+
+	t.Run("different-repos", func(t *testing.T) {
+		t.Parallel()
+		otherDir, otherCommit := initTestRepoWithFiles(t, map[string]string{
+			"config/a.yaml": "a: 1\nb: 3\n",
+			"config/c.yaml": "c\n",
+		})
+		res, err := Diff(fileLocator(repoDir, last, "policy"), fileLocator(otherDir, otherCommit, "config"))
+		require.NoError(t, err)
+		require.Empty(t, res.Changes)
+	})
+}
+
+func TestChangedFiles(t *testing.T) {
+	t.Parallel()
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"src/a.go":  "package a\n",
+		"src/b.go":  "package a\n",
+		"README.md": "readme\n",
+	})
+	tagTestRepo(t, repoDir, "v1.0.0", first, "")
+	commitTestFile(t, repoDir, "src/a.go", "package a\n\nvar x = 1\n")
+	commitTestFile(t, repoDir, "src/sub/c.go", "package sub\n")
+	last := commitTestFile(t, repoDir, "README.md", "readme v2\n")
+
+	actions := func(changes []FileChange) map[string]string {
+		ret := map[string]string{}
+		for _, c := range changes {
+			p := c.To
+			if p == "" {
+				p = c.From
+			}
+			ret[p] = c.Action
+		}
+		return ret
+	}
+
+	changes, err := ChangedFiles("file://"+repoDir, "v1.0.0", last)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"README.md":    ChangeModified,
+		"src/a.go":     ChangeModified,
+		"src/sub/c.go": ChangeAdded,
+	}, actions(changes))
+
+	changes, err = ChangedFiles(fileLocator(repoDir, "refs/heads/master", "src"), last, "v1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"src/a.go":     ChangeModified,
+		"src/sub/c.go": ChangeDeleted,
+	}, actions(changes))
+
+	changes, err = ChangedFiles("file://"+repoDir, last, last)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	_, err = ChangedFiles("file://"+repoDir, "", last)
+	require.Error(t, err)
+}
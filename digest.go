@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha1" //nolint:gosec // Used to match git-style digests, not for security
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// digestAlgorithms maps the supported digest algorithm labels to their
+// hash constructors.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// DigestMismatchError is returned when the data fetched from a locator does
+// not match the digest expected by the caller.
+type DigestMismatchError struct {
+	// Locator is the locator whose data failed the check
+	Locator string
+
+	// Algorithm is the digest algorithm (ie sha256)
+	Algorithm string
+
+	// Expected and Actual are the hex encoded digests
+	Expected string
+	Actual   string
+}
+
+func (e *DigestMismatchError) Error() string {
+	return fmt.Sprintf(
+		"%s digest mismatch for %s: expected %s, got %s",
+		e.Algorithm, e.Locator, e.Expected, e.Actual,
+	)
+}
+
+// parseDigest splits a digest string in the form algorithm:hex and checks
+// the algorithm is supported.
+func parseDigest(digest string) (algorithm, value string, err error) {
+	algorithm, value, ok := strings.Cut(digest, ":")
+	if !ok || algorithm == "" || value == "" {
+		return "", "", fmt.Errorf("invalid digest %q, expected algorithm:hex", digest)
+	}
+	algorithm = strings.ToLower(algorithm)
+	newHash, ok := digestAlgorithms[algorithm]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	value = strings.ToLower(value)
+	if _, err := hex.DecodeString(value); err != nil || len(value) != newHash().Size()*2 {
+		return "", "", fmt.Errorf("invalid %s digest value %q", algorithm, value)
+	}
+	return algorithm, value, nil
+}
+
+// digestWriter passes data through to a writer while hashing it
+type digestWriter struct {
+	io.Writer
+	hasher    hash.Hash
+	algorithm string
+	expected  string
+}
+
+// newDigestWriter wraps w to verify the data written against the digest. If
+// the digest is empty, the writer is returned unchanged and verify is a no-op.
+func newDigestWriter(w io.Writer, digest string) (*digestWriter, error) {
+	if digest == "" {
+		return &digestWriter{Writer: w}, nil
+	}
+	algorithm, value, err := parseDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	hasher := digestAlgorithms[algorithm]()
+	return &digestWriter{
+		Writer:    io.MultiWriter(w, hasher),
+		hasher:    hasher,
+		algorithm: algorithm,
+		expected:  value,
+	}, nil
+}
+
+// verify checks the digest of the data written against the expected value
+func (dw *digestWriter) verify(locator string) error {
+	if dw.hasher == nil {
+		return nil
+	}
+	actual := hex.EncodeToString(dw.hasher.Sum(nil))
+	if actual != dw.expected {
+		return &DigestMismatchError{
+			Locator:   locator,
+			Algorithm: dw.algorithm,
+			Expected:  dw.expected,
+			Actual:    actual,
+		}
+	}
+	return nil
+}
+
+// digestReader hashes the data read from a stream and checks it against
+// the expected digest once the stream is exhausted.
+type digestReader struct {
+	io.ReadCloser
+	dw      *digestWriter
+	locator string
+}
+
+// Read implements io.Reader. When the underlying stream reaches EOF, the
+// digest is verified and a mismatch is returned instead of io.EOF.
+func (dr *digestReader) Read(p []byte) (int, error) {
+	n, err := dr.ReadCloser.Read(p)
+	if n > 0 {
+		dr.dw.Write(p[:n]) //nolint:errcheck,gosec // Hashes never return errors
+	}
+	if errors.Is(err, io.EOF) {
+		if verr := dr.dw.verify(dr.locator); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
+
+// newDigestReader wraps rc to verify the data read against the digest. If
+// the digest is empty, the reader is returned unchanged.
+func newDigestReader(rc io.ReadCloser, digest, locator string) (io.ReadCloser, error) {
+	if digest == "" {
+		return rc, nil
+	}
+	dw, err := newDigestWriter(io.Discard, digest)
+	if err != nil {
+		return nil, err
+	}
+	return &digestReader{ReadCloser: rc, dw: dw, locator: locator}, nil
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Digest(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestParseDigest(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name    string
+		digest  string
+		algo    string
+		mustErr bool
+	}{
+		{"sha256", sha256Digest("hello"), "sha256", false},
+		{"uppercase", strings.ToUpper(sha256Digest("hello")), "sha256", false},
+		{"sha1", "sha1:aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d", "sha1", false},
+		{"no-algorithm", "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d", "", true},
+		{"unknown-algorithm", "md5:5d41402abc4b2a76b9719d911017c592", "", true},
+		{"bad-length", "sha256:abcd", "", true},
+		{"not-hex", "sha1:zzf4c61ddcc5e8a2dabede0f3b482cd9aea9434d", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			algo, _, err := parseDigest(tc.digest)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.algo, algo)
+		})
+	}
+}
+
+func TestCopyFileExpectedDigest(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"hello.txt": "hello world",
+		"bye.txt":   "bye world",
+	})
+
+	t.Run("match", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		err := CopyFile(
+			fileLocator(repoDir, commitHash, "hello.txt"), &buf,
+			noAuth, WithExpectedDigest(sha256Digest("hello world")),
+		)
+		require.NoError(t, err)
+		require.Equal(t, "hello world", buf.String())
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		t.Parallel()
+		err := CopyFile(
+			fileLocator(repoDir, commitHash, "hello.txt"), io.Discard,
+			noAuth, WithExpectedDigest(sha256Digest("something else")),
+		)
+		require.Error(t, err)
+		var mismatch *DigestMismatchError
+		require.True(t, errors.As(err, &mismatch))
+		require.Equal(t, "sha256", mismatch.Algorithm)
+		require.Equal(t, strings.TrimPrefix(sha256Digest("hello world"), "sha256:"), mismatch.Actual)
+	})
+
+	t.Run("invalid-digest", func(t *testing.T) {
+		t.Parallel()
+		err := CopyFile(
+			fileLocator(repoDir, commitHash, "hello.txt"), io.Discard,
+			noAuth, WithExpectedDigest("sha256:nope"),
+		)
+		require.Error(t, err)
+	})
+
+	t.Run("group", func(t *testing.T) {
+		t.Parallel()
+		locators := []string{
+			fileLocator(repoDir, commitHash, "hello.txt"),
+			fileLocator(repoDir, commitHash, "bye.txt"),
+		}
+		data, err := GetGroup(locators, noAuth, WithExpectedDigest(sha256Digest("hello world"), ""))
+		require.NoError(t, err)
+		require.Equal(t, "bye world", string(data[1]))
+
+		_, err = GetGroup(locators, noAuth, WithExpectedDigest("", sha256Digest("hello world")))
+		require.Error(t, err)
+		var mismatch *DigestMismatchError
+		require.True(t, errors.As(err, &mismatch))
+		require.Equal(t, locators[1], mismatch.Locator)
+
+		_, err = GetGroup(locators, noAuth, WithExpectedDigest(sha256Digest("hello world")))
+		require.Error(t, err)
+	})
+}
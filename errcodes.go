@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Error codes identifying the kinds of errors returned by the package. The
+// codes are stable, programs can rely on them to react to errors. They are
+// read from errors with ErrorCode.
+const (
+	// CodeParse is returned when a locator cannot be parsed
+	CodeParse = "VL001"
+
+	// CodeAuth is returned when the remote requires credentials or rejects
+	// the ones provided
+	CodeAuth = "VL401"
+
+	// CodePolicy is returned when a locator is not allowed by the policy
+	// or the allowed and blocked host lists
+	CodePolicy = "VL403"
+
+	// CodeNotFound is returned when the repository, ref, path or note
+	// referenced by a locator does not exist
+	CodeNotFound = "VL404"
+
+	// CodeDigestMismatch is returned when the data fetched does not match
+	// the expected digest
+	CodeDigestMismatch = "VL412"
+
+	// CodeSizeLimit is returned when the data fetched exceeds a size limit
+	CodeSizeLimit = "VL413"
+
+	// CodeHostUnavailable is returned by group operations for the locators
+	// skipped after their host failed repeatedly
+	CodeHostUnavailable = "VL502"
+
+	// CodeNotCached is returned in offline mode when the data is not in the
+	// local copies of the repository
+	CodeNotCached = "VL503"
+)
+
+// ParseError is returned when a locator string cannot be parsed
+type ParseError struct {
+	// Locator is the string that failed to parse
+	Locator string
+
+	// Err is the parsing error
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the error code of parsing errors
+func (e *ParseError) Code() string { return CodeParse }
+
+// Code returns the error code of policy violations
+func (e *PolicyViolationError) Code() string { return CodePolicy }
+
+// Code returns the error code of digest mismatches
+func (e *DigestMismatchError) Code() string { return CodeDigestMismatch }
+
+// Code returns the error code of exceeded size limits
+func (e *SizeLimitError) Code() string { return CodeSizeLimit }
+
+// Code returns the error code of locators skipped by the circuit breaker
+func (e *HostUnavailableError) Code() string { return CodeHostUnavailable }
+
+// Code returns the error code of data missing in offline mode
+func (e *NotCachedError) Code() string { return CodeNotCached }
+
+// Codes returns the error code of each error in the list, in the same
+// order. Entries without an error or without a code are empty strings.
+func (el *ErrorList) Codes() []string {
+	codes := make([]string, len(el.Errors))
+	for i, err := range el.Errors {
+		codes[i] = ErrorCode(err)
+	}
+	return codes
+}
+
+// notFoundErrors are the errors of the package and go-git reporting
+// missing data
+var notFoundErrors = []error{
+	fs.ErrNotExist,
+	ErrNoteNotFound,
+	plumbing.ErrReferenceNotFound,
+	plumbing.ErrObjectNotFound,
+	object.ErrFileNotFound,
+	object.ErrDirectoryNotFound,
+	object.ErrEntryNotFound,
+	transport.ErrRepositoryNotFound,
+	transport.ErrEmptyRemoteRepository,
+	git.NoMatchingRefSpecError{},
+}
+
+// authErrors are the go-git transport errors caused by credentials
+var authErrors = []error{
+	transport.ErrAuthenticationRequired,
+	transport.ErrAuthorizationFailed,
+	transport.ErrInvalidAuthMethod,
+}
+
+// ErrorCode returns the code of the first error in the chain of err that
+// has one (see the Code constants), or an empty string if none has. For
+// ErrorLists, the code of the first error with one is returned, use
+// ErrorList.Codes to get the code of each error.
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var coded interface{ Code() string }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	for _, target := range authErrors {
+		if errors.Is(err, target) {
+			return CodeAuth
+		}
+	}
+	for _, target := range notFoundErrors {
+		if errors.Is(err, target) {
+			return CodeNotFound
+		}
+	}
+	return ""
+}
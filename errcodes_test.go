@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCode(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{"README.md": "hello"})
+
+	for _, tc := range []struct {
+		name    string
+		locator string
+		opts    []fnOpt
+		code    string
+	}{
+		{"ok", fileLocator(repoDir, commit, "README.md"), nil, ""},
+		{"parse", "ftp://example.com/repo#README.md", nil, CodeParse},
+		{"missing-file", fileLocator(repoDir, commit, "nope.md"), nil, CodeNotFound},
+		{"missing-ref", fileLocator(repoDir, "nope", "README.md"), nil, CodeNotFound},
+		{"policy", fileLocator(repoDir, commit, "README.md"), []fnOpt{WithAllowLocal(false)}, CodePolicy},
+		{"digest", fileLocator(repoDir, commit, "README.md"), []fnOpt{WithExpectedDigest("sha256:" + fmt.Sprintf("%064x", 0))}, CodeDigestMismatch},
+		{"size", fileLocator(repoDir, commit, "README.md"), []fnOpt{WithMaxFileSize(1)}, CodeSizeLimit},
+		{"offline", fileLocator(repoDir, commit, "README.md"), []fnOpt{WithOffline(true)}, CodeNotCached},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := CopyFile(tc.locator, io.Discard, append(tc.opts, noAuth)...)
+			require.Equal(t, tc.code, ErrorCode(err), err)
+		})
+	}
+
+	t.Run("auth", func(t *testing.T) {
+		t.Parallel()
+		err := fmt.Errorf("cloning repository: %w", transport.ErrAuthenticationRequired)
+		require.Equal(t, CodeAuth, ErrorCode(err))
+		require.Empty(t, ErrorCode(errors.New("unknown")))
+	})
+
+	t.Run("error-list", func(t *testing.T) {
+		t.Parallel()
+		err := CopyFileGroup(
+			[]string{fileLocator(repoDir, commit, "README.md"), fileLocator(repoDir, commit, "nope.md")},
+			[]io.Writer{io.Discard, io.Discard}, noAuth,
+		)
+		var list *ErrorList
+		require.True(t, errors.As(err, &list), err)
+		require.Equal(t, []string{"", CodeNotFound}, list.Codes())
+		require.Equal(t, CodeNotFound, ErrorCode(err))
+	})
+
+	t.Run("group-parse", func(t *testing.T) {
+		t.Parallel()
+		err := CopyFileGroup(
+			[]string{fileLocator(repoDir, commit, "README.md"), "ftp://example.com/repo#README.md"},
+			[]io.Writer{io.Discard, io.Discard}, noAuth,
+		)
+		require.ErrorContains(t, err, "parsing locator 1")
+		require.Equal(t, CodeParse, ErrorCode(err))
+	})
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+// fetchRef initializes an empty repository in the storer and fetches a
+// single reference from the remote, storing it under the same name. If
+// fsobj is nil, the repository is initialized without a worktree. A depth
+// of zero fetches the full history of the ref.
+func fetchRef(st storage.Storer, fsobj billy.Filesystem, repourl, ref string, auth transport.AuthMethod, depth int) (*git.Repository, error) {
+	repo, err := fetchRefSpecs(st, fsobj, repourl, []config.RefSpec{
+		config.RefSpec(fmt.Sprintf("%s:%s", ref, ref)),
+	}, auth, depth)
+	if err != nil {
+		return nil, fmt.Errorf("fetching ref %q: %w", ref, err)
+	}
+	return repo, nil
+}
+
+// fetchRefSpecs initializes an empty repository in the storer and fetches
+// the refspecs from the remote.
+func fetchRefSpecs(st storage.Storer, fsobj billy.Filesystem, repourl string, refspecs []config.RefSpec, auth transport.AuthMethod, depth int) (*git.Repository, error) {
+	repo, err := git.Init(st, fsobj)
+	if err != nil {
+		return nil, fmt.Errorf("initializing repo: %w", err)
+	}
+
+	if _, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repourl},
+	}); err != nil {
+		return nil, fmt.Errorf("creating remote: %w", err)
+	}
+
+	if err = repo.Fetch(&git.FetchOptions{
+		Auth:     auth,
+		Depth:    depth,
+		RefSpecs: refspecs,
+	}); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// errNoForgeAPI is returned when the host of a locator has no known API
+var errNoForgeAPI = errors.New("no forge API known for host")
+
+// forgeEligible returns true when the data of the components can be read
+// through a forge API. Only full commit hashes are fetched, as the data
+// they point to cannot change, and only when the clone would read it from
+// the remote itself. Hosts are never probed, only those with a provider
+// assigned are eligible.
+func forgeEligible(c *Components, opts *options) bool {
+	return opts.ForgeAPI && !opts.Offline && opts.ClonePath == "" &&
+		c.Tool == ToolGit && c.Transport == TransportHTTPS && c.mirror == "" &&
+		c.AsOf.IsZero() && plumbing.IsHash(c.Commit) &&
+		lookupProvider(c.Hostname, opts) != nil
+}
+
+// useForgeAPI returns true when the file of the components can be fetched
+// through the forge API
+func useForgeAPI(c *Components, opts *options) bool {
+	return forgeEligible(c, opts) && c.SubPath != ""
+}
+
+// ownerRepo splits the path of repositories named owner/repo, as in most
+// forges, trimming the .git suffix
+func ownerRepo(c *Components) (owner, repo string, err error) {
+	owner, repo, ok := strings.Cut(strings.Trim(c.RepoPath, "/"), "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("%q is not an owner/repository path", c.RepoPath)
+	}
+	return owner, strings.TrimSuffix(repo, ".git"), nil
+}
+
+// openForgeFile opens the file of a locator through the API of its forge.
+// Callers clone the repository when it returns an error.
+func openForgeFile(l Locator, c *Components, opts *options, funcs ...fnOpt) (rc io.ReadCloser, err error) {
+	defer func() {
+		if err != nil && !errors.Is(err, errNoForgeAPI) {
+			opts.progressf("forge API fetch failed, cloning: %v\n", err)
+		}
+	}()
+
+	auth, err := prepareRemote(l, c, opts)
+	if err != nil {
+		return nil, err
+	}
+	provider := lookupProvider(c.Hostname, opts)
+	if provider == nil {
+		return nil, fmt.Errorf("%w %s", errNoForgeAPI, c.Hostname)
+	}
+	opts.progressf("fetching %s\n", c.String())
+
+	resp, err := provider.OpenFile(opts.forgeClient(), auth, c)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MaxFileSize > 0 && resp.ContentLength > opts.MaxFileSize {
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, &SizeLimitError{
+			Kind: SizeLimitFile, Limit: opts.MaxFileSize, Size: resp.ContentLength, Object: c.SubPath,
+		}
+	}
+
+	// Forges serve the pointer of files stored in LFS, those are read
+	// from the clone which knows how to smudge them.
+	br := bufio.NewReaderSize(resp.Body, lfsPointerMaxSize+1)
+	head, err := br.Peek(lfsPointerMaxSize + 1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	if _, ok := parseLFSPointer(head); ok && opts.LFS {
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("%q is stored in LFS", c.SubPath)
+	}
+
+	rc = &peekedReader{Reader: br, Closer: resp.Body}
+	if opts.MaxFileSize > 0 {
+		rc = &sizeLimitReader{ReadCloser: rc, limit: opts.MaxFileSize, object: c.SubPath}
+	}
+	return rc, nil
+}
+
+// sizeLimitReader fails when the data read exceeds the file size limit,
+// for responses that do not declare their length
+type sizeLimitReader struct {
+	io.ReadCloser
+	limit  int64
+	read   int64
+	object string
+}
+
+func (s *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	s.read += int64(n)
+	if s.read > s.limit {
+		return n, &SizeLimitError{Kind: SizeLimitFile, Limit: s.limit, Size: s.read, Object: s.object}
+	}
+	return n, err
+}
+
+// forgeGet performs a GET request to a forge API, returning the response
+// if the status is 200.
+func forgeGet(client *http.Client, auth transport.AuthMethod, u string, header map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating API request: %w", err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if a, ok := auth.(githttp.AuthMethod); ok {
+		a.SetAuth(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting %s: %w", u, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("requesting %s: http status %d", u, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// readForgeJSON decodes the JSON body of a forge API response into v,
+// closing it
+func readForgeJSON(resp *http.Response, v any) error {
+	defer resp.Body.Close() //nolint:errcheck
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding API response: %w", err)
+	}
+	return nil
+}
+
+// escapePath escapes the segments of a slash separated path for a URL
+func escapePath(p string) string {
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testCommit = "0123456789abcdef0123456789abcdef01234567"
+
+// forgeTestClient returns a client connecting to a test server whatever
+// the host requested, as locators cannot carry the port of the server. The
+// server certificate is verified as issued to example.com.
+func forgeTestClient(srv *httptest.Server) *http.Client {
+	client := srv.Client()
+	tr := client.Transport.(*http.Transport).Clone() //nolint:forcetypeassert
+	tr.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+	tr.TLSClientConfig.ServerName = "example.com"
+	client.Transport = tr
+	return client
+}
+
+// forgeTestServer is a fake forge answering the API requests of any host.
+// It is not a git remote: fetches falling back to cloning it fail.
+type forgeTestServer struct {
+	client *http.Client
+}
+
+// newForgeTestServer starts a fake forge serving the API with handler.
+func newForgeTestServer(t *testing.T, handler http.Handler) *forgeTestServer {
+	t.Helper()
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+	return &forgeTestServer{client: forgeTestClient(srv)}
+}
+
+// forgeTest is a fetch from a fake forge. The fetches expected to fail
+// through the API fail altogether, as the fallback clone fails.
+type forgeTest struct {
+	name    string
+	locator string
+	funcs   []fnOpt
+	expect  any
+	mustErr bool
+}
+
+// forgeFetch fetches the data of a locator in a forge test.
+type forgeFetch func(t *testing.T, locator string, funcs ...fnOpt) (any, error)
+
+// copyForgeFile is the forgeFetch reading a file with CopyFile.
+func copyForgeFile(_ *testing.T, locator string, funcs ...fnOpt) (any, error) {
+	var b bytes.Buffer
+	err := CopyFile(locator, &b, funcs...)
+	return b.String(), err
+}
+
+// run fetches the tests from the server in parallel subtests, with the
+// options of the provider followed by those of each test.
+func (s *forgeTestServer) run(t *testing.T, fetch forgeFetch, provider []fnOpt, tests []forgeTest) {
+	t.Helper()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			funcs := append(append([]fnOpt{WithHttpClient(s.client)}, provider...), tc.funcs...)
+			got, err := fetch(t, tc.locator, funcs...)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestUseForgeAPI(t *testing.T) {
+	t.Parallel()
+	pinned := "git+https://github.com/org/repo@" + testCommit + "#README.md"
+	for _, tc := range []struct {
+		name    string
+		locator string
+		funcs   []fnOpt
+		expect  bool
+	}{
+		{"pinned", pinned, nil, true},
+		{"unknown-host", "git+https://git.example.com/org/repo@" + testCommit + "#README.md", nil, false},
+		{"declared-host", "git+https://git.example.com/org/repo@" + testCommit + "#README.md", []fnOpt{WithGiteaHosts("git.example.com")}, true},
+		{"tag", "git+https://github.com/org/repo@v1.0.0#README.md", nil, false},
+		{"abbreviated", "git+https://github.com/org/repo@0123456#README.md", nil, false},
+		{"no-subpath", "git+https://github.com/org/repo@" + testCommit, nil, false},
+		{"ssh", "git+ssh://github.com/org/repo@" + testCommit + "#README.md", nil, false},
+		{"disabled", pinned, []fnOpt{WithForgeAPI(false)}, false},
+		{"offline", pinned, []fnOpt{WithOffline(true)}, false},
+		{"mirror", pinned, []fnOpt{WithMirrors(map[string]string{"github.com": "mirror.example.com"})}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			opts := defaultOptions
+			for _, fn := range tc.funcs {
+				require.NoError(t, fn(&opts))
+			}
+			c, err := Locator(tc.locator).Parse(tc.funcs...)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, useForgeAPI(c, &opts))
+		})
+	}
+}
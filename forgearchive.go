@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// useForgeArchive returns true when Download can extract the files from
+// the archive of the commit served by the forge. Downloads needing the
+// repository itself (its git directory or submodules) are always cloned.
+func useForgeArchive(c *Components, opts *options) bool {
+	return forgeEligible(c, opts) && !opts.KeepGitDir && !opts.Submodules
+}
+
+// downloadForgeArchive writes the files in the subpath of a locator to the
+// download target from the tarball of the commit served by its forge.
+// Callers clone the repository when it returns an error.
+//
+// Forges produce the archives with git archive, which drops the paths
+// marked export-ignore and expands the export-subst placeholders. When the
+// .gitattributes files use them (or the archive holds data the clone
+// handles, such as symbolic links to follow or LFS pointers) the archive is
+// not used. Unless export-ignore is honored, all the .gitattributes files
+// are read from the tree first, as they can drop themselves from the
+// archive. Forges that can't list the tree are cloned then.
+func downloadForgeArchive(l Locator, c *Components, opts *options, target *downloadTarget, funcs ...fnOpt) (err error) {
+	defer func() {
+		if err != nil && !errors.Is(err, errNoForgeAPI) {
+			opts.progressf("forge archive download failed, cloning: %v\n", err)
+		}
+	}()
+
+	auth, err := prepareRemote(l, c, opts)
+	if err != nil {
+		return err
+	}
+	provider := lookupProvider(c.Hostname, opts)
+	if provider == nil {
+		return fmt.Errorf("%w %s", errNoForgeAPI, c.Hostname)
+	}
+	if !opts.ExportIgnore {
+		if err := checkTreeAttributes(provider, auth, c, opts); err != nil {
+			return err
+		}
+	}
+	opts.progressf("fetching archive of %s\n", c.String())
+
+	resp, err := provider.OpenArchive(opts.forgeClient(), auth, c)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	subpath := strings.Trim(c.SubPath, "/")
+	found := false
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+
+		// Entries are nested in a top level directory
+		_, p, ok := strings.Cut(hdr.Name, "/")
+		p = strings.Trim(p, "/")
+		if !ok || p == "" || (hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeSymlink) {
+			continue
+		}
+
+		var src io.Reader = tr
+		if path.Base(p) == ".gitattributes" {
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return fmt.Errorf("reading %q: %w", p, err)
+			}
+			if err := checkArchiveAttributes(p, data, opts); err != nil {
+				return err
+			}
+			src = bytes.NewReader(data)
+		}
+
+		rel, ok := subpathRel(p, subpath)
+		if !ok {
+			continue
+		}
+		found = true
+		if !matchFilters(rel, opts.Include, opts.Exclude) {
+			continue
+		}
+
+		if hdr.Typeflag == tar.TypeSymlink {
+			if opts.Symlinks == SymlinksSkip {
+				continue
+			}
+			return fmt.Errorf("%q is a symbolic link", p)
+		}
+
+		total += hdr.Size
+		switch {
+		case opts.MaxFileSize > 0 && hdr.Size > opts.MaxFileSize:
+			return &SizeLimitError{Kind: SizeLimitFile, Limit: opts.MaxFileSize, Size: hdr.Size, Object: p}
+		case opts.MaxRepoSize > 0 && total > opts.MaxRepoSize:
+			return &SizeLimitError{Kind: SizeLimitRepository, Limit: opts.MaxRepoSize, Size: total, Object: p}
+		}
+
+		// Forges archive the pointers of the files stored in LFS
+		if opts.LFS && hdr.Size <= lfsPointerMaxSize {
+			data, err := io.ReadAll(src)
+			if err != nil {
+				return fmt.Errorf("reading %q: %w", p, err)
+			}
+			if _, ok := parseLFSPointer(data); ok {
+				return fmt.Errorf("%q is stored in LFS", p)
+			}
+			src = bytes.NewReader(data)
+		}
+
+		destPath := p
+		if windowsPaths {
+			destPath, err = windowsSafePath(p, opts.IllegalNames)
+			if err != nil {
+				return err
+			}
+			if destPath == "" {
+				continue
+			}
+		}
+		target.written[filepath.FromSlash(destPath)] = struct{}{}
+
+		dest, err := prepareDestination(target.staging, destPath)
+		if err != nil {
+			return err
+		}
+		opts.progressf("writing %s\n", destPath)
+
+		perm := os.FileMode(0o644)
+		if hdr.Mode&0o111 != 0 {
+			perm = os.FileMode(0o755)
+		}
+		if err := target.writeFile(dest, perm, src, hdr.Size); err != nil {
+			return err
+		}
+	}
+
+	// Missing subpaths are reported by the clone
+	if !found && subpath != "" {
+		return fmt.Errorf("%q not found in archive", subpath)
+	}
+	return nil
+}
+
+// checkTreeAttributes reads all the .gitattributes files of the commit
+// through the forge API and checks they set no archive attributes. It fails
+// when the provider can't list the files of the commit.
+func checkTreeAttributes(provider Provider, auth transport.AuthMethod, c *Components, opts *options) error {
+	lister, ok := provider.(TreeProvider)
+	if !ok {
+		return fmt.Errorf("%s API can't list the .gitattributes files", provider.Name())
+	}
+	files, err := lister.ListFiles(opts.forgeClient(), auth, c)
+	if err != nil {
+		return fmt.Errorf("listing files: %w", err)
+	}
+
+	for _, p := range files {
+		if path.Base(p) != ".gitattributes" {
+			continue
+		}
+		attrs := *c
+		attrs.SubPath = p
+		resp, err := provider.OpenFile(opts.forgeClient(), auth, &attrs)
+		if err != nil {
+			return fmt.Errorf("reading %q: %w", p, err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close() //nolint:errcheck,gosec
+		if err != nil {
+			return fmt.Errorf("reading %q: %w", p, err)
+		}
+		if err := checkArchiveAttributes(p, data, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkArchiveAttributes returns an error when an attributes file sets the
+// attributes making git archive differ from the tree
+func checkArchiveAttributes(p string, data []byte, opts *options) error {
+	if bytes.Contains(data, []byte("export-subst")) ||
+		(!opts.ExportIgnore && bytes.Contains(data, []byte("export-ignore"))) {
+		return fmt.Errorf("%q sets archive attributes", p)
+	}
+	return nil
+}
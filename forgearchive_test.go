@@ -0,0 +1,284 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testArchiveEntry is an entry of the archives served by the test forge
+type testArchiveEntry struct {
+	name, data, link string
+	mode             int64
+}
+
+// newTestArchive builds a gzipped tarball with the entries nested in a top
+// level directory, as forges serve them
+func newTestArchive(t *testing.T, entries []testArchiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header",
+		PAXRecords: map[string]string{"comment": testCommit},
+	}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "repo-0123456/", Mode: 0o755}))
+	for _, e := range entries {
+		hdr := &tar.Header{Name: "repo-0123456/" + e.name, Mode: e.mode, Size: int64(len(e.data)), Typeflag: tar.TypeReg}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0o644
+		}
+		if e.link != "" {
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.link, 0
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		if e.link == "" {
+			_, err := tw.Write([]byte(e.data))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestDownloadForgeArchive(t *testing.T) {
+	t.Parallel()
+	lfs, _ := lfsTestPointer("large file")
+	files := []testArchiveEntry{
+		{name: "README.md", data: "readme\n"},
+		{name: "src/main.sh", data: "#!/bin/sh\n", mode: 0o755},
+		{name: "src/sub/data.txt", data: "data\n"},
+		{name: "src/link", link: "main.sh"},
+	}
+	archives := map[string][]byte{
+		"repo":       newTestArchive(t, files),
+		"subst":      newTestArchive(t, append([]testArchiveEntry{{name: ".gitattributes", data: "VERSION export-subst\n"}}, files...)),
+		"ignore":     newTestArchive(t, append([]testArchiveEntry{{name: ".gitattributes", data: "/tests export-ignore\n"}}, files...)),
+		"lfs":        newTestArchive(t, []testArchiveEntry{{name: "large.bin", data: string(lfs)}}),
+		"not-gzip":   []byte("not an archive"),
+		"empty-repo": newTestArchive(t, nil),
+		"hidden":     newTestArchive(t, files),
+		"nested":     newTestArchive(t, files),
+	}
+	// The .gitattributes files in the trees served by the API. Those of
+	// the hidden and nested repositories drop themselves from the archive.
+	attributes := map[string]map[string]string{
+		"subst":  {".gitattributes": "VERSION export-subst\n"},
+		"ignore": {".gitattributes": "/tests export-ignore\n"},
+		"hidden": {".gitattributes": "/.gitattributes export-ignore\n"},
+		"nested": {"src/sub/.gitattributes": "* export-ignore\n"},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/org/{repo}/archive/{file}", func(w http.ResponseWriter, r *http.Request) {
+		data, ok := archives[r.PathValue("repo")]
+		if !ok || r.PathValue("file") != testCommit+".tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data) //nolint:errcheck,gosec
+	})
+	mux.HandleFunc("GET /api/v1/repos/org/{repo}/git/trees/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := archives[r.PathValue("repo")]; !ok || r.PathValue("sha") != testCommit {
+			http.NotFound(w, r)
+			return
+		}
+		// Trees are listed in two pages, the attributes in the second
+		tree := gitTreeResponse{Tree: []gitTreeEntry{{Path: "README.md", Type: "blob"}}, Truncated: true}
+		if r.URL.Query().Get("page") != "1" {
+			tree = gitTreeResponse{Tree: []gitTreeEntry{{Path: "src", Type: "tree"}}}
+			for p := range attributes[r.PathValue("repo")] {
+				tree.Tree = append(tree.Tree, gitTreeEntry{Path: p, Type: "blob"})
+			}
+		}
+		json.NewEncoder(w).Encode(&tree) //nolint:errcheck,gosec
+	})
+	mux.HandleFunc("GET /api/v1/repos/org/{repo}/raw/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		data, ok := attributes[r.PathValue("repo")][r.PathValue("path")]
+		if !ok || r.URL.Query().Get("ref") != testCommit {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(data)) //nolint:errcheck,gosec
+	})
+	srv := newForgeTestServer(t, mux)
+	provider := []fnOpt{WithGiteaHosts("example.com")}
+
+	locator := func(repo, subpath string) string {
+		return "git+https://example.com/org/" + repo + "@" + testCommit + "#" + subpath
+	}
+
+	srv.run(t, downloadForgeTree, provider, []forgeTest{
+		{"repo", locator("repo", ""), nil, map[string]string{
+			"README.md": "readme\n", "src/main.sh": "#!/bin/sh\n", "src/sub/data.txt": "data\n",
+		}, false},
+		{"subpath", locator("repo", "src/sub"), nil, map[string]string{"src/sub/data.txt": "data\n"}, false},
+		{"file", locator("repo", "README.md"), nil, map[string]string{"README.md": "readme\n"}, false},
+		{"include", locator("repo", "src"), []fnOpt{WithInclude("*.sh")}, map[string]string{"src/main.sh": "#!/bin/sh\n"}, false},
+		{"export-ignore", locator("ignore", "README.md"), []fnOpt{WithExportIgnore(true)}, map[string]string{"README.md": "readme\n"}, false},
+		{"export-ignore-off", locator("ignore", "README.md"), nil, nil, true},
+		{"export-subst", locator("subst", "README.md"), nil, nil, true},
+		{"hidden-attributes", locator("hidden", "README.md"), nil, nil, true},
+		{"nested-attributes", locator("nested", "README.md"), nil, nil, true},
+		{"hidden-attributes-export-ignore", locator("hidden", "README.md"), []fnOpt{WithExportIgnore(true)}, map[string]string{"README.md": "readme\n"}, false},
+		{"symlinks", locator("repo", "src"), []fnOpt{WithSymlinks(SymlinksPreserve)}, nil, true},
+		{"lfs", locator("lfs", ""), nil, nil, true},
+		{"lfs-off", locator("lfs", ""), []fnOpt{WithLFS(false)}, map[string]string{"large.bin": string(lfs)}, false},
+		{"size-limit", locator("repo", ""), []fnOpt{WithMaxFileSize(8)}, nil, true},
+		{"missing-subpath", locator("repo", "missing"), nil, nil, true},
+		{"not-gzip", locator("not-gzip", ""), nil, nil, true},
+		{"not-found", locator("missing", ""), nil, nil, true},
+	})
+
+	t.Run("manifests", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		dest := filepath.Join(dir, "out")
+		checksumsPath := filepath.Join(dir, "checksums.json")
+		require.NoError(t, Download(
+			locator("repo", "src"), dest,
+			append(append([]fnOpt{WithHttpClient(srv.client)}, provider...),
+				WithChecksumManifest(checksumsPath), WithOmniBORManifest(filepath.Join(dir, "omnibor")))...,
+		))
+
+		info, err := os.Stat(filepath.Join(dest, "src", "main.sh"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+		data, err := os.ReadFile(checksumsPath)
+		require.NoError(t, err)
+		manifest := &ChecksumManifest{}
+		require.NoError(t, json.Unmarshal(data, manifest))
+		require.Equal(t, testCommit, manifest.Commit)
+		require.Len(t, manifest.Files, 2)
+
+		data, err = os.ReadFile(filepath.Join(dir, "omnibor"))
+		require.NoError(t, err)
+		require.Contains(t, string(data), gitOIDFor(t, "data\n"))
+	})
+}
+
+// downloadForgeTree is the forgeFetch downloading a tree, returning the
+// data of the files written. Failed downloads leave nothing behind.
+func downloadForgeTree(t *testing.T, locator string, funcs ...fnOpt) (any, error) {
+	t.Helper()
+	dest := filepath.Join(t.TempDir(), "out")
+	if err := Download(locator, dest, funcs...); err != nil {
+		require.NoDirExists(t, dest)
+		return nil, err
+	}
+	got := map[string]string{}
+	require.NoError(t, filepath.WalkDir(dest, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		rel, _ := filepath.Rel(dest, p) //nolint:errcheck
+		got[filepath.ToSlash(rel)] = string(data)
+		return err
+	}))
+	return got, nil
+}
+
+// gitOIDFor returns the SHA-256 gitoid of a blob
+func gitOIDFor(t *testing.T, data string) string {
+	t.Helper()
+	h := newGitOIDHasher(int64(len(data)))
+	h.Write([]byte(data)) //nolint:errcheck,gosec
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestUseForgeArchive(t *testing.T) {
+	t.Parallel()
+	pinned := "git+https://github.com/org/repo@" + testCommit
+	for _, tc := range []struct {
+		name    string
+		locator string
+		funcs   []fnOpt
+		expect  bool
+	}{
+		{"pinned", pinned, nil, true},
+		{"subpath", pinned + "#src", nil, true},
+		{"branch", "git+https://github.com/org/repo@main", nil, false},
+		{"keep-git-dir", pinned, []fnOpt{WithKeepGitDir(true)}, false},
+		{"submodules", pinned, []fnOpt{WithSubmodules(true, 0)}, false},
+		{"disabled", pinned, []fnOpt{WithForgeAPI(false)}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			opts := defaultOptions
+			for _, fn := range tc.funcs {
+				require.NoError(t, fn(&opts))
+			}
+			c, err := Locator(tc.locator).Parse(tc.funcs...)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, useForgeArchive(c, &opts))
+		})
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/org/{repo}/git/trees/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("sha") != testCommit || r.URL.Query().Get("recursive") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(&gitTreeResponse{ //nolint:errcheck,gosec
+			Tree:      []gitTreeEntry{{Path: "a.txt", Type: "blob"}, {Path: "sub", Type: "tree"}, {Path: "sub/.gitattributes", Type: "blob"}},
+			Truncated: r.PathValue("repo") == "large",
+		})
+	})
+	mux.HandleFunc("GET /api/v4/projects/org%2Frepo/repository/tree", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != testCommit {
+			http.NotFound(w, r)
+			return
+		}
+		entries := []gitTreeEntry{{Path: "a.txt", Type: "blob"}, {Path: "sub", Type: "tree"}}
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+		} else {
+			entries = []gitTreeEntry{{Path: "sub/.gitattributes", Type: "blob"}}
+		}
+		json.NewEncoder(w).Encode(entries) //nolint:errcheck,gosec
+	})
+	srv := newForgeTestServer(t, mux)
+
+	for _, tc := range []struct {
+		name     string
+		provider TreeProvider
+		repo     string
+		mustErr  bool
+	}{
+		{"github", GitHubProvider{}, "repo", false},
+		{"github-truncated", GitHubProvider{}, "large", true},
+		{"gitlab-pages", GitLabProvider{}, "repo", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := Locator("git+https://example.com/org/" + tc.repo + "@" + testCommit).Parse()
+			require.NoError(t, err)
+			files, err := tc.provider.ListFiles(srv.client, nil, c)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"a.txt", "sub/.gitattributes"}, files)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenFS(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		"README.md":         "readme\n",
+		"docs/index.md":     "index\n",
+		"docs/guide/use.md": "use\n",
+	})
+
+	for _, tc := range []struct {
+		name     string
+		fragment string
+		expected []string
+		mustErr  bool
+	}{
+		{"subdir", "docs", []string{"guide/use.md", "index.md"}, false},
+		{"root", "", []string{"README.md", "docs/guide/use.md", "docs/index.md"}, false},
+		{"file", "README.md", nil, true},
+		{"missing", "nope", nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fsys, err := OpenFS(fileLocator(repoDir, commit, tc.fragment))
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			files := []string{}
+			require.NoError(t, fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() && d.Name() == ".git" {
+					return fs.SkipDir
+				}
+				if !d.IsDir() {
+					files = append(files, p)
+				}
+				return nil
+			}))
+			require.Equal(t, tc.expected, files)
+		})
+	}
+}
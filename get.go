@@ -5,16 +5,21 @@ package vcslocator
 
 import (
 	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/go-git/go-billy/v5/helper/iofs"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/nozzle/throttler"
 )
 
@@ -30,22 +35,75 @@ func (el *ErrorList) Error() string {
 	return ""
 }
 
+// Unwrap returns the errors in the list so they can be inspected with
+// errors.Is and errors.As
+func (el *ErrorList) Unwrap() []error {
+	return el.Errors
+}
+
 type copyPlan struct {
 	Locator    Locator
-	FS         fs.FS
+	Cloned     *clonedRepo
 	Components *Components
 	Files      map[int]string
+
+	// forge is set when the files are fetched through the forge API, the
+	// repository is only cloned if a request fails
+	forge     bool
+	cloneOnce sync.Once
+	cloneErr  error
+
+	// breaker skips the repositories of hosts failing repeatedly
+	breaker *hostBreaker
+}
+
+// clone clones the repository of the plan, files are read from the object
+// store so the checkout is skipped
+func (p *copyPlan) clone(opts options, funcs ...fnOpt) error {
+	if err := p.breaker.check(p.Components.Hostname); err != nil {
+		return fmt.Errorf("reading %q: %w", p.Locator, err)
+	}
+	opts.noCheckout = true
+	cloned, err := cloneRepo(p.Locator, &opts, funcs...)
+	p.breaker.record(p.Components.Hostname, err)
+	if err != nil {
+		return fmt.Errorf("reading %q: %w", p.Locator, err)
+	}
+	p.Cloned = cloned
+	return nil
+}
+
+// open opens a file of the plan. When fetching from the forge API fails,
+// the repository is cloned to read the file from it.
+func (p *copyPlan) open(path string, opts options, funcs ...fnOpt) (io.ReadCloser, error) {
+	if p.forge {
+		if err := p.breaker.check(p.Components.Hostname); err != nil {
+			return nil, err
+		}
+		c := *p.Components
+		c.SubPath = path
+		f, err := openForgeFile(p.Locator, &c, &opts, funcs...)
+		if err == nil {
+			p.breaker.record(p.Components.Hostname, nil)
+			return f, nil
+		}
+		p.cloneOnce.Do(func() { p.cloneErr = p.clone(opts, funcs...) })
+		if p.cloneErr != nil {
+			return nil, p.cloneErr
+		}
+	}
+	return openRepoFile(p.Cloned, path)
 }
 
 // GetGroup gets the data of several vcs locators in an efficient manner
-func GetGroup[T ~string](locators []T) ([][]byte, error) {
+func GetGroup[T ~string](locators []T, funcs ...fnOpt) ([][]byte, error) {
 	buffers := make([]io.Writer, len(locators))
 	for i := range locators {
 		var b bytes.Buffer
 		buffers[i] = &b
 	}
 
-	if err := CopyFileGroup(locators, buffers); err != nil {
+	if err := CopyFileGroup(locators, buffers, funcs...); err != nil {
 		return nil, err
 	}
 
@@ -60,20 +118,35 @@ func GetGroup[T ~string](locators []T) ([][]byte, error) {
 	return ret, nil
 }
 
-// CopyFileGroup copies a group of locators to the specified writers
+// CopyFileGroup copies a group of locators to the specified writers. When
+// a host fails repeatedly, the rest of its locators are skipped with a
+// HostUnavailableError (see WithCircuitBreaker).
 func CopyFileGroup[T ~string](locators []T, writers []io.Writer, funcs ...fnOpt) error {
 	if len(locators) != len(writers) {
 		return fmt.Errorf("number of writers does not match the number of VCS locators")
 	}
 
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return err
+		}
+	}
+	if len(opts.ExpectedDigests) != 0 && len(opts.ExpectedDigests) != len(locators) {
+		return fmt.Errorf("number of expected digests does not match the number of VCS locators")
+	}
+
 	// First, create the clone plan
 	cloneList := map[string]*copyPlan{}
+	breaker := newHostBreaker(opts.BreakerThreshold)
+	lineRanges := make([][2]int, len(locators))
 	for i, l := range locators {
 		// Parse the locator
-		components, err := Locator(l).Parse()
+		components, err := Locator(l).Parse(funcs...)
 		if err != nil {
-			return fmt.Errorf("error parsing locator %d", i)
+			return fmt.Errorf("parsing locator %d: %w", i, err)
 		}
+		lineRanges[i] = [2]int{components.LineStart, components.LineEnd}
 
 		repostring := fmt.Sprintf("%s:%s", components.RepoURL(), components.RefString)
 		if _, ok := cloneList[repostring]; !ok {
@@ -81,25 +154,33 @@ func CopyFileGroup[T ~string](locators []T, writers []io.Writer, funcs ...fnOpt)
 				Locator:    Locator(l),
 				Components: components,
 				Files:      map[int]string{},
+				forge:      useForgeAPI(components, &opts),
+				breaker:    breaker,
 			}
 		}
 		cloneList[repostring].Files[i] = components.SubPath
 	}
 
 	// Clone them repos
-	var mutex sync.Mutex
-	t := throttler.New(4, len(cloneList))
-	for repostring, copyplan := range cloneList {
-		go func(repostring string, copyplan *copyPlan) {
-			fsobj, err := CloneRepository(copyplan.Locator, funcs...)
-			mutex.Lock()
-			cloneList[repostring].FS = fsobj
-			mutex.Unlock()
-			if err != nil {
-				err = fmt.Errorf("reading %q: %w", copyplan.Locator, err)
+	defer func() {
+		for _, copyplan := range cloneList {
+			if copyplan.Cloned != nil {
+				copyplan.Cloned.Close() //nolint:errcheck,gosec
 			}
-			t.Done(err)
-		}(repostring, copyplan)
+		}
+	}()
+	// Plans fetched from the forge API are only cloned if a request fails
+	toClone := []*copyPlan{}
+	for _, copyplan := range cloneList {
+		if !copyplan.forge {
+			toClone = append(toClone, copyplan)
+		}
+	}
+	t := throttler.New(4, len(toClone))
+	for _, copyplan := range toClone {
+		go func(copyplan *copyPlan) {
+			t.Done(copyplan.clone(opts, funcs...))
+		}(copyplan)
 		t.Throttle()
 	}
 
@@ -114,7 +195,7 @@ func CopyFileGroup[T ~string](locators []T, writers []io.Writer, funcs ...fnOpt)
 	for _, copyplan := range cloneList {
 		for i, path := range copyplan.Files {
 			go func(i int, path string, copyplan *copyPlan) {
-				f, err := copyplan.FS.Open(path)
+				f, err := copyplan.open(path, opts, funcs...)
 				if err != nil {
 					emtx.Lock()
 					errs[i] = fmt.Errorf("opening path %d (%q): %w", i, path, err)
@@ -123,12 +204,24 @@ func CopyFileGroup[T ~string](locators []T, writers []io.Writer, funcs ...fnOpt)
 					return
 				}
 				defer f.Close() //nolint:errcheck
-				if _, err := io.Copy(writers[i], f); err != nil {
+
+				digest := ""
+				if len(opts.ExpectedDigests) != 0 {
+					digest = opts.ExpectedDigests[i]
+				}
+				dw, err := newDigestWriter(writers[i], digest)
+				if err == nil {
+					lw := newLineRangeWriter(dw, lineRanges[i][0], lineRanges[i][1])
+					if _, err = io.Copy(lw, f); err != nil {
+						err = fmt.Errorf("copying data stream %d: %w", i, err)
+					} else if err = verifyLineRange(lw); err == nil {
+						err = dw.verify(string(locators[i]))
+					}
+				}
+				if err != nil {
 					emtx.Lock()
-					errs[i] = fmt.Errorf("copying data stream %d: %w", i, err)
+					errs[i] = err
 					emtx.Unlock()
-					t2.Done(nil)
-					return
 				}
 				t2.Done(nil)
 			}(i, path, copyplan)
@@ -152,6 +245,49 @@ func CopyFileGroup[T ~string](locators []T, writers []io.Writer, funcs ...fnOpt)
 	return nil
 }
 
+// GetReader returns a reader to stream the contents of the file specified
+// by the VCS locator. If an expected digest is set, it is checked when the
+// reader reaches the end of the file. The caller must close the reader.
+func GetReader[T ~string](locator T, funcs ...fnOpt) (io.ReadCloser, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	l := Locator(locator)
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing locator: %w", err)
+	}
+	if components.SubPath == "" {
+		return nil, errors.New("locator has no subpath defined")
+	}
+
+	if len(opts.ExpectedDigests) > 1 {
+		return nil, errors.New("only one expected digest can be checked when reading a file")
+	}
+	digest := ""
+	if len(opts.ExpectedDigests) == 1 {
+		digest = opts.ExpectedDigests[0]
+	}
+
+	f, err := openLocatorFile(l, components, &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newDigestReader(
+		newLineRangeReader(f, components.LineStart, components.LineEnd),
+		digest, string(locator),
+	)
+	if err != nil {
+		f.Close() //nolint:errcheck,gosec
+		return nil, err
+	}
+	return r, nil
+}
+
 // CopyFile downloads a file specified by the VCS locator and copies it
 // to an io.Writer.
 func CopyFile[T ~string](locator T, w io.Writer, funcs ...fnOpt) error {
@@ -171,22 +307,66 @@ func CopyFile[T ~string](locator T, w io.Writer, funcs ...fnOpt) error {
 		return errors.New("locator has no subpath defined")
 	}
 
-	fsobj, err := CloneRepository(locator, funcs...)
+	if len(opts.ExpectedDigests) > 1 {
+		return errors.New("only one expected digest can be checked when copying a file")
+	}
+	digest := ""
+	if len(opts.ExpectedDigests) == 1 {
+		digest = opts.ExpectedDigests[0]
+	}
+	dw, err := newDigestWriter(w, digest)
 	if err != nil {
-		return fmt.Errorf("cloning repository: %w", err)
+		return err
 	}
 
-	f, err := fsobj.Open(components.SubPath)
+	f, err := openLocatorFile(l, components, &opts, funcs...)
 	if err != nil {
-		return fmt.Errorf("opening file: %w", err)
+		return err
 	}
-	if _, err := io.Copy(w, f); err != nil {
+	defer f.Close() //nolint:errcheck
+	lw := newLineRangeWriter(dw, components.LineStart, components.LineEnd)
+	if _, err := io.Copy(lw, f); err != nil {
 		return fmt.Errorf("copying data stream: %w", err)
 	}
-	return nil
+	if err := verifyLineRange(lw); err != nil {
+		return err
+	}
+	return dw.verify(string(locator))
 }
 
-// Download copies data from the git repository to the specified directory
+// openLocatorFile opens the file in the subpath of a locator. Files at
+// pinned commits are fetched through the forge API when possible, otherwise
+// the repository is cloned and released when the reader is closed.
+func openLocatorFile(l Locator, components *Components, opts *options, funcs ...fnOpt) (io.ReadCloser, error) {
+	if useForgeAPI(components, opts) {
+		f, err := openForgeFile(l, components, opts, funcs...)
+		if err == nil {
+			return f, nil
+		}
+	}
+
+	// Files are read from the object store, skip the checkout
+	opts.noCheckout = true
+	cloned, err := cloneRepo(l, opts, funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("cloning repository: %w", err)
+	}
+
+	f, err := openRepoFile(cloned, components.SubPath)
+	if err != nil {
+		cloned.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	return &closingReader{ReadCloser: f, closer: cloned}, nil
+}
+
+// Download copies data from the git repository to the specified directory.
+// Only the locator subpath is copied, if the locator has no subpath (or it
+// is "/") the whole repository tree is downloaded.
+//
+// Locators pinned to a commit of a known forge are extracted from the
+// archive of the commit served by the forge (see WithForgeAPI), the
+// repository is cloned when the archive cannot be used.
 func Download[T ~string](locator T, localDir string, funcs ...fnOpt) error {
 	opts := defaultOptions
 	for _, fn := range funcs {
@@ -201,53 +381,343 @@ func Download[T ~string](locator T, localDir string, funcs ...fnOpt) error {
 	if err != nil {
 		return fmt.Errorf("parsing locator: %w", err)
 	}
-	if components.SubPath == "" {
-		return errors.New("locator has no subpath defined")
+
+	// Files are written to a staging directory and only moved to localDir
+	// once all of them were fetched.
+	root, staging, err := newStagingDir(localDir)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging) //nolint:errcheck
+
+	if opts.KeepGitDir {
+		if st, ok := openPreviousDownload(root, components.fetchURL()); ok {
+			opts.refreshStorer = st
+		}
+	}
+
+	target := &downloadTarget{root: root, staging: staging, written: map[string]struct{}{}}
+	if opts.OmniBORManifestPath != "" {
+		target.manifest = &OmniBORManifest{Inputs: []string{}}
+	}
+
+	commit := ""
+	if useForgeArchive(components, &opts) {
+		if err := downloadForgeArchive(l, components, &opts, target, funcs...); err == nil {
+			commit = components.Commit
+		} else if err := target.reset(); err != nil {
+			return err
+		}
 	}
 
-	fsys, err := CloneRepository(locator, funcs...)
+	var cloned *clonedRepo
+	if commit == "" {
+		if cloned, err = downloadClone(l, components, &opts, target, funcs...); err != nil {
+			return err
+		}
+		defer cloned.Close() //nolint:errcheck
+		commit = cloned.Commit
+	}
+
+	if err := commitStagingDir(staging, root); err != nil {
+		return err
+	}
+
+	if opts.Sync {
+		subpath := strings.Trim(components.SubPath, "/")
+		subtree := subpath
+		if windowsPaths && subpath != "" {
+			if subtree, err = windowsSafePath(subpath, opts.IllegalNames); err != nil {
+				return err
+			}
+		}
+		// An empty subtree after the check means the subpath was skipped
+		if subpath == "" || subtree != "" {
+			if err := removeStaleFiles(root, filepath.FromSlash(subtree), target.written); err != nil {
+				return fmt.Errorf("removing stale files: %w", err)
+			}
+		}
+	}
+
+	// Archives are not used when keeping the git directory
+	if opts.KeepGitDir {
+		if err := writeGitDir(cloned.Repo, cloned.Commit, root); err != nil {
+			return fmt.Errorf("writing git directory: %w", err)
+		}
+	}
+
+	// The checksums are computed from the files on disk, including the
+	// ones that were already up to date.
+	if opts.ChecksumManifestPath != "" {
+		paths := slices.Sorted(maps.Keys(target.written))
+		checksums, err := newChecksumManifest(root, paths, string(locator), commit)
+		if err != nil {
+			return fmt.Errorf("computing checksums: %w", err)
+		}
+		data, err := json.MarshalIndent(checksums, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling checksum manifest: %w", err)
+		}
+		if err := os.WriteFile(opts.ChecksumManifestPath, data, 0o644); err != nil { //nolint:gosec // Manifests are public
+			return fmt.Errorf("writing checksum manifest: %w", err)
+		}
+	}
+
+	if target.manifest != nil {
+		if err := os.WriteFile(opts.OmniBORManifestPath, target.manifest.Bytes(), 0o644); err != nil { //nolint:gosec // Manifests are public
+			return fmt.Errorf("writing OmniBOR manifest: %w", err)
+		}
+	}
+	return nil
+}
+
+// downloadTarget is where Download writes the files
+type downloadTarget struct {
+	// root is the download directory, files are written to the staging
+	// directory and moved to root once all of them were fetched
+	root    string
+	staging string
+
+	// written records the destination paths of the files in the download
+	written map[string]struct{}
+
+	// manifest collects the gitoids of the files, when requested
+	manifest *OmniBORManifest
+}
+
+// reset removes the files written to the staging directory
+func (t *downloadTarget) reset() error {
+	if err := os.RemoveAll(t.staging); err != nil {
+		return fmt.Errorf("cleaning staging directory: %w", err)
+	}
+	if err := os.Mkdir(t.staging, os.FileMode(0o755)); err != nil {
+		return fmt.Errorf("creating staging directory: %w", err)
+	}
+	clear(t.written)
+	if t.manifest != nil {
+		t.manifest.Inputs = []string{}
+	}
+	return nil
+}
+
+// writeFile writes the data of a file of size bytes to the destination
+// path, recording its gitoid in the manifest
+func (t *downloadTarget) writeFile(dest string, perm os.FileMode, src io.Reader, size int64) error {
+	dst, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("opening destination file: %w", err)
+	}
+	defer dst.Close() //nolint:errcheck
+
+	// Set the mode explicitly, the umask would otherwise change it
+	if err := dst.Chmod(perm); err != nil {
+		return fmt.Errorf("setting file mode: %w", err)
+	}
+
+	if t.manifest == nil {
+		if _, err := io.Copy(dst, src); err != nil {
+			return fmt.Errorf("copying data stream: %w", err)
+		}
+		return nil
+	}
+
+	h := newGitOIDHasher(size)
+	if _, err := io.Copy(io.MultiWriter(dst, h), src); err != nil {
+		return fmt.Errorf("copying data stream: %w", err)
+	}
+	t.manifest.Inputs = append(t.manifest.Inputs, hex.EncodeToString(h.Sum(nil)))
+	return nil
+}
+
+// subpathRel returns the path of a repository file relative to the subpath
+// of a download (the file name when the subpath is the file itself). It
+// returns false if the file is not in the subpath.
+func subpathRel(path, subpath string) (string, bool) {
+	switch {
+	case subpath == "":
+		return path, true
+	case path == subpath:
+		return path[strings.LastIndex(path, "/")+1:], true
+	case strings.HasPrefix(path, subpath+"/"):
+		return strings.TrimPrefix(path, subpath+"/"), true
+	default:
+		return "", false
+	}
+}
+
+// downloadClone clones the repository of a locator and writes the files in
+// its subpath to the download target. The caller must close the returned
+// repository.
+func downloadClone(l Locator, components *Components, opts *options, target *downloadTarget, funcs ...fnOpt) (*clonedRepo, error) {
+	cloned, err := cloneRepo(l, opts, funcs...)
 	if err != nil {
-		return fmt.Errorf("cloning repository: %w", err)
+		return nil, fmt.Errorf("cloning repository: %w", err)
+	}
+	if err := writeClonedFiles(cloned, components, opts, target); err != nil {
+		cloned.Close() //nolint:errcheck,gosec
+		return nil, err
+	}
+	return cloned, nil
+}
+
+// writeClonedFiles writes the files in the subpath of a cloned repository
+// to the download target
+func writeClonedFiles(cloned *clonedRepo, components *Components, opts *options, target *downloadTarget) error {
+	fsys := iofs.New(cloned.FS)
+
+	// File modes are read from the commit tree as the checkout filesystem
+	// does not necessarily record them.
+	tree, err := cloned.tree()
+	if err != nil {
+		return err
+	}
+
+	var lfs *lfsClient
+	if opts.LFS {
+		if lfs, err = newLFSClient(cloned, tree.Tree); err != nil {
+			return err
+		}
+	}
+
+	// copyFile copies a file from the repository to the destination path
+	copyFile := func(path, dest string) error {
+		f, err := fsys.Open(path)
+		if err != nil {
+			return fmt.Errorf("opening file from source: %w", err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close() //nolint:errcheck,gosec
+			return fmt.Errorf("reading file info: %w", err)
+		}
+		size := info.Size()
+
+		// Files stored in LFS are written with the contents of the object
+		var src io.ReadCloser = f
+		if lfs != nil {
+			var pointer *lfsPointer
+			if src, pointer, err = lfs.smudge(f); err != nil {
+				return fmt.Errorf("reading %q from LFS: %w", path, err)
+			}
+			if pointer != nil {
+				size = pointer.Size
+			}
+		}
+		defer src.Close() //nolint:errcheck
+
+		perm, err := treeFilePerm(tree, path)
+		if err != nil {
+			return err
+		}
+		return target.writeFile(dest, perm, src, size)
 	}
 
+	var ignore *exportIgnoreMatcher
+	if opts.ExportIgnore {
+		if ignore, err = newExportIgnoreMatcher(cloned.FS); err != nil {
+			return err
+		}
+	}
+
+	subpath := strings.Trim(components.SubPath, "/")
+
 	// Walk the filesystem to fetch all we need
-	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 
 		if d.IsDir() {
 			return nil
 		}
 
-		if !strings.HasPrefix(path, strings.TrimPrefix(components.SubPath, "/")) {
+		// Filters match the path relative to the subpath
+		rel, ok := subpathRel(path, subpath)
+		if !ok || !matchFilters(rel, opts.Include, opts.Exclude) || ignore.ignored(path) {
 			return nil
 		}
 
-		// We know all paths are files here, so we create the dir and copy
-		src, err := fsys.Open(path)
-		if err != nil {
-			return fmt.Errorf("opening file from source: %w", err)
+		isLink := d.Type()&fs.ModeSymlink != 0
+		if isLink && opts.Symlinks == SymlinksSkip {
+			return nil
 		}
-		defer src.Close() //nolint:errcheck
+		if isLink && opts.Symlinks == SymlinksReject {
+			return fmt.Errorf("%q is a symbolic link", path)
+		}
+
+		// Long destination paths are handled by the os package, but names
+		// windows cannot represent are checked before writing.
+		destPath := path
+		if windowsPaths {
+			destPath, err = windowsSafePath(path, opts.IllegalNames)
+			if err != nil {
+				return err
+			}
+			if destPath == "" {
+				return nil
+			}
+		}
+
+		target.written[filepath.FromSlash(destPath)] = struct{}{}
 
-		destDir := filepath.Join(localDir, filepath.Dir(path))
-		if err := os.MkdirAll(destDir, os.FileMode(0o755)); err != nil {
-			return fmt.Errorf("creating destination dir: %w", err)
+		// Files already in the destination are not written again
+		if !isLink {
+			entry, err := tree.FindEntry(path)
+			if err != nil {
+				return fmt.Errorf("looking up %q in tree: %w", path, err)
+			}
+			if oid, ok := unchangedFile(target.root, destPath, entry.Hash, entryPerm(entry.Mode)); ok {
+				if target.manifest != nil {
+					target.manifest.Inputs = append(target.manifest.Inputs, oid)
+				}
+				return nil
+			}
 		}
 
-		dst, err := os.Create(filepath.Join(localDir, path))
+		dest, err := prepareDestination(target.staging, destPath)
 		if err != nil {
-			return fmt.Errorf("opening destination file: %w", err)
+			return err
 		}
-		defer dst.Close() //nolint:errcheck
+		opts.progressf("writing %s\n", destPath)
 
-		if _, err := io.Copy(dst, src); err != nil {
-			return fmt.Errorf("copying data stream: %w", err)
+		if !isLink {
+			return copyFile(path, dest)
 		}
-		return nil
-	}); err != nil {
-		return err
+
+		if opts.Symlinks == SymlinksPreserve {
+			return preserveSymlink(cloned.FS, target.staging, path, dest)
+		}
+
+		// Resolve the link and copy the target file
+		linkTarget, err := resolveRepoSymlink(cloned.FS, path)
+		if err != nil {
+			return err
+		}
+		info, err := cloned.FS.Stat(linkTarget)
+		if err != nil {
+			return fmt.Errorf("reading link target: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("symbolic link %q points to a directory", path)
+		}
+		return copyFile(linkTarget, dest)
+	})
+}
+
+// treeFilePerm returns the permissions to write a file from the tree with:
+// 0o755 for executables and 0o644 for all other files.
+func treeFilePerm(tree *repoTree, path string) (os.FileMode, error) {
+	entry, err := tree.FindEntry(path)
+	if err != nil {
+		return 0, fmt.Errorf("looking up %q in tree: %w", path, err)
 	}
-	return nil
+	return entryPerm(entry.Mode), nil
+}
+
+// entryPerm returns the permissions of files with a tree entry mode
+func entryPerm(mode filemode.FileMode) os.FileMode {
+	if mode == filemode.Executable {
+		return os.FileMode(0o755)
+	}
+	return os.FileMode(0o644)
 }
@@ -6,8 +6,11 @@ package vcslocator
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -91,6 +94,15 @@ func TestCopyFile(t *testing.T) {
 		require.Equal(t, "# Guide\nSome content.", buf.String())
 	})
 
+	t.Run("copies using an abbreviated commit", func(t *testing.T) {
+		t.Parallel()
+		locator := fileLocator(repoDir, commitHash[:10], "hello.txt")
+		var buf bytes.Buffer
+		err := CopyFile(locator, &buf, noAuth)
+		require.NoError(t, err)
+		require.Equal(t, "hello world", buf.String())
+	})
+
 	t.Run("errors when no subpath", func(t *testing.T) {
 		t.Parallel()
 		locator := fileLocator(repoDir, commitHash, "")
@@ -117,6 +129,60 @@ func TestCopyFile(t *testing.T) {
 	})
 }
 
+func TestGetReader(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"hello.txt":     "hello world",
+		"docs/guide.md": "# Guide\n",
+	})
+
+	t.Run("streams a file", func(t *testing.T) {
+		t.Parallel()
+		r, err := GetReader(fileLocator(repoDir, commitHash, "docs/guide.md"), noAuth)
+		require.NoError(t, err)
+		defer r.Close() //nolint:errcheck
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "# Guide\n", string(data))
+	})
+
+	t.Run("verifies the digest", func(t *testing.T) {
+		t.Parallel()
+		r, err := GetReader(
+			fileLocator(repoDir, commitHash, "hello.txt"), noAuth,
+			WithExpectedDigest(sha256Digest("hello world")),
+		)
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+
+		r, err = GetReader(
+			fileLocator(repoDir, commitHash, "hello.txt"), noAuth,
+			WithExpectedDigest(sha256Digest("bye world")),
+		)
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		var mismatch *DigestMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		require.NoError(t, r.Close())
+	})
+
+	t.Run("errors when no subpath", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetReader(fileLocator(repoDir, commitHash, ""), noAuth)
+		require.Error(t, err)
+	})
+
+	t.Run("errors when file does not exist", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetReader(fileLocator(repoDir, commitHash, "nonexistent.txt"), noAuth)
+		require.Error(t, err)
+	})
+}
+
 func TestDownload(t *testing.T) {
 	t.Parallel()
 
@@ -174,13 +240,47 @@ func TestDownload(t *testing.T) {
 		require.Equal(t, "package util\n", string(utils))
 	})
 
-	t.Run("errors when no subpath", func(t *testing.T) {
+	t.Run("preserves executable bits", func(t *testing.T) {
 		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("file modes are not supported on windows")
+		}
+
+		execDir, _ := initTestRepoWithFiles(t, map[string]string{"bin/README": "scripts"})
+		script := filepath.Join(execDir, "bin", "run.sh")
+		require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0o700)) //nolint:gosec
+		execCommit := commitTestFile(t, execDir, "bin/run.sh", "#!/bin/sh\n")
+
 		destDir := t.TempDir()
-		locator := fileLocator(repoDir, commitHash, "")
-		err := Download(locator, destDir, noAuth)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "no subpath defined")
+		// Existing files get their mode fixed too
+		require.NoError(t, os.MkdirAll(filepath.Join(destDir, "bin"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(destDir, "bin", "README"), []byte("old"), 0o600))
+
+		err := Download(fileLocator(execDir, execCommit, "bin"), destDir, noAuth)
+		require.NoError(t, err)
+
+		info, err := os.Stat(filepath.Join(destDir, "bin", "run.sh"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+		info, err = os.Stat(filepath.Join(destDir, "bin", "README"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+	})
+
+	t.Run("downloads the whole repository without subpath", func(t *testing.T) {
+		t.Parallel()
+		for _, fragment := range []string{"", "/"} {
+			destDir := t.TempDir()
+			locator := fileLocator(repoDir, commitHash, fragment)
+			err := Download(locator, destDir, noAuth)
+			require.NoError(t, err)
+
+			require.FileExists(t, filepath.Join(destDir, "hello.txt"))
+			require.FileExists(t, filepath.Join(destDir, "docs", "faq.md"))
+			require.FileExists(t, filepath.Join(destDir, "src", "util", "utils.go"))
+			require.NoDirExists(t, filepath.Join(destDir, ".git"))
+		}
 	})
 
 	t.Run("errors on invalid locator", func(t *testing.T) {
@@ -190,3 +290,21 @@ func TestDownload(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestDownloadProgress(t *testing.T) {
+	t.Parallel()
+
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"docs/guide.md": "# Guide",
+		"docs/faq.md":   "# FAQ",
+	})
+
+	var progress bytes.Buffer
+	require.NoError(t, Download(
+		fileLocator(repoDir, commitHash, "docs"), t.TempDir(),
+		WithSystemCredentials(false), WithProgress(&progress),
+	))
+	// The transfer progress sent by the remote goes in between
+	require.True(t, strings.HasPrefix(progress.String(), "fetching file://"+filepath.ToSlash(repoDir)+"\n"))
+	require.True(t, strings.HasSuffix(progress.String(), "writing docs/faq.md\nwriting docs/guide.md\n"))
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// GlobMatch is a file matched by the subpath pattern of a locator
+type GlobMatch struct {
+	// Path is the path of the file relative to the repository root
+	Path string
+
+	// Data holds the file contents
+	Data []byte
+}
+
+// GetGlob returns the paths and contents of the files matching the locator
+// subpath, which is interpreted as a glob pattern (ie .github/workflows/*.yml).
+// Pattern segments are matched with path.Match and ** matches any number of
+// directories. Matches are sorted by path, an empty list is not an error.
+func GetGlob[T ~string](locator T, funcs ...fnOpt) ([]GlobMatch, error) {
+	ret := []GlobMatch{}
+	if err := CopyGlob(locator, func(p string, r io.Reader) error {
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, r); err != nil {
+			return fmt.Errorf("reading %q: %w", p, err)
+		}
+		ret = append(ret, GlobMatch{Path: p, Data: buf.Bytes()})
+		return nil
+	}, funcs...); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// CopyGlob calls fn with the path and a reader of each file matching the
+// glob pattern in the locator subpath, in path order. Only regular files are
+// matched, symbolic links and submodules are skipped. Errors returned by fn
+// stop the iteration and are returned to the caller.
+func CopyGlob[T ~string](locator T, fn func(path string, r io.Reader) error, funcs ...fnOpt) error {
+	opts := defaultOptions
+	for _, f := range funcs {
+		if err := f(&opts); err != nil {
+			return err
+		}
+	}
+
+	l := Locator(locator)
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return fmt.Errorf("parsing locator: %w", err)
+	}
+	pattern := strings.Trim(components.SubPath, "/")
+	if pattern == "" {
+		return errors.New("locator has no subpath defined")
+	}
+	if err := validateGlob(pattern); err != nil {
+		return err
+	}
+
+	cloned, err := cloneRepo(l, &opts, funcs...)
+	if err != nil {
+		return fmt.Errorf("cloning repository: %w", err)
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return fmt.Errorf("reading commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("reading commit tree: %w", err)
+	}
+
+	matches := []archiveEntry{}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("walking tree: %w", err)
+		}
+		if entry.Mode == filemode.Dir || entry.Mode == filemode.Submodule || entry.Mode == filemode.Symlink {
+			continue
+		}
+		if matchGlob(pattern, name) {
+			matches = append(matches, archiveEntry{Path: name, Mode: entry.Mode, Hash: entry.Hash})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Path < matches[j].Path
+	})
+
+	for i := range matches {
+		if err := copyGlobMatch(cloned, &matches[i], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyGlobMatch opens the blob of a matched file and passes it to fn
+func copyGlobMatch(cloned *clonedRepo, entry *archiveEntry, fn func(string, io.Reader) error) error {
+	r, _, err := openBlob(cloned, entry)
+	if err != nil {
+		return err
+	}
+	defer r.Close() //nolint:errcheck
+	return fn(entry.Path, r)
+}
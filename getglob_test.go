@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGlob(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		".github/workflows/ci.yml":      "ci\n",
+		".github/workflows/release.yml": "release\n",
+		".github/workflows/README.md":   "readme\n",
+		".github/dependabot.yml":        "deps\n",
+		"docs/a/index.md":               "a\n",
+		"docs/b/c/index.md":             "c\n",
+	})
+
+	for _, tc := range []struct {
+		name     string
+		pattern  string
+		expected []GlobMatch
+		mustErr  bool
+	}{
+		{"star", ".github/workflows/*.yml", []GlobMatch{
+			{Path: ".github/workflows/ci.yml", Data: []byte("ci\n")},
+			{Path: ".github/workflows/release.yml", Data: []byte("release\n")},
+		}, false},
+		{"doublestar", "docs/**/index.md", []GlobMatch{
+			{Path: "docs/a/index.md", Data: []byte("a\n")},
+			{Path: "docs/b/c/index.md", Data: []byte("c\n")},
+		}, false},
+		{"literal", ".github/dependabot.yml", []GlobMatch{
+			{Path: ".github/dependabot.yml", Data: []byte("deps\n")},
+		}, false},
+		{"no-matches", "*.go", []GlobMatch{}, false},
+		{"no-subpath", "", nil, true},
+		{"invalid", "docs/[", nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			matches, err := GetGlob(fileLocator(repoDir, commit, tc.pattern))
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, matches)
+		})
+	}
+}
+
+func TestCopyGlobError(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		"a.txt": "a", "b.txt": "b",
+	})
+	errStop := errors.New("stop")
+	seen := []string{}
+	err := CopyGlob(fileLocator(repoDir, commit, "*.txt"), func(p string, _ io.Reader) error {
+		seen = append(seen, p)
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, []string{"a.txt"}, seen)
+}
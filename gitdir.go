@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// gitDirName is the name of the repository directory in a worktree
+const gitDirName = ".git"
+
+// openLocal opens the repository at path: a worktree, a bare repository or
+// a worktree with a .git file pointing to its git directory elsewhere, as
+// submodules and linked worktrees have. Linked worktrees read the objects,
+// references and configuration they share from the common directory.
+func openLocal(path string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+}
+
+// commonGitDir returns the git directory holding the objects shared by the
+// linked worktrees of gitDir, as recorded in its commondir file. Other git
+// directories are returned as they are.
+func commonGitDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(data))
+	if dir == "" {
+		return gitDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return dir
+}
+
+// writeGitDir turns root into a working clone of the repository by writing
+// its objects, references and remotes to root/.git. HEAD is detached at the
+// commit and the index is reset to it without touching the files in root.
+// If root already has a .git directory, it is updated.
+func writeGitDir(src *git.Repository, commit, root string) error {
+	st := filesystem.NewStorage(osfs.New(filepath.Join(root, gitDirName)), cache.NewObjectLRUDefault())
+	repo, err := git.Init(st, osfs.New(root))
+	if errors.Is(err, git.ErrRepositoryAlreadyExists) {
+		repo, err = git.Open(st, osfs.New(root))
+	}
+	if err != nil {
+		return fmt.Errorf("initializing git directory: %w", err)
+	}
+
+	objects, err := src.Storer.IterEncodedObjects(plumbing.AnyObject)
+	if err != nil {
+		return fmt.Errorf("listing objects: %w", err)
+	}
+	defer objects.Close()
+	if err := objects.ForEach(func(obj plumbing.EncodedObject) error {
+		if st.HasEncodedObject(obj.Hash()) == nil {
+			return nil
+		}
+		if _, err := st.SetEncodedObject(obj); err != nil {
+			return fmt.Errorf("writing object %s: %w", obj.Hash(), err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	refs, err := src.Storer.IterReferences()
+	if err != nil {
+		return fmt.Errorf("listing references: %w", err)
+	}
+	if err := refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name() == plumbing.HEAD {
+			return nil
+		}
+		return st.SetReference(ref)
+	}); err != nil {
+		return fmt.Errorf("writing references: %w", err)
+	}
+	if err := st.SetReference(plumbing.NewHashReference(plumbing.HEAD, plumbing.NewHash(commit))); err != nil {
+		return fmt.Errorf("writing HEAD: %w", err)
+	}
+
+	shallow, err := src.Storer.Shallow()
+	if err != nil {
+		return fmt.Errorf("reading shallow commits: %w", err)
+	}
+	if err := st.SetShallow(shallow); err != nil {
+		return fmt.Errorf("writing shallow commits: %w", err)
+	}
+
+	srcConfig, err := src.Config()
+	if err != nil {
+		return fmt.Errorf("reading repository config: %w", err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("reading repository config: %w", err)
+	}
+	cfg.Remotes = srcConfig.Remotes
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("writing repository config: %w", err)
+	}
+
+	// Objects borrowed from a reference repository stay in it
+	if ref := findReferenceStorer(src.Storer); ref != nil {
+		if err := ref.writeAlternates(st); err != nil {
+			return err
+		}
+		if repo, err = git.Open(&referenceStorer{Storer: st, reference: ref.reference}, osfs.New(root)); err != nil {
+			return fmt.Errorf("opening repository: %w", err)
+		}
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("opening worktree: %w", err)
+	}
+	if err := wt.Reset(&git.ResetOptions{
+		Commit: plumbing.NewHash(commit),
+		Mode:   git.MixedReset,
+	}); err != nil {
+		return fmt.Errorf("resetting index: %w", err)
+	}
+	return nil
+}
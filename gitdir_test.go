@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadKeepGitDir(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"hello.txt":   "hello",
+		"src/main.go": "package main\n",
+	})
+	tagTestRepo(t, repoDir, "v1.0.0", first, "release")
+	second := commitTestFile(t, repoDir, "hello.txt", "bye")
+
+	dest := t.TempDir()
+	require.NoError(t, Download(fileLocator(repoDir, first, ""), dest, noAuth, WithKeepGitDir(true)))
+
+	repo, err := git.PlainOpen(dest)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, first, head.Hash().String())
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	status, err := wt.Status()
+	require.NoError(t, err)
+	require.True(t, status.IsClean(), status.String())
+
+	remote, err := repo.Remote("origin")
+	require.NoError(t, err)
+	require.NotEmpty(t, remote.Config().URLs)
+
+	// Downloading again updates the existing git directory
+	require.NoError(t, Download(fileLocator(repoDir, second, ""), dest, noAuth, WithKeepGitDir(true), WithSync(true)))
+	repo, err = git.PlainOpen(dest)
+	require.NoError(t, err)
+	head, err = repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, second, head.Hash().String())
+	data, err := os.ReadFile(filepath.Join(dest, "hello.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "bye", string(data))
+
+	wt, err = repo.Worktree()
+	require.NoError(t, err)
+	status, err = wt.Status()
+	require.NoError(t, err)
+	require.True(t, status.IsClean(), status.String())
+}
+
+func TestLocalLayouts(t *testing.T) {
+	t.Parallel()
+	noAuth := WithSystemCredentials(false)
+
+	// The git directory of the worktree is moved elsewhere, leaving a
+	// .git file pointing to it as submodules do
+	sepDir, _ := initTestRepoWithFiles(t, map[string]string{"README.md": "separate"})
+	gitDir := filepath.Join(t.TempDir(), "separate.git")
+	require.NoError(t, os.Rename(filepath.Join(sepDir, ".git"), gitDir))
+	require.NoError(t, os.WriteFile(filepath.Join(sepDir, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o600))
+
+	for _, inPlace := range []bool{false, true} {
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(fileLocator(sepDir, "", "README.md"), &buf, noAuth, WithOpenInPlace(inPlace)))
+		require.Equal(t, "separate", buf.String())
+	}
+
+	gitBin, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git binary not found")
+	}
+
+	// The linked worktree has its own HEAD, its objects and references
+	// are in the common directory of the main worktree
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"README.md": "v1"})
+	commitTestFile(t, repoDir, "README.md", "v2")
+	linkedDir := filepath.Join(t.TempDir(), "linked")
+	out, err := exec.Command(gitBin, "-C", repoDir, "worktree", "add", "--detach", linkedDir, first).CombinedOutput() //nolint:gosec
+	require.NoError(t, err, string(out))
+
+	for _, tc := range []struct {
+		name     string
+		ref      string
+		expected string
+	}{
+		{"head", "", "v1"},
+		{"branch", "refs/heads/master", "v2"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, CopyFile(fileLocator(linkedDir, tc.ref, "README.md"), &buf, noAuth, WithOpenInPlace(true)))
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("reference", func(t *testing.T) {
+		t.Parallel()
+		clonePath := t.TempDir()
+		_, err := CloneRepository(
+			fileLocator(repoDir, "refs/heads/master", ""),
+			noAuth, WithReference(linkedDir), WithClonePath(clonePath), WithDiskStorage(""),
+		)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(clonePath, ".git", "objects", "info", "alternates"))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(repoDir, ".git", "objects")+"\n", string(data))
+	})
+}
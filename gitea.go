@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// codebergHost is the hostname of Codeberg, which runs Forgejo
+const codebergHost = "codeberg.org"
+
+// GiteaProvider fetches data through the Gitea API, also served by
+// Forgejo servers such as Codeberg.
+type GiteaProvider struct{}
+
+// Name returns the name of the forge
+func (GiteaProvider) Name() string {
+	return "gitea"
+}
+
+// OpenFile requests a file from the raw content endpoint of the
+// Gitea API
+func (GiteaProvider) OpenFile(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	owner, repo, err := ownerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"https://%s/api/v1/repos/%s/%s/raw/%s?ref=%s", c.Hostname,
+		url.PathEscape(owner), url.PathEscape(repo), escapePath(c.SubPath), url.QueryEscape(c.Commit),
+	)
+	return forgeGet(client, auth, u, nil)
+}
+
+// OpenArchive requests the tarball of a commit from the archive
+// endpoint of the Gitea API
+func (GiteaProvider) OpenArchive(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	owner, repo, err := ownerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"https://%s/api/v1/repos/%s/%s/archive/%s.tar.gz", c.Hostname,
+		url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(c.Commit),
+	)
+	return forgeGet(client, auth, u, nil)
+}
+
+// ListFiles lists the files of a commit with the recursive git trees
+// endpoint of the Gitea API, requesting pages until the listing is not
+// truncated
+func (GiteaProvider) ListFiles(client *http.Client, auth transport.AuthMethod, c *Components) ([]string, error) {
+	owner, repo, err := ownerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for page := 1; ; page++ {
+		u := fmt.Sprintf(
+			"https://%s/api/v1/repos/%s/%s/git/trees/%s?recursive=true&page=%d", c.Hostname,
+			url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(c.Commit), page,
+		)
+		resp, err := forgeGet(client, auth, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		tree := gitTreeResponse{}
+		if err := readForgeJSON(resp, &tree); err != nil {
+			return nil, err
+		}
+		files = append(files, tree.files()...)
+		if !tree.Truncated || len(tree.Tree) == 0 {
+			return files, nil
+		}
+	}
+}
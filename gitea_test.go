@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGiteaFastPath(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/org/repo/raw/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != testCommit {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		switch r.PathValue("path") {
+		case "README.md":
+			io.WriteString(w, "hello from gitea\n") //nolint:errcheck,gosec
+		case "docs/a b.txt":
+			io.WriteString(w, "nested\n") //nolint:errcheck,gosec
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := newForgeTestServer(t, mux)
+	srv.run(t, copyForgeFile, []fnOpt{WithGiteaHosts("forgejo.example.com")}, []forgeTest{
+		{"file", "git+https://forgejo.example.com/org/repo@" + testCommit + "#README.md", nil, "hello from gitea\n", false},
+		{"nested", "git+https://forgejo.example.com/org/repo.git@" + testCommit + "#docs/a b.txt", nil, "nested\n", false},
+		{"not-found", "git+https://forgejo.example.com/org/repo@" + testCommit + "#missing.txt", nil, nil, true},
+	})
+}
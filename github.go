@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+const (
+	// gitHubHost is the hostname of github.com, its API is served from
+	// api.github.com. GitHub Enterprise servers serve it under /api/v3.
+	gitHubHost = "github.com"
+
+	// gitHubRawMediaType makes the contents API return the raw file
+	gitHubRawMediaType = "application/vnd.github.raw+json"
+
+	// gitHubAPIVersion is the version of the REST API requested
+	gitHubAPIVersion = "2022-11-28"
+)
+
+// GitHubProvider fetches data through the GitHub REST API. It serves
+// github.com and GitHub Enterprise servers.
+type GitHubProvider struct{}
+
+// Name returns the name of the forge
+func (GitHubProvider) Name() string {
+	return "github"
+}
+
+// EnvToken returns the token set in the variables read by the GitHub CLI,
+// GH_TOKEN or GITHUB_TOKEN for github.com and GH_ENTERPRISE_TOKEN or
+// GITHUB_ENTERPRISE_TOKEN for enterprise servers
+func (GitHubProvider) EnvToken(host string) transport.AuthMethod {
+	vars := []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	if strings.EqualFold(host, gitHubHost) {
+		vars = []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	}
+	if token := firstEnv(vars...); token != "" {
+		return &githttp.BasicAuth{Username: tokenUsername, Password: token}
+	}
+	return nil
+}
+
+// gitHubAPIURL returns the base URL of the REST API of a GitHub host
+func gitHubAPIURL(host string) string {
+	if strings.EqualFold(host, gitHubHost) {
+		return "https://api." + gitHubHost
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// OpenFile requests a file from the GitHub contents API
+func (GitHubProvider) OpenFile(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	owner, repo, err := ownerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf(
+		"%s/repos/%s/%s/contents/%s?ref=%s", gitHubAPIURL(c.Hostname),
+		url.PathEscape(owner), url.PathEscape(repo), escapePath(c.SubPath), url.QueryEscape(c.Commit),
+	)
+	resp, err := forgeGet(client, auth, u, map[string]string{
+		"Accept":               gitHubRawMediaType,
+		"X-GitHub-Api-Version": gitHubAPIVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Directories and submodules are returned as JSON objects even when
+	// the raw contents are requested
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt == "application/json" {
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("%q is not a file", c.SubPath)
+	}
+	return resp, nil
+}
+
+// OpenArchive requests the tarball of a commit from the GitHub API,
+// which redirects to the archive download
+func (GitHubProvider) OpenArchive(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	owner, repo, err := ownerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"%s/repos/%s/%s/tarball/%s", gitHubAPIURL(c.Hostname),
+		url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(c.Commit),
+	)
+	return forgeGet(client, auth, u, map[string]string{"X-GitHub-Api-Version": gitHubAPIVersion})
+}
+
+// ListFiles lists the files of a commit with the recursive git trees API.
+// Trees too large to be listed in one response are reported as an error.
+func (GitHubProvider) ListFiles(client *http.Client, auth transport.AuthMethod, c *Components) ([]string, error) {
+	owner, repo, err := ownerRepo(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"%s/repos/%s/%s/git/trees/%s?recursive=1", gitHubAPIURL(c.Hostname),
+		url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(c.Commit),
+	)
+	resp, err := forgeGet(client, auth, u, map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": gitHubAPIVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tree := gitTreeResponse{}
+	if err := readForgeJSON(resp, &tree); err != nil {
+		return nil, err
+	}
+	if tree.Truncated {
+		return nil, fmt.Errorf("tree of %s is too large to be listed", c.Commit)
+	}
+	return tree.files(), nil
+}
+
+// gitTreeEntry is an entry of the tree listings of the forge APIs. Files
+// are listed with the blob type.
+type gitTreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// gitTreeResponse is the recursive tree listing of the git trees API of
+// GitHub, also served by Gitea
+type gitTreeResponse struct {
+	Tree      []gitTreeEntry `json:"tree"`
+	Truncated bool           `json:"truncated"`
+}
+
+// files returns the paths of the blobs in the listing
+func (t *gitTreeResponse) files() []string {
+	files := []string{}
+	for _, e := range t.Tree {
+		if e.Type == "blob" {
+			files = append(files, e.Path)
+		}
+	}
+	return files
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newGitHubTestServer starts a server answering the contents API requests
+// of a GitHub Enterprise server at example.com. It returns the server and a
+// counter of the requests received.
+func newGitHubTestServer(t *testing.T) (*forgeTestServer, *atomic.Int32) {
+	t.Helper()
+	lfs, _ := lfsTestPointer("large file")
+	files := map[string]string{
+		"README.md":         "hello from the api\n",
+		"dir/file name.txt": "spaces\n",
+		"large.bin":         string(lfs),
+	}
+	var requests atomic.Int32
+	srv := newForgeTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Accept") != gitHubRawMediaType || r.URL.Query().Get("ref") != testCommit {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); ok && (user != "user" || pass != "token") {
+			http.Error(w, "bad credentials", http.StatusUnauthorized)
+			return
+		}
+		p, ok := strings.CutPrefix(r.URL.Path, "/api/v3/repos/org/repo/contents/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if p == "dir" {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			io.WriteString(w, `[]`) //nolint:errcheck,gosec
+			return
+		}
+		data, ok := files[p]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", gitHubRawMediaType)
+		io.WriteString(w, data) //nolint:errcheck,gosec
+	}))
+	return srv, &requests
+}
+
+func TestGitHubFastPath(t *testing.T) {
+	t.Parallel()
+	srv, requests := newGitHubTestServer(t)
+	base := "git+https://example.com/org/repo@" + testCommit + "#"
+	provider := []fnOpt{WithGitHubEnterprise("example.com")}
+	funcs := append([]fnOpt{WithHttpClient(srv.client)}, provider...)
+
+	srv.run(t, copyForgeFile, provider, []forgeTest{
+		{"file", base + "README.md", nil, "hello from the api\n", false},
+		{"escaped", base + "dir/file name.txt", nil, "spaces\n", false},
+		{"auth", base + "README.md", []fnOpt{WithHttpAuth("user", "token")}, "hello from the api\n", false},
+		{"lines", base + "README.md", []fnOpt{WithLineRange(1, 1)}, "hello from the api\n", false},
+		{"digest", base + "README.md", []fnOpt{WithExpectedDigest("sha256:0000000000000000000000000000000000000000000000000000000000000000")}, nil, true},
+		{"size-limit", base + "README.md", []fnOpt{WithMaxFileSize(4)}, nil, true},
+		{"not-found", base + "missing.txt", nil, nil, true},
+		{"directory", base + "dir", nil, nil, true},
+		{"lfs", base + "large.bin", nil, nil, true},
+		{"bad-auth", base + "README.md", []fnOpt{WithHttpAuth("user", "wrong")}, nil, true},
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		t.Parallel()
+		r, err := GetReader(base+"README.md", funcs...)
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.Equal(t, "hello from the api\n", string(data))
+	})
+
+	t.Run("group", func(t *testing.T) {
+		t.Parallel()
+		data, err := GetGroup([]string{base + "README.md", base + "dir/file name.txt"}, funcs...)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("hello from the api\n"), []byte("spaces\n")}, data)
+	})
+
+	t.Run("group-fallback", func(t *testing.T) {
+		t.Parallel()
+		err := CopyFileGroup(
+			[]string{base + "README.md", base + "missing.txt"},
+			[]io.Writer{&bytes.Buffer{}, &bytes.Buffer{}}, funcs...,
+		)
+		var el *ErrorList
+		require.ErrorAs(t, err, &el)
+		require.NoError(t, el.Errors[0])
+		require.Error(t, el.Errors[1])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		srv, requests := newGitHubTestServer(t)
+		err := CopyFile(
+			base+"README.md", io.Discard,
+			WithHttpClient(srv.client), WithGitHubEnterprise("example.com"), WithForgeAPI(false),
+		)
+		require.Error(t, err)
+		require.Zero(t, requests.Load())
+	})
+
+	t.Cleanup(func() { require.NotZero(t, requests.Load()) })
+}
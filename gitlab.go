@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// gitLabHost is the hostname of gitlab.com
+const gitLabHost = "gitlab.com"
+
+// GitLabProvider fetches data through the GitLab REST API. It serves
+// gitlab.com and self-managed instances.
+type GitLabProvider struct{}
+
+// Name returns the name of the forge
+func (GitLabProvider) Name() string {
+	return "gitlab"
+}
+
+// EnvToken returns the token set in the variables read by the GitLab CLI,
+// GITLAB_TOKEN or GITLAB_ACCESS_TOKEN
+func (GitLabProvider) EnvToken(string) transport.AuthMethod {
+	if token := firstEnv("GITLAB_TOKEN", "GITLAB_ACCESS_TOKEN"); token != "" {
+		return &githttp.BasicAuth{Username: gitLabTokenUsername, Password: token}
+	}
+	return nil
+}
+
+// gitLabProject returns the URL encoded path identifying the project of
+// the components in the GitLab API
+func gitLabProject(c *Components) (string, error) {
+	project := strings.TrimSuffix(strings.Trim(c.RepoPath, "/"), ".git")
+	if !strings.Contains(project, "/") {
+		return "", fmt.Errorf("%q is not a GitLab project path", c.RepoPath)
+	}
+	return url.PathEscape(project), nil
+}
+
+// gitLabTokenUsername is the username GitLab expects when a token is sent
+// as the password of HTTP basic auth
+const gitLabTokenUsername = "oauth2"
+
+// gitLabGet performs a request to the GitLab API. Tokens set as the
+// password of the basic auth credentials (with the oauth2 or x-access-token
+// username) are sent in the header the API expects. Passwords are never
+// sent as tokens.
+func gitLabGet(client *http.Client, auth transport.AuthMethod, u string) (*http.Response, error) {
+	header := map[string]string{}
+	if ba, ok := auth.(*githttp.BasicAuth); ok && ba.Password != "" &&
+		(ba.Username == gitLabTokenUsername || ba.Username == tokenUsername) {
+		header["PRIVATE-TOKEN"] = ba.Password
+		auth = nil
+	}
+	return forgeGet(client, auth, u, header)
+}
+
+// OpenFile requests a file from the raw endpoint of the GitLab
+// repository files API. Projects and files are identified by their full
+// paths, URL encoded including the slashes.
+func (GitLabProvider) OpenFile(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	project, err := gitLabProject(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"https://%s/api/v4/projects/%s/repository/files/%s/raw?ref=%s", c.Hostname,
+		project, url.PathEscape(strings.Trim(c.SubPath, "/")), url.QueryEscape(c.Commit),
+	)
+	return gitLabGet(client, auth, u)
+}
+
+// OpenArchive requests the tarball of a commit from the GitLab
+// repository archive API
+func (GitLabProvider) OpenArchive(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	project, err := gitLabProject(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"https://%s/api/v4/projects/%s/repository/archive.tar.gz?sha=%s", c.Hostname,
+		project, url.QueryEscape(c.Commit),
+	)
+	return gitLabGet(client, auth, u)
+}
+
+// ListFiles lists the files of a commit with the repository tree API,
+// following its pages
+func (GitLabProvider) ListFiles(client *http.Client, auth transport.AuthMethod, c *Components) ([]string, error) {
+	project, err := gitLabProject(c)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for page := "1"; page != ""; {
+		u := fmt.Sprintf(
+			"https://%s/api/v4/projects/%s/repository/tree?ref=%s&recursive=true&per_page=100&page=%s", c.Hostname,
+			project, url.QueryEscape(c.Commit), url.QueryEscape(page),
+		)
+		resp, err := gitLabGet(client, auth, u)
+		if err != nil {
+			return nil, err
+		}
+		page = resp.Header.Get("X-Next-Page")
+
+		entries := []gitTreeEntry{}
+		if err := readForgeJSON(resp, &entries); err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type == "blob" {
+				files = append(files, e.Path)
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGitLabFastPath(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"/api/v4/projects/group%2Fsub%2Fproject/repository/files/README.md/raw":        "hello from gitlab\n",
+		"/api/v4/projects/group%2Fsub%2Fproject/repository/files/docs%2Fa%20b.txt/raw": "nested\n",
+		"/api/v4/projects/group%2Fsub%2Fproject/repository/files/private.txt/raw":      "secret\n",
+	}
+	srv := newForgeTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != testCommit {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		p := r.URL.EscapedPath()
+		if p == "/api/v4/projects/group%2Fsub%2Fproject/repository/files/private.txt/raw" && r.Header.Get("PRIVATE-TOKEN") != "token" {
+			http.NotFound(w, r)
+			return
+		}
+		data, ok := files[p]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, data) //nolint:errcheck,gosec
+	}))
+
+	base := "git+https://example.com/group/sub/project.git@" + testCommit + "#"
+	srv.run(t, copyForgeFile, []fnOpt{WithGitLabHosts("example.com")}, []forgeTest{
+		{"file", base + "README.md", nil, "hello from gitlab\n", false},
+		{"nested", base + "docs/a b.txt", nil, "nested\n", false},
+		{"token", base + "private.txt", []fnOpt{WithHttpAuth("oauth2", "token")}, "secret\n", false},
+		{"access-token", base + "private.txt", []fnOpt{WithHttpAuth(tokenUsername, "token")}, "secret\n", false},
+		{"password", base + "private.txt", []fnOpt{WithHttpAuth("user", "token")}, nil, true},
+		{"no-token", base + "private.txt", nil, nil, true},
+		{"not-found", base + "missing.txt", nil, nil, true},
+	})
+}
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Types of git objects identified by gitoids
+const (
+	GitOIDTypeBlob = "blob"
+	GitOIDTypeTree = "tree"
+)
+
+// GitOID captures the git object identifiers of a file or directory in the
+// repository.
+type GitOID struct {
+	// Path is the path of the object relative to the repository root
+	Path string
+
+	// Type is the git object type: blob (files) or tree (directories)
+	Type string
+
+	// SHA1 is the git object id as recorded in the repository
+	SHA1 string
+
+	// SHA256 is the SHA-256 gitoid of the object. It is only computed for
+	// blobs as tree objects reference their entries by SHA-1.
+	SHA256 string
+}
+
+// URI returns the SHA-1 GitOID URI of the object (gitoid:blob:sha1:...)
+func (g *GitOID) URI() string {
+	return fmt.Sprintf("gitoid:%s:sha1:%s", g.Type, g.SHA1)
+}
+
+// SHA256URI returns the SHA-256 GitOID URI of the object or an empty string
+// if the SHA-256 gitoid was not computed.
+func (g *GitOID) SHA256URI() string {
+	if g.SHA256 == "" {
+		return ""
+	}
+	return fmt.Sprintf("gitoid:%s:sha256:%s", g.Type, g.SHA256)
+}
+
+// GetGitOIDs returns the gitoids of the object referenced by the locator. If
+// the subpath points to a file, its blob gitoid is returned. If it points to
+// a directory (or the locator has no subpath), the list contains the tree of
+// the directory followed by all the trees and blobs under it.
+//
+// SHA-1 identifiers are read from the repository objects, only the SHA-256
+// blob gitoids require hashing the file contents.
+func GetGitOIDs[T ~string](locator T, funcs ...fnOpt) ([]GitOID, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+
+	root, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit tree: %w", err)
+	}
+
+	subpath := strings.Trim(cloned.Components.SubPath, "/")
+	if subpath == "" {
+		return treeGitOIDs(root, "")
+	}
+
+	entry, err := root.FindEntry(subpath)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %q: %w", subpath, err)
+	}
+
+	switch entry.Mode {
+	case filemode.Dir:
+		tree, err := root.Tree(subpath)
+		if err != nil {
+			return nil, fmt.Errorf("reading tree %q: %w", subpath, err)
+		}
+		return treeGitOIDs(tree, subpath)
+	case filemode.Submodule:
+		return nil, fmt.Errorf("%q is a submodule", subpath)
+	default:
+		blob, err := root.TreeEntryFile(entry)
+		if err != nil {
+			return nil, fmt.Errorf("reading file %q: %w", subpath, err)
+		}
+		oid, err := blobGitOID(&blob.Blob, subpath)
+		if err != nil {
+			return nil, err
+		}
+		return []GitOID{*oid}, nil
+	}
+}
+
+// treeGitOIDs returns the gitoid of a tree and every object below it. The
+// prefix is the path of the tree in the repository.
+func treeGitOIDs(tree *object.Tree, prefix string) ([]GitOID, error) {
+	ret := []GitOID{{Path: prefix, Type: GitOIDTypeTree, SHA1: tree.Hash.String()}}
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("walking tree: %w", err)
+		}
+
+		p := path.Join(prefix, name)
+		switch entry.Mode {
+		case filemode.Dir:
+			ret = append(ret, GitOID{Path: p, Type: GitOIDTypeTree, SHA1: entry.Hash.String()})
+		case filemode.Submodule:
+			// Submodule commits are not part of the repository
+			continue
+		default:
+			blob, err := tree.TreeEntryFile(&entry)
+			if err != nil {
+				return nil, fmt.Errorf("reading file %q: %w", p, err)
+			}
+			oid, err := blobGitOID(&blob.Blob, p)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, *oid)
+		}
+	}
+	return ret, nil
+}
+
+// blobGitOID builds the gitoid of a blob, computing its SHA-256 gitoid
+func blobGitOID(blob *object.Blob, p string) (*GitOID, error) {
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("reading blob %q: %w", p, err)
+	}
+	defer r.Close() //nolint:errcheck
+
+	h := newGitOIDHasher(blob.Size)
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, fmt.Errorf("hashing blob %q: %w", p, err)
+	}
+
+	return &GitOID{
+		Path:   p,
+		Type:   GitOIDTypeBlob,
+		SHA1:   blob.Hash.String(),
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// newGitOIDHasher returns a SHA-256 hasher primed with the header of a blob
+// of the specified size. Writing the blob contents to it yields its gitoid.
+func newGitOIDHasher(size int64) hash.Hash {
+	h := sha256.New()
+	fmt.Fprintf(h, "blob %d\x00", size)
+	return h
+}
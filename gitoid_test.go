@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGitOIDs(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"hello.txt":        "hello",
+		"docs/guide.md":    "# Guide",
+		"docs/api/spec.md": "spec",
+	})
+
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(plumbing.NewHash(commitHash))
+	require.NoError(t, err)
+	root, err := commit.Tree()
+	require.NoError(t, err)
+	docs, err := root.Tree("docs")
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte("blob 5\x00hello"))
+	helloSHA256 := hex.EncodeToString(sum[:])
+	helloSHA1 := plumbing.ComputeHash(plumbing.BlobObject, []byte("hello")).String()
+
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		oids, err := GetGitOIDs(fileLocator(repoDir, commitHash, "hello.txt"), noAuth)
+		require.NoError(t, err)
+		require.Len(t, oids, 1)
+		require.Equal(t, "hello.txt", oids[0].Path)
+		require.Equal(t, GitOIDTypeBlob, oids[0].Type)
+		require.Equal(t, helloSHA1, oids[0].SHA1)
+		require.Equal(t, helloSHA256, oids[0].SHA256)
+		require.Equal(t, "gitoid:blob:sha1:"+helloSHA1, oids[0].URI())
+		require.Equal(t, "gitoid:blob:sha256:"+helloSHA256, oids[0].SHA256URI())
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		t.Parallel()
+		oids, err := GetGitOIDs(fileLocator(repoDir, commitHash, "docs/"), noAuth)
+		require.NoError(t, err)
+
+		byPath := map[string]GitOID{}
+		for _, oid := range oids {
+			byPath[oid.Path] = oid
+		}
+		require.Len(t, byPath, 4)
+		require.Equal(t, "docs", oids[0].Path)
+		require.Equal(t, docs.Hash.String(), byPath["docs"].SHA1)
+		api := byPath["docs/api"]
+		require.Equal(t, GitOIDTypeTree, api.Type)
+		require.Empty(t, api.SHA256URI())
+		require.Equal(t, GitOIDTypeBlob, byPath["docs/api/spec.md"].Type)
+		require.Equal(t,
+			plumbing.ComputeHash(plumbing.BlobObject, []byte("# Guide")).String(),
+			byPath["docs/guide.md"].SHA1,
+		)
+	})
+
+	t.Run("root", func(t *testing.T) {
+		t.Parallel()
+		oids, err := GetGitOIDs(fileLocator(repoDir, commitHash, ""), noAuth)
+		require.NoError(t, err)
+		require.Len(t, oids, 6)
+		require.Equal(t, root.Hash.String(), oids[0].SHA1)
+		require.Equal(t, "gitoid:tree:sha1:"+root.Hash.String(), oids[0].URI())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetGitOIDs(fileLocator(repoDir, commitHash, "nope.txt"), noAuth)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,277 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/smallstep/pkcs7"
+)
+
+// gitsignPEMType is the PEM block type of the S/MIME signatures produced by
+// gitsign (and git's x509 signing format).
+const gitsignPEMType = "SIGNED MESSAGE"
+
+var (
+	// oidFulcioIssuerV2 is the Fulcio extension recording the OIDC issuer
+	// as a DER encoded UTF8String.
+	oidFulcioIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+
+	// oidFulcioIssuerV1 is the deprecated Fulcio issuer extension that stores
+	// the issuer URL as raw bytes.
+	oidFulcioIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+)
+
+// TransparencyLog abstracts the transparency log (ie Rekor) used to check
+// that a keyless signature was publicly recorded when it was made.
+type TransparencyLog interface {
+	// VerifyInclusion returns an error if the log has no valid entry for
+	// the signature, made by the certificate over the payload.
+	VerifyInclusion(cert *x509.Certificate, signature, payload []byte) error
+}
+
+// GitsignOptions configures the verification of gitsign signatures.
+type GitsignOptions struct {
+	// Roots holds the trusted certificate authorities (ie the Fulcio roots)
+	Roots *x509.CertPool
+
+	// Intermediates holds intermediate certificates not embedded in the
+	// signatures.
+	Intermediates *x509.CertPool
+
+	// TransparencyLog, when set, is queried to check that the signature was
+	// recorded in the log. When nil, no inclusion check is performed.
+	TransparencyLog TransparencyLog
+}
+
+// GitsignVerification captures the result of verifying a gitsign signature.
+type GitsignVerification struct {
+	// ObjectType is the type of the verified object: commit or tag
+	ObjectType string
+
+	// Hash is the hash of the verified object
+	Hash string
+
+	// Signed is true when the object carries an x509 signature
+	Signed bool
+
+	// Verified is true when the signature is valid, the certificate chains
+	// to the trusted roots and, if configured, the transparency log check
+	// passed.
+	Verified bool
+
+	// CertificateIdentity is the identity bound to the signing certificate
+	// (the email or URI subject alternative name).
+	CertificateIdentity string
+
+	// CertificateIssuer is the OIDC issuer recorded in the certificate by
+	// Fulcio
+	CertificateIssuer string
+
+	// Certificate is the signing certificate
+	Certificate *x509.Certificate
+
+	// SigningTime is the time the signature was made. It is read from the
+	// signature attributes or, when missing, from the object timestamp.
+	SigningTime time.Time
+
+	// TransparencyLogVerified is true when the inclusion of the signature
+	// in the transparency log was verified.
+	TransparencyLogVerified bool
+
+	// Reason explains why the verification failed
+	Reason string
+}
+
+// VerifyGitsign checks the Sigstore keyless (gitsign) signature of the object
+// referenced by the locator. Like VerifySignature, an annotated tag is
+// verified when the locator points to one, otherwise the commit.
+//
+// Certificates are verified at signing time as Fulcio certificates are short
+// lived. Failing verifications are reported in the returned structure, errors
+// are only returned if the data could not be fetched.
+func VerifyGitsign[T ~string](locator T, gitsignOpts *GitsignOptions, funcs ...fnOpt) (*GitsignVerification, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if gitsignOpts == nil || gitsignOpts.Roots == nil {
+		return nil, errors.New("no trusted roots specified to verify signatures")
+	}
+
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	if cloned.Components.Tag != "" {
+		ref, err := cloned.Repo.Reference(plumbing.NewTagReferenceName(cloned.Components.Tag), true)
+		if err != nil {
+			return nil, fmt.Errorf("reading tag reference: %w", err)
+		}
+
+		tag, err := cloned.Repo.TagObject(ref.Hash())
+		switch {
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+		case err != nil:
+			return nil, fmt.Errorf("reading tag object: %w", err)
+		default:
+			payload, err := signedPayload(tag)
+			if err != nil {
+				return nil, fmt.Errorf("encoding tag: %w", err)
+			}
+			res := &GitsignVerification{ObjectType: ObjectTypeTag, Hash: tag.Hash.String()}
+			res.verify(gitsignOpts, tag.PGPSignature, payload, tag.Tagger.When)
+			return res, nil
+		}
+	}
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+
+	payload, err := signedPayload(commit)
+	if err != nil {
+		return nil, fmt.Errorf("encoding commit: %w", err)
+	}
+	res := &GitsignVerification{ObjectType: ObjectTypeCommit, Hash: commit.Hash.String()}
+	res.verify(gitsignOpts, commit.PGPSignature, payload, commit.Committer.When)
+	return res, nil
+}
+
+// signedObject is a git object carrying a signature (commit or tag)
+type signedObject interface {
+	EncodeWithoutSignature(plumbing.EncodedObject) error
+}
+
+// signedPayload returns the data covered by the signature of a commit or tag
+func signedPayload(obj signedObject) ([]byte, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := obj.EncodeWithoutSignature(encoded); err != nil {
+		return nil, err
+	}
+	r, err := encoded.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close() //nolint:errcheck
+
+	return io.ReadAll(r)
+}
+
+// verify checks the armored signature over the payload and records the
+// outcome in the result.
+func (gv *GitsignVerification) verify(gitsignOpts *GitsignOptions, armored string, payload []byte, objectTime time.Time) {
+	if armored == "" {
+		gv.Reason = fmt.Sprintf("%s is not signed", gv.ObjectType)
+		return
+	}
+
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != gitsignPEMType {
+		gv.Reason = "signature is not an x509 (gitsign) signature"
+		return
+	}
+	gv.Signed = true
+
+	p7, err := pkcs7.Parse(block.Bytes)
+	if err != nil {
+		gv.Reason = fmt.Sprintf("parsing signature: %s", err)
+		return
+	}
+	// gitsign produces detached signatures
+	p7.Content = payload
+
+	cert := p7.GetOnlySigner()
+	if cert == nil {
+		gv.Reason = "signature must have exactly one signer"
+		return
+	}
+	gv.Certificate = cert
+	gv.CertificateIdentity = certificateIdentity(cert)
+	gv.CertificateIssuer = certificateIssuer(cert)
+
+	gv.SigningTime = objectTime
+	var signingTime time.Time
+	if err := p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeSigningTime, &signingTime); err == nil {
+		gv.SigningTime = signingTime
+	}
+
+	// Check the signature and the signing time against the cert validity
+	if err := p7.Verify(); err != nil {
+		gv.Reason = fmt.Sprintf("verifying signature: %s", err)
+		return
+	}
+
+	intermediates := x509.NewCertPool()
+	if gitsignOpts.Intermediates != nil {
+		intermediates = gitsignOpts.Intermediates.Clone()
+	}
+	for _, c := range p7.Certificates {
+		if c != cert {
+			intermediates.AddCert(c)
+		}
+	}
+
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         gitsignOpts.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   gv.SigningTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		gv.Reason = fmt.Sprintf("verifying certificate chain: %s", err)
+		return
+	}
+
+	if gitsignOpts.TransparencyLog != nil {
+		if err := gitsignOpts.TransparencyLog.VerifyInclusion(cert, block.Bytes, payload); err != nil {
+			gv.Reason = fmt.Sprintf("verifying transparency log inclusion: %s", err)
+			return
+		}
+		gv.TransparencyLogVerified = true
+	}
+
+	gv.Verified = true
+}
+
+// certificateIdentity returns the identity in the certificate SAN
+func certificateIdentity(cert *x509.Certificate) string {
+	switch {
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	default:
+		return ""
+	}
+}
+
+// certificateIssuer reads the OIDC issuer from the Fulcio extensions
+func certificateIssuer(cert *x509.Certificate) string {
+	legacy := ""
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidFulcioIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidFulcioIssuerV1):
+			legacy = string(ext.Value)
+		}
+	}
+	return legacy
+}
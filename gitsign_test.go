@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/smallstep/pkcs7"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA is a certificate authority used to issue test signing certificates
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+// issue creates a short lived code signing certificate like those issued by
+// Fulcio, bound to an email identity.
+func (ca *testCA) issue(t *testing.T, email, issuer string) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	issuerExt, err := asn1.Marshal(issuer)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:    big.NewInt(time.Now().UnixNano()),
+		NotBefore:       time.Now().Add(-time.Minute),
+		NotAfter:        time.Now().Add(10 * time.Minute),
+		EmailAddresses:  []string{email},
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{{Id: oidFulcioIssuerV2, Value: issuerExt}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, key.Public(), ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// gitsignCommit stores a commit on top of HEAD signed with a detached
+// S/MIME signature like the ones generated by gitsign.
+func gitsignCommit(t *testing.T, repoDir string, cert *x509.Certificate, key crypto.Signer) string {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	parent, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+
+	sig := object.Signature{Name: "test", Email: "test@test.com", When: time.Now()}
+	commit := &object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      "gitsigned commit",
+		TreeHash:     parent.TreeHash,
+		ParentHashes: []plumbing.Hash{parent.Hash},
+	}
+
+	payload, err := signedPayload(commit)
+	require.NoError(t, err)
+	sd, err := pkcs7.NewSignedData(payload)
+	require.NoError(t, err)
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	require.NoError(t, sd.AddSigner(cert, key, pkcs7.SignerInfoConfig{}))
+	sd.Detach()
+	der, err := sd.Finish()
+	require.NoError(t, err)
+	commit.PGPSignature = string(pem.EncodeToMemory(&pem.Block{Type: gitsignPEMType, Bytes: der}))
+
+	obj := repo.Storer.NewEncodedObject()
+	require.NoError(t, commit.Encode(obj))
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(head.Name(), hash)))
+	return hash.String()
+}
+
+// fakeTransparencyLog reports all entries as included or missing
+type fakeTransparencyLog struct {
+	included bool
+}
+
+func (f *fakeTransparencyLog) VerifyInclusion(_ *x509.Certificate, _, _ []byte) error {
+	if !f.included {
+		return errors.New("entry not found in log")
+	}
+	return nil
+}
+
+func TestVerifyGitsign(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	ca := newTestCA(t)
+	cert, key := ca.issue(t, "signer@example.com", "https://accounts.example.com")
+
+	repoDir, unsigned := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	signed := gitsignCommit(t, repoDir, cert, key)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	otherRoots := x509.NewCertPool()
+	otherRoots.AddCert(newTestCA(t).cert)
+
+	for _, tc := range []struct {
+		name     string
+		commit   string
+		opts     *GitsignOptions
+		signed   bool
+		verified bool
+		tlog     bool
+	}{
+		{"signed", signed, &GitsignOptions{Roots: roots}, true, true, false},
+		{"tlog-included", signed, &GitsignOptions{Roots: roots, TransparencyLog: &fakeTransparencyLog{included: true}}, true, true, true},
+		{"tlog-missing", signed, &GitsignOptions{Roots: roots, TransparencyLog: &fakeTransparencyLog{}}, true, false, false},
+		{"untrusted-root", signed, &GitsignOptions{Roots: otherRoots}, true, false, false},
+		{"unsigned", unsigned, &GitsignOptions{Roots: roots}, false, false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			res, err := VerifyGitsign(fileLocator(repoDir, tc.commit, ""), tc.opts, noAuth)
+			require.NoError(t, err)
+			require.Equal(t, ObjectTypeCommit, res.ObjectType)
+			require.Equal(t, tc.commit, res.Hash)
+			require.Equal(t, tc.signed, res.Signed)
+			require.Equal(t, tc.verified, res.Verified, res.Reason)
+			require.Equal(t, tc.tlog, res.TransparencyLogVerified)
+			if !tc.verified {
+				require.NotEmpty(t, res.Reason)
+			}
+			if tc.signed {
+				require.Equal(t, "signer@example.com", res.CertificateIdentity)
+				require.Equal(t, "https://accounts.example.com", res.CertificateIssuer)
+				require.False(t, res.SigningTime.IsZero())
+			}
+		})
+	}
+
+	t.Run("no-roots", func(t *testing.T) {
+		t.Parallel()
+		_, err := VerifyGitsign(fileLocator(repoDir, signed, ""), nil, noAuth)
+		require.Error(t, err)
+	})
+}
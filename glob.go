@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// validateGlob checks a glob pattern can be matched with matchGlob
+func validateGlob(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("empty glob pattern")
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchGlob matches a slash separated path against a glob pattern. Each
+// pattern segment is matched with path.Match against one path segment,
+// except for ** which matches any number of segments, including none.
+func matchGlob(pattern, name string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlobSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchFilters returns true if the path matches at least one of the include
+// patterns (or there are none) and does not match any exclude pattern.
+func matchFilters(name string, include, exclude []string) bool {
+	for _, pattern := range exclude {
+		if matchGlob(pattern, name) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		pattern string
+		name    string
+		expect  bool
+	}{
+		{"*.yaml", "a.yaml", true},
+		{"*.yaml", "dir/a.yaml", false},
+		{"**/*.yaml", "a.yaml", true},
+		{"**/*.yaml", "dir/sub/a.yaml", true},
+		{"**/*.yaml", "dir/sub/a.yml", false},
+		{"vendor/**", "vendor/x/y.go", true},
+		{"vendor/**", "src/vendor/y.go", false},
+		{"**/testdata/**", "pkg/testdata/f.bin", true},
+		{"**/testdata/**", "testdata/f.bin", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/y/c", false},
+		{"dir/?.txt", "dir/a.txt", true},
+		{"dir/[ab].txt", "dir/c.txt", false},
+	} {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.NoError(t, validateGlob(tc.pattern))
+			require.Equal(t, tc.expect, matchGlob(tc.pattern, tc.name))
+		})
+	}
+	require.Error(t, validateGlob("dir/[a"))
+	require.Error(t, validateGlob(""))
+}
+
+func TestDownloadFilters(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		"deploy/app.yaml":              "app",
+		"deploy/base/svc.yaml":         "svc",
+		"deploy/base/README.md":        "readme",
+		"deploy/vendor/lib/dep.yaml":   "dep",
+		"deploy/testdata/fixture.yaml": "fixture",
+		"other/x.yaml":                 "x",
+	})
+	locator := fileLocator(repoDir, commit, "deploy")
+
+	dest := t.TempDir()
+	require.NoError(t, Download(
+		locator, dest, noAuth,
+		WithInclude("**/*.yaml"), WithExclude("vendor/**", "**/testdata/**"),
+	))
+
+	require.FileExists(t, filepath.Join(dest, "deploy", "app.yaml"))
+	require.FileExists(t, filepath.Join(dest, "deploy", "base", "svc.yaml"))
+	require.NoFileExists(t, filepath.Join(dest, "deploy", "base", "README.md"))
+	require.NoDirExists(t, filepath.Join(dest, "deploy", "vendor"))
+	require.NoDirExists(t, filepath.Join(dest, "deploy", "testdata"))
+	require.NoDirExists(t, filepath.Join(dest, "other"))
+
+	// Single file subpaths are matched by name
+	dest = t.TempDir()
+	require.NoError(t, Download(
+		fileLocator(repoDir, commit, "deploy/base/README.md"), dest, noAuth, WithInclude("*.yaml"),
+	))
+	require.NoFileExists(t, filepath.Join(dest, "deploy", "base", "README.md"))
+
+	require.Error(t, Download(locator, dest, noAuth, WithInclude("[")))
+}
@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// goImport is a go-import meta tag declaration
+type goImport struct {
+	Prefix, VCS, RepoRoot, SubDir string
+}
+
+// ResolveGoModule turns a Go module path into the VCS locator of its
+// repository. The module path may include a version
+// (golang.org/x/mod@v0.30.0) which is converted to the git ref it was
+// published from.
+//
+// Modules hosted in GitHub, GitLab, Bitbucket, Codeberg and SourceHut are
+// mapped directly, for any other host the repository is looked up in the
+// go-import meta tags served at https://<module>?go-get=1. Only git
+// repositories are supported.
+func ResolveGoModule(modulePath string, funcs ...fnOpt) (Locator, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return "", err
+		}
+	}
+
+	modulePath, version, _ := strings.Cut(strings.TrimSpace(modulePath), "@")
+	modulePath = strings.Trim(modulePath, "/")
+	parts := strings.Split(modulePath, "/")
+	if modulePath == "" || !strings.Contains(parts[0], ".") {
+		return "", fmt.Errorf("invalid go module path %q", modulePath)
+	}
+
+	var repoURL, prefix, subdir string
+	if isGoRepoHost(parts[0]) {
+		if len(parts) < 3 {
+			return "", fmt.Errorf("go module path %q has no repository", modulePath)
+		}
+		prefix = strings.Join(parts[:3], "/")
+		repoURL = "https://" + prefix
+	} else {
+		if err := checkHostLists(Locator(modulePath), parts[0], &opts); err != nil {
+			return "", err
+		}
+		if err := requireOnline(Locator(modulePath), &opts); err != nil {
+			return "", err
+		}
+		imports, err := fetchGoImports(opts.httpClient(), modulePath)
+		if err != nil {
+			return "", err
+		}
+		imp, err := matchGoImport(imports, modulePath)
+		if err != nil {
+			return "", err
+		}
+		prefix, repoURL, subdir = imp.Prefix, imp.RepoRoot, imp.SubDir
+	}
+
+	// Compute the module directory in the repository
+	rest := strings.Trim(strings.TrimPrefix(modulePath, prefix), "/")
+	var dirParts []string
+	if rest != "" {
+		dirParts = strings.Split(rest, "/")
+	}
+	moduleDir := goModuleDir(dirParts)
+	if subdir != "" {
+		moduleDir = strings.Trim(subdir+"/"+moduleDir, "/")
+	}
+
+	ref, err := goModuleRef(version, moduleDir)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing repository URL: %w", err)
+	}
+	if u.Scheme != TransportHTTPS && u.Scheme != TransportSSH {
+		return "", fmt.Errorf("unsupported repository URL scheme %q", u.Scheme)
+	}
+
+	c := &Components{
+		Tool:      ToolGit,
+		Transport: u.Scheme,
+		Hostname:  u.Host,
+		RepoPath:  u.Path,
+		RefString: ref,
+		SubPath:   moduleDir,
+	}
+	return Locator(c.String()), nil
+}
+
+// hostPurlType returns the purl type of a known code hosting service
+func hostPurlType(hostname string) string {
+	for purlType, host := range purlHosts {
+		if host == hostname {
+			return purlType
+		}
+	}
+	return ""
+}
+
+// isGoRepoHost returns true for the hosts where the first two elements of
+// go module paths are the path of the repository (ie git.sr.ht/~user/repo)
+func isGoRepoHost(hostname string) bool {
+	return hostPurlType(hostname) != "" || hostname == codebergHost || hostname == sourceHutHost
+}
+
+// fetchGoImports requests the go-get page of the module path and returns its
+// go-import declarations.
+func fetchGoImports(client *http.Client, modulePath string) ([]goImport, error) {
+	resp, err := client.Get("https://" + modulePath + "?go-get=1")
+	if err != nil {
+		return nil, fmt.Errorf("fetching go-get metadata: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching go-get metadata: http status %d", resp.StatusCode)
+	}
+	return parseGoImports(resp.Body)
+}
+
+// parseGoImports reads the go-import meta tags from the head of an HTML
+// document. Like the go command, it uses a lenient XML decoder.
+func parseGoImports(r io.Reader) ([]goImport, error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "utf-8", "ascii":
+			return input, nil
+		default:
+			return nil, fmt.Errorf("can't decode XML document using charset %q", charset)
+		}
+	}
+	d.Strict = false
+
+	ret := []goImport{}
+	for {
+		t, err := d.RawToken()
+		if err != nil {
+			if errors.Is(err, io.EOF) || len(ret) > 0 {
+				return ret, nil
+			}
+			return nil, fmt.Errorf("parsing go-get metadata: %w", err)
+		}
+		if e, ok := t.(xml.StartElement); ok && strings.EqualFold(e.Name.Local, "body") {
+			return ret, nil
+		}
+		if e, ok := t.(xml.EndElement); ok && strings.EqualFold(e.Name.Local, "head") {
+			return ret, nil
+		}
+		e, ok := t.(xml.StartElement)
+		if !ok || !strings.EqualFold(e.Name.Local, "meta") {
+			continue
+		}
+
+		var name, content string
+		for _, a := range e.Attr {
+			switch strings.ToLower(a.Name.Local) {
+			case "name":
+				name = a.Value
+			case "content":
+				content = a.Value
+			}
+		}
+		if name != "go-import" {
+			continue
+		}
+
+		fields := strings.Fields(content)
+		if len(fields) < 3 || len(fields) > 4 {
+			continue
+		}
+		imp := goImport{Prefix: fields[0], VCS: fields[1], RepoRoot: fields[2]}
+		if len(fields) == 4 {
+			imp.SubDir = fields[3]
+		}
+		ret = append(ret, imp)
+	}
+}
+
+// matchGoImport returns the go-import declaration for the module path. When
+// several prefixes match, the longest one wins.
+func matchGoImport(imports []goImport, modulePath string) (*goImport, error) {
+	var match *goImport
+	for i := range imports {
+		imp := &imports[i]
+		if imp.VCS == "mod" {
+			continue
+		}
+		if modulePath != imp.Prefix && !strings.HasPrefix(modulePath, imp.Prefix+"/") {
+			continue
+		}
+		if match == nil || len(imp.Prefix) > len(match.Prefix) {
+			match = imp
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no go-import metadata found for %q", modulePath)
+	}
+	if match.VCS != ToolGit {
+		return nil, fmt.Errorf("module %q is hosted in a %s repository, only git is supported", modulePath, match.VCS)
+	}
+	return match, nil
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoImports(t *testing.T) {
+	t.Parallel()
+	imports, err := parseGoImports(strings.NewReader(`<!DOCTYPE html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+<meta name="go-import" content="example.com/mod git https://git.example.com/mod">
+<meta name="go-import" content="example.com/mono git https://git.example.com/mono sub/dir">
+<meta name="go-source" content="example.com/mod https://git.example.com/mod">
+</head>
+<body><meta name="go-import" content="ignored git https://example.com"></body>
+</html>`))
+	require.NoError(t, err)
+	require.Equal(t, []goImport{
+		{Prefix: "example.com/mod", VCS: "git", RepoRoot: "https://git.example.com/mod"},
+		{Prefix: "example.com/mono", VCS: "git", RepoRoot: "https://git.example.com/mono", SubDir: "sub/dir"},
+	}, imports)
+}
+
+func TestResolveGoModule(t *testing.T) {
+	t.Parallel()
+
+	var host string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("go-get") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/x/"):
+			parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/x/"), "/")
+			fmt.Fprintf(w, `<html><head><meta name="go-import" content="%s/x/%s git https://git.example.com/%s"></head></html>`, host, parts[0], parts[0])
+		case strings.HasPrefix(r.URL.Path, "/hg/"):
+			fmt.Fprintf(w, `<html><head><meta name="go-import" content="%s/hg hg https://hg.example.com/repo"></head></html>`, host)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	host = strings.TrimPrefix(srv.URL, "https://")
+	client := WithHttpClient(srv.Client())
+
+	for _, tc := range []struct {
+		name    string
+		module  string
+		expect  string
+		mustErr bool
+	}{
+		{"github", "github.com/example/test", "git+https://github.com/example/test", false},
+		{"github-nested", "github.com/example/test/tools/v2@v2.1.0", "git+https://github.com/example/test@tools/v2.1.0#tools", false},
+		{"codeberg", "codeberg.org/example/test@v1.0.0", "git+https://codeberg.org/example/test@v1.0.0", false},
+		{"sourcehut", "git.sr.ht/~user/test/sub@v0.3.0", "git+https://git.sr.ht/~user/test@sub/v0.3.0#sub", false},
+		{"meta", host + "/x/tools", "git+https://git.example.com/tools", false},
+		{"meta-version", host + "/x/tools@v0.1.0", "git+https://git.example.com/tools@v0.1.0", false},
+		{"meta-nested", host + "/x/tools/gopls@v0.2.0", "git+https://git.example.com/tools@gopls/v0.2.0#gopls", false},
+		{"meta-pseudo", host + "/x/tools@v0.0.0-20240101120000-25c779ba165d", "git+https://git.example.com/tools@25c779ba165d", false},
+		{"not-git", host + "/hg", "", true},
+		{"not-found", host + "/missing", "", true},
+		{"invalid", "notamodule", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			l, err := ResolveGoModule(tc.module, client)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, string(l))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// HashTree returns a digest of the subtree referenced by the locator in the
+// form algorithm:hex (ie sha256:8f43...). The digest covers the path, mode
+// and contents of every file so it changes if any of them does, while it
+// does not depend on the commit or on where the subtree lives in the
+// repository.
+//
+// The digest is computed over a listing with a line for each file, sorted
+// by path:
+//
+//	<git mode in octal> <hex digest of the contents> <path>\n
+//
+// where paths are relative to the locator subpath (for a single file, its
+// name) and symbolic links are hashed by their target. The algorithm is one
+// of sha1, sha256, sha384 or sha512. Directories are implied by the files,
+// the include/exclude filters and export-ignore options apply.
+func HashTree[T ~string](locator T, algorithm string, funcs ...fnOpt) (string, error) {
+	algorithm = strings.ToLower(algorithm)
+	newHash, ok := digestAlgorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+
+	cloned, entries, _, err := prepareArchive(Locator(locator), funcs...)
+	if err != nil {
+		return "", err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	subpath := strings.Trim(cloned.Components.SubPath, "/")
+	listing := newHash()
+	for i := range entries {
+		entry := &entries[i]
+		if entry.isDir() {
+			continue
+		}
+
+		r, _, err := openBlob(cloned, entry)
+		if err != nil {
+			return "", err
+		}
+		h := newHash()
+		_, err = io.Copy(h, r)
+		r.Close() //nolint:errcheck,gosec
+		if err != nil {
+			return "", fmt.Errorf("hashing %q: %w", entry.Path, err)
+		}
+
+		rel := entry.Path
+		switch {
+		case subpath == "":
+		case rel == subpath:
+			rel = path.Base(rel)
+		default:
+			rel = strings.TrimPrefix(rel, subpath+"/")
+		}
+		fmt.Fprintf(listing, "%s %s %s\n", entry.Mode, hex.EncodeToString(h.Sum(nil)), rel)
+	}
+	return algorithm + ":" + hex.EncodeToString(listing.Sum(nil)), nil
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashTree(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"a/one.txt":     "one",
+		"a/sub/two.txt": "two",
+		"b/one.txt":     "one",
+		"b/sub/two.txt": "two",
+		"c/one.txt":     "one",
+	})
+	second := commitTestFile(t, repoDir, "a/one.txt", "changed")
+
+	hash := func(t *testing.T, commit, subpath, algo string) string {
+		t.Helper()
+		digest, err := HashTree(fileLocator(repoDir, commit, subpath), algo, noAuth)
+		require.NoError(t, err)
+		return digest
+	}
+
+	// Known value for a single file tree
+	contents := sha256.Sum256([]byte("one"))
+	listing := sha256.Sum256(fmt.Appendf(nil, "0100644 %s one.txt\n", hex.EncodeToString(contents[:])))
+	require.Equal(t, "sha256:"+hex.EncodeToString(listing[:]), hash(t, first, "c", "sha256"))
+
+	// Equal trees hash the same regardless of their location or commit
+	require.Equal(t, hash(t, first, "a", "sha256"), hash(t, first, "b", "sha256"))
+	require.Equal(t, hash(t, first, "b", "sha256"), hash(t, second, "b", "sha256"))
+	require.NotEqual(t, hash(t, first, "a", "sha256"), hash(t, second, "a", "sha256"))
+	require.NotEqual(t, hash(t, first, "a", "sha256"), hash(t, first, "c", "sha256"))
+
+	require.Regexp(t, `^sha512:[0-9a-f]{128}$`, hash(t, first, "", "SHA512"))
+
+	_, err := HashTree(fileLocator(repoDir, first, "a"), "md5", noAuth)
+	require.Error(t, err)
+}
@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitAsOf walks the first parent history starting at tip and returns the
+// newest commit whose commit date is not after the specified date.
+func commitAsOf(repo *git.Repository, tip plumbing.Hash, date time.Time) (plumbing.Hash, error) {
+	commit, err := repo.CommitObject(tip)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("reading commit %s: %w", tip, err)
+	}
+
+	for {
+		if !commit.Committer.When.After(date) {
+			return commit.Hash, nil
+		}
+
+		if commit.NumParents() == 0 {
+			return plumbing.ZeroHash, fmt.Errorf("no commit found before %s", date.Format(time.RFC3339))
+		}
+
+		commit, err = commit.Parent(0)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("reading parent commit: %w", err)
+		}
+	}
+}
+
+// peelToCommit returns the commit an object points to. Tag objects are
+// followed until a commit is found, which handles tags pointing to other
+// (annotated) tags.
+func peelToCommit(repo *git.Repository, hash plumbing.Hash) (*object.Commit, error) {
+	for range 10 {
+		tag, err := repo.TagObject(hash)
+		if err != nil {
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				break
+			}
+			return nil, fmt.Errorf("reading tag object %s: %w", hash, err)
+		}
+		if tag.TargetType == plumbing.CommitObject {
+			hash = tag.Target
+			break
+		}
+		if tag.TargetType != plumbing.TagObject {
+			return nil, fmt.Errorf("tag %q points to a %s, not a commit", tag.Name, tag.TargetType)
+		}
+		hash = tag.Target
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", hash, err)
+	}
+	return commit, nil
+}
+
+// peelTagRef reads a tag reference from the repository and returns the
+// commit it points to.
+func peelTagRef(repo *git.Repository, tag string) (*object.Commit, error) {
+	ref, err := repo.Reference(plumbing.NewTagReferenceName(tag), true)
+	if err != nil {
+		return nil, fmt.Errorf("reading tag %q: %w", tag, err)
+	}
+	return peelToCommit(repo, ref.Hash())
+}
+
+// logCommits walks the history from tip in reverse chronological order and
+// returns the commits that changed subpath (all of them if it is empty),
+// up to limit commits if greater than zero. Merges are simplified as git
+// log does: if the path is the same as in one of the parents, the merge is
+// skipped and only that parent is followed. The walk stops at the shallow
+// boundary of shallow clones.
+func logCommits(repo *git.Repository, tip plumbing.Hash, subpath string, limit int) ([]*object.Commit, error) {
+	shallows, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil, fmt.Errorf("reading shallow commits: %w", err)
+	}
+	boundary := map[plumbing.Hash]struct{}{}
+	for _, h := range shallows {
+		boundary[h] = struct{}{}
+	}
+
+	start, err := repo.CommitObject(tip)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", tip, err)
+	}
+
+	ret := []*object.Commit{}
+	queue := []*object.Commit{start}
+	seen := map[plumbing.Hash]struct{}{tip: {}}
+	for len(queue) > 0 && (limit <= 0 || len(ret) < limit) {
+		// Pop the newest commit in the queue
+		newest := 0
+		for i := range queue {
+			if queue[i].Committer.When.After(queue[newest].Committer.When) {
+				newest = i
+			}
+		}
+		commit := queue[newest]
+		queue = append(queue[:newest], queue[newest+1:]...)
+
+		parents := []*object.Commit{}
+		if _, ok := boundary[commit.Hash]; !ok {
+			for _, h := range commit.ParentHashes {
+				parent, err := repo.CommitObject(h)
+				if err != nil {
+					return nil, fmt.Errorf("reading commit %s: %w", h, err)
+				}
+				parents = append(parents, parent)
+			}
+		}
+
+		show, follow, err := commitChangesPath(commit, parents, subpath)
+		if err != nil {
+			return nil, err
+		}
+		if show {
+			ret = append(ret, commit)
+		}
+		for _, p := range follow {
+			if _, ok := seen[p.Hash]; ok {
+				continue
+			}
+			seen[p.Hash] = struct{}{}
+			queue = append(queue, p)
+		}
+	}
+	return ret, nil
+}
+
+// commitChangesPath checks if a commit changed a path compared to its
+// parents and returns the parents to follow when walking the history.
+func commitChangesPath(commit *object.Commit, parents []*object.Commit, subpath string) (bool, []*object.Commit, error) {
+	if subpath == "" {
+		return true, parents, nil
+	}
+
+	hash, err := pathHash(commit, subpath)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(parents) == 0 {
+		return !hash.IsZero(), parents, nil
+	}
+	for _, p := range parents {
+		parentHash, err := pathHash(p, subpath)
+		if err != nil {
+			return false, nil, err
+		}
+		if parentHash == hash {
+			return false, []*object.Commit{p}, nil
+		}
+	}
+	return true, parents, nil
+}
+
+// pathHash returns the hash of the object at path p in the tree of a
+// commit or the zero hash if the path does not exist.
+func pathHash(commit *object.Commit, p string) (plumbing.Hash, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("reading tree of %s: %w", commit.Hash, err)
+	}
+	entry, err := tree.FindEntry(p)
+	if err != nil {
+		if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			return plumbing.ZeroHash, nil
+		}
+		return plumbing.ZeroHash, fmt.Errorf("looking up %q in %s: %w", p, commit.Hash, err)
+	}
+	return entry.Hash, nil
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateRefs(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{"data.txt": "initial"})
+	jan := commitTestFileAt(t, repoDir, "data.txt", "january", time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC))
+	feb := commitTestFileAt(t, repoDir, "data.txt", "february", time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC))
+	commitTestFileAt(t, repoDir, "data.txt", "march", time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC))
+
+	t.Run("parse", func(t *testing.T) {
+		t.Parallel()
+		c, err := Locator(fileLocator(repoDir, "master@{2025-02-01T00:00:00Z}", "")).Parse()
+		require.NoError(t, err)
+		require.Equal(t, "master", c.Branch)
+		require.Empty(t, c.Tag)
+		require.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), c.AsOf)
+	})
+
+	for ref, expect := range map[string]string{
+		"master@{2025-02-01}":            "january",
+		"master@{2025-02-10T12:00:00}":   "february",
+		"master@{2025-04-01}":            "march",
+		"refs/heads/master@{2025-03-01}": "february",
+	} {
+		t.Run(ref, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, CopyFile(fileLocator(repoDir, ref, "data.txt"), &buf, noAuth))
+			require.Equal(t, expect, buf.String())
+		})
+	}
+
+	t.Run("option", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(
+			fileLocator(repoDir, "master", "data.txt"), &buf, noAuth,
+			WithRefAsOf(time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC)),
+		))
+		require.Equal(t, "february", buf.String())
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		t.Parallel()
+		commit, err := Locator(fileLocator(repoDir, "master@{2025-01-31}", "")).Resolve(noAuth)
+		require.NoError(t, err)
+		require.Equal(t, jan, commit)
+
+		pinned, err := Locator(fileLocator(repoDir, "master@{2025-02-28}", "data.txt")).Pin(noAuth)
+		require.NoError(t, err)
+		require.Equal(t, Locator(fileLocator(repoDir, feb, "data.txt")), pinned)
+	})
+
+	t.Run("before-history", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.Error(t, CopyFile(fileLocator(repoDir, "master@{2000-01-01}", "data.txt"), &buf, noAuth))
+	})
+}
@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Person captures the identity and timestamp of a commit author, committer
+// or tagger.
+type Person struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// personFromSignature converts a go-git signature to a Person
+func personFromSignature(sig *object.Signature) *Person {
+	return &Person{
+		Name:  sig.Name,
+		Email: sig.Email,
+		When:  sig.When,
+	}
+}
+
+// TagInfo captures the metadata of a tag
+type TagInfo struct {
+	// Name is the short name of the tag (ie v1.0.0)
+	Name string
+
+	// Hash is the object the tag reference points to. For annotated tags
+	// this is the hash of the tag object, for lightweight tags it is the
+	// hash of the commit.
+	Hash string
+
+	// Annotated is true when the tag is an annotated tag object
+	Annotated bool
+
+	// Tagger and Message are only set for annotated tags
+	Tagger  *Person
+	Message string
+
+	// Commit is the commit the tag resolves to after peeling all tag objects
+	Commit string
+}
+
+// GetTagInfo fetches the tag referenced by the locator and returns its
+// metadata. For annotated tags, the tagger, date and message are returned
+// along with the peeled commit. Locators with version queries (ie @latest)
+// are resolved before fetching the tag.
+func GetTagInfo[T ~string](locator T, funcs ...fnOpt) (*TagInfo, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	l := Locator(locator)
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing locator: %w", err)
+	}
+
+	if isRefQuery(components.RefString) {
+		if err := resolveRefQuery(l, components, &opts, funcs...); err != nil {
+			return nil, fmt.Errorf("resolving version query: %w", err)
+		}
+	}
+
+	if components.Tag == "" {
+		return nil, errors.New("locator does not reference a tag")
+	}
+
+	refName := plumbing.NewTagReferenceName(components.Tag)
+	var cleanup func() error
+	repo, err := withCredentialPrompt(components, &opts, func() (*git.Repository, error) {
+		auth, err := prepareRemote(l, components, &opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := requireOnline(l, &opts); err != nil {
+			return nil, err
+		}
+
+		st, done, err := newStorage(&opts, components)
+		if err != nil {
+			return nil, err
+		}
+		repo, err := fetchRef(st, nil, components.fetchURL(), refName.String(), auth, 1)
+		if err != nil {
+			done() //nolint:errcheck,gosec
+			return nil, err
+		}
+		cleanup = done
+		return repo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup() //nolint:errcheck
+
+	ref, err := repo.Reference(refName, true)
+	if err != nil {
+		return nil, fmt.Errorf("reading tag reference: %w", err)
+	}
+
+	commit, err := peelToCommit(repo, ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	info := &TagInfo{
+		Name:   components.Tag,
+		Hash:   ref.Hash().String(),
+		Commit: commit.Hash.String(),
+	}
+
+	tag, err := repo.TagObject(ref.Hash())
+	switch {
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		// Lightweight tag
+	case err != nil:
+		return nil, fmt.Errorf("reading tag object: %w", err)
+	default:
+		info.Annotated = true
+		info.Tagger = personFromSignature(&tag.Tagger)
+		info.Message = tag.Message
+	}
+
+	return info, nil
+}
+
+// CommitInfo captures the metadata of a commit
+type CommitInfo struct {
+	Hash      string
+	Author    *Person
+	Committer *Person
+	Message   string
+
+	// Parents are the hashes of the commit parents
+	Parents []string
+
+	// TreeHash is the hash of the root tree of the commit
+	TreeHash string
+}
+
+// GetCommitInfo returns the metadata of the commit the locator resolves to.
+// The locator reference is resolved just as when cloning: branches and tags
+// point to the commit at their tip and locators without a ref to the remote
+// HEAD.
+func GetCommitInfo[T ~string](locator T, funcs ...fnOpt) (*CommitInfo, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+
+	return newCommitInfo(commit), nil
+}
+
+// newCommitInfo captures the metadata of a go-git commit
+func newCommitInfo(commit *object.Commit) *CommitInfo {
+	info := &CommitInfo{
+		Hash:      commit.Hash.String(),
+		Author:    personFromSignature(&commit.Author),
+		Committer: personFromSignature(&commit.Committer),
+		Message:   commit.Message,
+		Parents:   []string{},
+		TreeHash:  commit.TreeHash.String(),
+	}
+	for _, p := range commit.ParentHashes {
+		info.Parents = append(info.Parents, p.String())
+	}
+	return info
+}
+
+// Log returns the commits that changed the locator subpath, newest first,
+// starting at the commit the locator resolves to. Subpaths pointing to a
+// directory match the commits changing any file under it and locators
+// without a subpath return the whole history. A limit greater than zero
+// caps the number of commits returned.
+func Log[T ~string](locator T, limit int, funcs ...fnOpt) ([]CommitInfo, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+	if limit < 0 {
+		return nil, errors.New("log limit cannot be negative")
+	}
+
+	cloned, err := cloneHistory(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	commits, err := logCommits(
+		cloned.Repo, plumbing.NewHash(cloned.Commit),
+		strings.Trim(cloned.Components.SubPath, "/"), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("walking log: %w", err)
+	}
+
+	ret := make([]CommitInfo, 0, len(commits))
+	for _, commit := range commits {
+		ret = append(ret, *newCommitInfo(commit))
+	}
+	return ret, nil
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTagInfo(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	tagTestRepo(t, repoDir, "v1.0.0", first, "")
+	tagTestRepo(t, repoDir, "v1.0.1", first, "Release v1.0.1\n")
+
+	// Create a tag pointing to the annotated tag object
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	inner, err := repo.Tag("v1.0.1")
+	require.NoError(t, err)
+	tagTestRepo(t, repoDir, "v1.0.1-signed", inner.Hash().String(), "Tag of a tag\n")
+	commitTestFile(t, repoDir, "hello.txt", "bye")
+
+	t.Run("lightweight", func(t *testing.T) {
+		t.Parallel()
+		info, err := GetTagInfo(fileLocator(repoDir, "v1.0.0", ""), noAuth)
+		require.NoError(t, err)
+		require.Equal(t, "v1.0.0", info.Name)
+		require.False(t, info.Annotated)
+		require.Nil(t, info.Tagger)
+		require.Equal(t, first, info.Hash)
+		require.Equal(t, first, info.Commit)
+	})
+
+	t.Run("annotated", func(t *testing.T) {
+		t.Parallel()
+		info, err := GetTagInfo(fileLocator(repoDir, "refs/tags/v1.0.1", ""), noAuth)
+		require.NoError(t, err)
+		require.True(t, info.Annotated)
+		require.Equal(t, inner.Hash().String(), info.Hash)
+		require.Equal(t, first, info.Commit)
+		require.Equal(t, "Release v1.0.1\n", info.Message)
+		require.Equal(t, "test", info.Tagger.Name)
+		require.Equal(t, "test@test.com", info.Tagger.Email)
+	})
+
+	t.Run("tag-of-tag", func(t *testing.T) {
+		t.Parallel()
+		info, err := GetTagInfo(fileLocator(repoDir, "v1.0.1-signed", ""), noAuth)
+		require.NoError(t, err)
+		require.True(t, info.Annotated)
+		require.Equal(t, first, info.Commit)
+		require.Equal(t, "Tag of a tag\n", info.Message)
+
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(fileLocator(repoDir, "v1.0.1-signed", "hello.txt"), &buf, noAuth))
+		require.Equal(t, "hello", buf.String())
+	})
+
+	t.Run("query", func(t *testing.T) {
+		t.Parallel()
+		info, err := GetTagInfo(fileLocator(repoDir, "latest", ""), noAuth)
+		require.NoError(t, err)
+		require.Equal(t, "v1.0.1", info.Name)
+	})
+
+	t.Run("not-a-tag", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetTagInfo(fileLocator(repoDir, first, ""), noAuth)
+		require.Error(t, err)
+	})
+}
+
+func TestGetCommitInfo(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	when := time.Date(2025, 5, 1, 10, 30, 0, 0, time.UTC)
+	second := commitTestFileAt(t, repoDir, "hello.txt", "bye", when)
+
+	t.Run("branch", func(t *testing.T) {
+		t.Parallel()
+		info, err := GetCommitInfo(fileLocator(repoDir, "refs/heads/master", ""), noAuth)
+		require.NoError(t, err)
+		require.Equal(t, second, info.Hash)
+		require.Equal(t, []string{first}, info.Parents)
+		require.Equal(t, "update hello.txt", info.Message)
+		require.Equal(t, "test", info.Author.Name)
+		require.Equal(t, "test@test.com", info.Committer.Email)
+		require.True(t, when.Equal(info.Author.When))
+		require.Len(t, info.TreeHash, 40)
+	})
+
+	t.Run("root-commit", func(t *testing.T) {
+		t.Parallel()
+		info, err := GetCommitInfo(fileLocator(repoDir, first[:8], ""), noAuth)
+		require.NoError(t, err)
+		require.Equal(t, first, info.Hash)
+		require.Empty(t, info.Parents)
+		require.Equal(t, "test commit", info.Message)
+	})
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"README.md":     "readme",
+		"docs/index.md": "index",
+	})
+	second := commitTestFile(t, repoDir, "docs/index.md", "index v2")
+	third := commitTestFile(t, repoDir, "README.md", "readme v2")
+	fourth := commitTestFile(t, repoDir, "docs/guide.md", "guide")
+
+	hashes := func(commits []CommitInfo) []string {
+		ret := []string{}
+		for i := range commits {
+			ret = append(ret, commits[i].Hash)
+		}
+		return ret
+	}
+
+	for _, tc := range []struct {
+		name     string
+		ref      string
+		fragment string
+		limit    int
+		expected []string
+	}{
+		{"file", "refs/heads/master", "README.md", 0, []string{third, first}},
+		{"dir", "refs/heads/master", "docs", 0, []string{fourth, second, first}},
+		{"file-in-dir", "refs/heads/master", "docs/index.md", 0, []string{second, first}},
+		{"all", "refs/heads/master", "", 0, []string{fourth, third, second, first}},
+		{"limit", "refs/heads/master", "docs", 1, []string{fourth}},
+		{"from-commit", third, "docs", 0, []string{second, first}},
+		{"untouched", "refs/heads/master", "nope.txt", 0, []string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			commits, err := Log(fileLocator(repoDir, tc.ref, tc.fragment), tc.limit, noAuth)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, hashes(commits))
+		})
+	}
+
+	_, err := Log(fileLocator(repoDir, "refs/heads/master", ""), -1, noAuth)
+	require.Error(t, err)
+}
@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// overlayStorer reads a local repository in place. Objects and references
+// are read from its storage while everything written to the repository
+// (HEAD, the index of checkouts, submodules) is kept in memory, leaving the
+// repository on disk untouched.
+type overlayStorer struct {
+	storage.Storer
+	mem *memory.Storage
+
+	// removed are the references deleted in the overlay
+	removed map[plumbing.ReferenceName]struct{}
+
+	// config is set once the configuration is written to the overlay
+	config *config.Config
+}
+
+// newOverlayStorer wraps the storage of a local repository
+func newOverlayStorer(st storage.Storer) *overlayStorer {
+	return &overlayStorer{
+		Storer:  st,
+		mem:     memory.NewStorage(),
+		removed: map[plumbing.ReferenceName]struct{}{},
+	}
+}
+
+// SetEncodedObject writes an object to memory
+func (s *overlayStorer) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	return s.mem.SetEncodedObject(obj)
+}
+
+// EncodedObject reads an object from the repository or the overlay
+func (s *overlayStorer) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := s.Storer.EncodedObject(t, h)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return s.mem.EncodedObject(t, h)
+	}
+	return obj, err
+}
+
+// HasEncodedObject checks if the object is in the repository or the overlay
+func (s *overlayStorer) HasEncodedObject(h plumbing.Hash) error {
+	if err := s.Storer.HasEncodedObject(h); !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return err
+	}
+	return s.mem.HasEncodedObject(h)
+}
+
+// EncodedObjectSize returns the size of an object in the repository or the overlay
+func (s *overlayStorer) EncodedObjectSize(h plumbing.Hash) (int64, error) {
+	size, err := s.Storer.EncodedObjectSize(h)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return s.mem.EncodedObjectSize(h)
+	}
+	return size, err
+}
+
+// SetReference writes a reference to memory
+func (s *overlayStorer) SetReference(ref *plumbing.Reference) error {
+	delete(s.removed, ref.Name())
+	return s.mem.SetReference(ref)
+}
+
+// CheckAndSetReference writes a reference to memory if old is current
+func (s *overlayStorer) CheckAndSetReference(ref, old *plumbing.Reference) error {
+	if old != nil {
+		current, err := s.Reference(old.Name())
+		if err != nil {
+			return err
+		}
+		if current.Hash() != old.Hash() {
+			return storage.ErrReferenceHasChanged
+		}
+	}
+	return s.SetReference(ref)
+}
+
+// Reference reads a reference from the overlay or the repository
+func (s *overlayStorer) Reference(name plumbing.ReferenceName) (*plumbing.Reference, error) {
+	if _, ok := s.removed[name]; ok {
+		return nil, plumbing.ErrReferenceNotFound
+	}
+	ref, err := s.mem.Reference(name)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return s.Storer.Reference(name)
+	}
+	return ref, err
+}
+
+// IterReferences lists the references of the repository as changed in the overlay
+func (s *overlayStorer) IterReferences() (storer.ReferenceIter, error) {
+	refs := map[plumbing.ReferenceName]*plumbing.Reference{}
+	for _, st := range []storer.ReferenceStorer{s.Storer, s.mem} {
+		iter, err := st.IterReferences()
+		if err != nil {
+			return nil, err
+		}
+		if err := iter.ForEach(func(ref *plumbing.Reference) error {
+			refs[ref.Name()] = ref
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	list := make([]*plumbing.Reference, 0, len(refs))
+	for name, ref := range refs {
+		if _, ok := s.removed[name]; !ok {
+			list = append(list, ref)
+		}
+	}
+	return storer.NewReferenceSliceIter(list), nil
+}
+
+// RemoveReference deletes a reference in the overlay
+func (s *overlayStorer) RemoveReference(name plumbing.ReferenceName) error {
+	s.removed[name] = struct{}{}
+	return s.mem.RemoveReference(name)
+}
+
+// PackRefs is a no-op, the references of the repository are not modified
+func (s *overlayStorer) PackRefs() error {
+	return nil
+}
+
+// SetIndex writes the index to memory
+func (s *overlayStorer) SetIndex(idx *index.Index) error {
+	return s.mem.SetIndex(idx)
+}
+
+// Index reads the index from memory, checkouts in the overlay start empty
+func (s *overlayStorer) Index() (*index.Index, error) {
+	return s.mem.Index()
+}
+
+// SetShallow writes the shallow commits to memory
+func (s *overlayStorer) SetShallow(commits []plumbing.Hash) error {
+	return s.mem.SetShallow(commits)
+}
+
+// SetConfig writes the repository configuration to memory
+func (s *overlayStorer) SetConfig(cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	s.config = cfg
+	return nil
+}
+
+// Config reads the configuration from the overlay or the repository
+func (s *overlayStorer) Config() (*config.Config, error) {
+	if s.config != nil {
+		return s.config, nil
+	}
+	return s.Storer.Config()
+}
+
+// Module returns an in-memory storer for the submodules
+func (s *overlayStorer) Module(name string) (storage.Storer, error) {
+	return s.mem.Module(name)
+}
+
+// openInPlace opens the repository of a file:// locator where it is on
+// disk instead of cloning it, reading its objects directly from its object
+// database. The locator ref is resolved in the repository.
+//
+// Files are checked out to fsobj (if not nil) by the caller, except for
+// bare repositories read without a clone path: as they have no worktree,
+// fsobj is replaced with a filesystem reading the files straight from the
+// object store. The filesystem of the repository is returned.
+func openInPlace(l Locator, components *Components, opts *options, fsobj billy.Filesystem) (*git.Repository, plumbing.Hash, billy.Filesystem, error) {
+	local, err := openLocal(components.RepoPath)
+	if err != nil {
+		return nil, plumbing.ZeroHash, nil, fmt.Errorf("opening local repository: %w", err)
+	}
+
+	hash, err := cachedCommit(local, components, plumbing.HEAD)
+	if err != nil {
+		return nil, plumbing.ZeroHash, nil, fmt.Errorf("resolving %q in %s: %w", components.refName(), l, err)
+	}
+
+	_, err = local.Worktree()
+	if errors.Is(err, git.ErrIsBareRepository) && fsobj != nil && opts.ClonePath == "" && !opts.Submodules {
+		commit, err := local.CommitObject(hash)
+		if err != nil {
+			return nil, plumbing.ZeroHash, nil, fmt.Errorf("reading commit: %w", err)
+		}
+		if fsobj, err = newTreeFS(local.Storer, commit); err != nil {
+			return nil, plumbing.ZeroHash, nil, fmt.Errorf("reading commit tree: %w", err)
+		}
+	}
+
+	st := newOverlayStorer(local.Storer)
+	repo, err := git.Open(st, fsobj)
+	if err != nil {
+		return nil, plumbing.ZeroHash, nil, fmt.Errorf("opening local repository: %w", err)
+	}
+	if err := st.SetReference(plumbing.NewHashReference(plumbing.HEAD, hash)); err != nil {
+		return nil, plumbing.ZeroHash, nil, fmt.Errorf("storing HEAD: %w", err)
+	}
+	return repo, hash, fsobj, nil
+}
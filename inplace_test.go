@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenInPlace(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"README.md":     "v1",
+		"docs/guide.md": "guide",
+	})
+	second := commitTestFile(t, repoDir, "README.md", "v2")
+
+	headData, err := os.ReadFile(filepath.Join(repoDir, ".git", "HEAD"))
+	require.NoError(t, err)
+	indexData, err := os.ReadFile(filepath.Join(repoDir, ".git", "index"))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		ref      string
+		commit   string
+		expected string
+	}{
+		{"head", "", second, "v2"},
+		{"branch", "refs/heads/master", second, "v2"},
+		{"commit", first, first, "v1"},
+		{"abbreviated", abbrevHash(first), first, "v1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			opts := defaultOptions
+			require.NoError(t, noAuth(&opts))
+			require.NoError(t, WithOpenInPlace(true)(&opts))
+
+			cloned, err := cloneRepo(Locator(fileLocator(repoDir, tc.ref, "README.md")), &opts)
+			require.NoError(t, err)
+			defer cloned.Close() //nolint:errcheck
+			require.Equal(t, tc.commit, cloned.Commit)
+
+			// Objects are read from the repository, not copied
+			overlay, ok := cloned.Repo.Storer.(*overlayStorer)
+			require.True(t, ok)
+			require.Empty(t, overlay.mem.ObjectStorage.Objects)
+
+			var buf bytes.Buffer
+			require.NoError(t, CopyFile(fileLocator(repoDir, tc.ref, "README.md"), &buf, noAuth, WithOpenInPlace(true)))
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("download", func(t *testing.T) {
+		t.Parallel()
+		dest := t.TempDir()
+		require.NoError(t, Download(fileLocator(repoDir, first, "docs"), dest, noAuth, WithOpenInPlace(true)))
+		data, err := os.ReadFile(filepath.Join(dest, "docs", "guide.md"))
+		require.NoError(t, err)
+		require.Equal(t, "guide", string(data))
+	})
+
+	t.Run("unmodified", func(t *testing.T) {
+		t.Parallel()
+		dest := t.TempDir()
+		require.NoError(t, Download(fileLocator(repoDir, first, ""), dest, noAuth, WithOpenInPlace(true)))
+
+		// Checkouts do not touch the HEAD or the index of the repository
+		data, err := os.ReadFile(filepath.Join(repoDir, ".git", "HEAD"))
+		require.NoError(t, err)
+		require.Equal(t, headData, data)
+		data, err = os.ReadFile(filepath.Join(repoDir, ".git", "index"))
+		require.NoError(t, err)
+		require.Equal(t, indexData, data)
+	})
+}
+
+func TestOpenInPlaceBare(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"README.md":     "v1",
+		"docs/guide.md": "guide",
+	})
+	commitTestSymlinks(t, repoDir, map[string]string{"guide.md": "docs/guide.md"})
+	second := commitTestFile(t, repoDir, "README.md", "v2")
+
+	bareDir := filepath.Join(t.TempDir(), "bare.git")
+	_, err := git.PlainClone(bareDir, true, &git.CloneOptions{URL: "file://" + filepath.ToSlash(repoDir)})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		ref      string
+		commit   string
+		expected string
+	}{
+		{"head", "", second, "v2"},
+		{"branch", "refs/heads/master", second, "v2"},
+		{"commit", first, first, "v1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			opts := defaultOptions
+			require.NoError(t, noAuth(&opts))
+			require.NoError(t, WithOpenInPlace(true)(&opts))
+
+			cloned, err := cloneRepo(Locator(fileLocator(bareDir, tc.ref, "README.md")), &opts)
+			require.NoError(t, err)
+			defer cloned.Close() //nolint:errcheck
+			require.Equal(t, tc.commit, cloned.Commit)
+
+			// Files are read from the object store, nothing is checked out
+			_, ok := cloned.FS.(*treeFS)
+			require.True(t, ok)
+			overlay, ok := cloned.Repo.Storer.(*overlayStorer)
+			require.True(t, ok)
+			require.Empty(t, overlay.mem.ObjectStorage.Objects)
+
+			var buf bytes.Buffer
+			require.NoError(t, CopyFile(fileLocator(bareDir, tc.ref, "README.md"), &buf, noAuth, WithOpenInPlace(true)))
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("symlink", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(fileLocator(bareDir, "", "guide.md"), &buf, noAuth, WithOpenInPlace(true)))
+		require.Equal(t, "guide", buf.String())
+	})
+
+	t.Run("download", func(t *testing.T) {
+		t.Parallel()
+		dest := t.TempDir()
+		require.NoError(t, Download(fileLocator(bareDir, "", "docs"), dest, noAuth, WithOpenInPlace(true)))
+		data, err := os.ReadFile(filepath.Join(dest, "docs", "guide.md"))
+		require.NoError(t, err)
+		require.Equal(t, "guide", string(data))
+	})
+
+	t.Run("fs", func(t *testing.T) {
+		t.Parallel()
+		fsys, err := OpenFS(fileLocator(bareDir, "", "docs"), noAuth, WithOpenInPlace(true))
+		require.NoError(t, err)
+		data, err := fs.ReadFile(fsys, "guide.md")
+		require.NoError(t, err)
+		require.Equal(t, "guide", string(data))
+		entries, err := fs.ReadDir(fsys, ".")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+
+	t.Run("clone-path", func(t *testing.T) {
+		t.Parallel()
+		dest := filepath.Join(t.TempDir(), "checkout")
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(fileLocator(bareDir, first, "README.md"), &buf, noAuth, WithOpenInPlace(true), WithClonePath(dest)))
+		require.Equal(t, "v1", buf.String())
+		data, err := os.ReadFile(filepath.Join(dest, "docs", "guide.md"))
+		require.NoError(t, err)
+		require.Equal(t, "guide", string(data))
+	})
+}
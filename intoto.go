@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	intoto "github.com/in-toto/attestation/go/v1"
+)
+
+// Digest algorithm names used in in-toto digest sets
+const (
+	DigestAlgorithmSHA256    = "sha256"
+	DigestAlgorithmGitCommit = "gitCommit"
+	DigestAlgorithmGitTree   = "gitTree"
+	DigestAlgorithmGitBlob   = "gitBlob"
+)
+
+// GetResourceDescriptor fetches the locator and returns an in-toto
+// ResourceDescriptor describing it, ready to be used as an attestation
+// subject or material. The descriptor uri is the locator and its digest
+// records the resolved commit. When the locator has a subpath, the digest
+// also includes the git tree of directories or the git blob and sha256 of
+// files.
+func GetResourceDescriptor[T ~string](locator T, funcs ...fnOpt) (*intoto.ResourceDescriptor, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	rd := &intoto.ResourceDescriptor{
+		Uri: string(locator),
+		Digest: map[string]string{
+			DigestAlgorithmGitCommit: cloned.Commit,
+		},
+	}
+
+	subpath := strings.Trim(cloned.Components.SubPath, "/")
+	if subpath == "" {
+		rd.Name = path.Base(strings.TrimSuffix(cloned.Components.RepoPath, ".git"))
+		return rd, nil
+	}
+	rd.Name = subpath
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+	root, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit tree: %w", err)
+	}
+	entry, err := root.FindEntry(subpath)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %q: %w", subpath, err)
+	}
+
+	switch entry.Mode {
+	case filemode.Dir:
+		rd.Digest[DigestAlgorithmGitTree] = entry.Hash.String()
+	case filemode.Submodule:
+		return nil, fmt.Errorf("%q is a submodule", subpath)
+	default:
+		file, err := root.TreeEntryFile(entry)
+		if err != nil {
+			return nil, fmt.Errorf("reading file %q: %w", subpath, err)
+		}
+		r, err := file.Reader()
+		if err != nil {
+			return nil, fmt.Errorf("reading file %q: %w", subpath, err)
+		}
+		defer r.Close() //nolint:errcheck
+
+		h := sha256.New()
+		if _, err := io.Copy(h, r); err != nil {
+			return nil, fmt.Errorf("hashing file %q: %w", subpath, err)
+		}
+		rd.Digest[DigestAlgorithmGitBlob] = entry.Hash.String()
+		rd.Digest[DigestAlgorithmSHA256] = hex.EncodeToString(h.Sum(nil))
+	}
+	return rd, nil
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetResourceDescriptor(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"hello.txt":     "hello",
+		"docs/guide.md": "# Guide",
+	})
+
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		locator := fileLocator(repoDir, commitHash, "hello.txt")
+		rd, err := GetResourceDescriptor(locator, noAuth)
+		require.NoError(t, err)
+		require.NoError(t, rd.Validate())
+
+		sum := sha256.Sum256([]byte("hello"))
+		require.Equal(t, locator, rd.GetUri())
+		require.Equal(t, "hello.txt", rd.GetName())
+		require.Equal(t, map[string]string{
+			DigestAlgorithmGitCommit: commitHash,
+			DigestAlgorithmGitBlob:   plumbing.ComputeHash(plumbing.BlobObject, []byte("hello")).String(),
+			DigestAlgorithmSHA256:    hex.EncodeToString(sum[:]),
+		}, rd.GetDigest())
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		t.Parallel()
+		rd, err := GetResourceDescriptor(fileLocator(repoDir, commitHash, "docs/"), noAuth)
+		require.NoError(t, err)
+		require.Equal(t, "docs", rd.GetName())
+		require.Len(t, rd.GetDigest(), 2)
+		require.Contains(t, rd.GetDigest(), DigestAlgorithmGitTree)
+	})
+
+	t.Run("repository", func(t *testing.T) {
+		t.Parallel()
+		rd, err := GetResourceDescriptor(fileLocator(repoDir, commitHash, ""), noAuth)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{DigestAlgorithmGitCommit: commitHash}, rd.GetDigest())
+	})
+}
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/zalando/go-keyring"
+)
+
+// KeychainService is the service name of the credentials stored in the OS
+// keychain. Entries use the hostname as their account (or username) and
+// hold a token or a username:token pair.
+const KeychainService = "vcslocator"
+
+// keychainCredentials caches the credentials read from the keychain by
+// hostname, so it is not queried (or unlocked) on every operation. Hosts
+// without an entry are cached as nil.
+var keychainCredentials sync.Map
+
+// keychainAuth returns the credentials stored in the OS keychain (macOS
+// Keychain, Windows Credential Manager or the Secret Service of libsecret)
+// for a host. It returns nil when the host has no entry.
+func keychainAuth(host string) (transport.AuthMethod, error) {
+	host = strings.ToLower(host)
+	if v, ok := keychainCredentials.Load(host); ok {
+		if v == nil {
+			return nil, nil
+		}
+		return v.(*githttp.BasicAuth), nil //nolint:forcetypeassert
+	}
+
+	secret, err := keyring.Get(KeychainService, host)
+	switch {
+	case errors.Is(err, keyring.ErrNotFound):
+		keychainCredentials.Store(host, nil)
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("reading credentials of %s from the keychain: %w", host, err)
+	}
+
+	user, token, ok := strings.Cut(secret, ":")
+	if !ok {
+		user, token = tokenUsername, secret
+	}
+	auth := &githttp.BasicAuth{Username: user, Password: token}
+	keychainCredentials.Store(host, auth)
+	return auth, nil
+}
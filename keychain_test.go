@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeychainAuth(t *testing.T) {
+	keyring.MockInit()
+	require.NoError(t, keyring.Set(KeychainService, "token.keychain.example.com", "secret"))
+	require.NoError(t, keyring.Set(KeychainService, "user.keychain.example.com", "user:secret"))
+
+	for _, tc := range []struct {
+		name   string
+		host   string
+		funcs  []fnOpt
+		expect any
+	}{
+		{"token", "token.keychain.example.com", []fnOpt{WithKeychain(true)}, &githttp.BasicAuth{Username: "x-access-token", Password: "secret"}},
+		{"user-token", "USER.keychain.example.com", []fnOpt{WithKeychain(true)}, &githttp.BasicAuth{Username: "user", Password: "secret"}},
+		{"not-found", "none.keychain.example.com", []fnOpt{WithKeychain(true)}, nil},
+		{"disabled", "token.keychain.example.com", nil, nil},
+		{"configured", "token.keychain.example.com", []fnOpt{WithKeychain(true), WithHttpAuth("me", "pass")}, &githttp.BasicAuth{Username: "me", Password: "pass"}},
+		{"mirror", "token.keychain.example.com", []fnOpt{WithKeychain(true), WithMirrors(map[string]string{"token.keychain.example.com": "mirror.example.com"})}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := defaultOptions
+			for _, fn := range tc.funcs {
+				require.NoError(t, fn(&opts))
+			}
+			l := Locator("git+https://" + tc.host + "/org/repo")
+			c, err := l.Parse(tc.funcs...)
+			require.NoError(t, err)
+			auth, err := prepareRemote(l, c, &opts)
+			require.NoError(t, err)
+			if tc.expect == nil {
+				require.Nil(t, auth)
+				return
+			}
+			require.Equal(t, tc.expect, auth)
+		})
+	}
+}
@@ -0,0 +1,379 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// lfsPointerMaxSize is the size of the largest file git-lfs considers a
+// pointer, larger files are never parsed.
+const lfsPointerMaxSize = 1024
+
+// lfsSpecVersion is the first line of LFS pointer files
+const lfsSpecVersion = "version https://git-lfs.github.com/spec/v1"
+
+// lfsMediaType is the content type of the LFS batch API
+const lfsMediaType = "application/vnd.git-lfs+json"
+
+// lfsPointer captures the object referenced by an LFS pointer file
+type lfsPointer struct {
+	// OID is the hex encoded SHA-256 digest of the object
+	OID  string
+	Size int64
+}
+
+// parseLFSPointer parses the contents of an LFS pointer file. It returns
+// false if the data is not a valid pointer.
+func parseLFSPointer(data []byte) (*lfsPointer, bool) {
+	if len(data) > lfsPointerMaxSize || !bytes.HasPrefix(data, []byte(lfsSpecVersion+"\n")) {
+		return nil, false
+	}
+
+	p := &lfsPointer{Size: -1}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for _, line := range lines[1:] {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, false
+		}
+		switch key {
+		case "oid":
+			oid, ok := strings.CutPrefix(value, "sha256:")
+			if _, err := hex.DecodeString(oid); !ok || err != nil || len(oid) != 64 {
+				return nil, false
+			}
+			p.OID = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return nil, false
+			}
+			p.Size = size
+		}
+	}
+	if p.OID == "" || p.Size < 0 {
+		return nil, false
+	}
+	return p, true
+}
+
+// lfsClient downloads the LFS objects of a repository. Objects of remote
+// repositories are fetched through the LFS batch API, objects of local
+// repositories are read from their LFS storage directory.
+type lfsClient struct {
+	// endpoint is the URL of the LFS server of remote repositories
+	endpoint string
+
+	// localDir is the path of local repositories
+	localDir string
+
+	// authHost is the host of the repository remote. The git credentials
+	// are only sent to it, the LFS server set in .lfsconfig and the
+	// download URLs are chosen by the repository.
+	authHost string
+
+	auth    transport.AuthMethod
+	client  *http.Client
+	locator string
+	offline bool
+
+	// opts are checked against the hosts of the LFS requests and the size
+	// of the objects
+	opts options
+}
+
+// newLFSClient returns a client to download the LFS objects of a cloned
+// repository. The LFS server URL can be overridden in the .lfsconfig file
+// of the tree, otherwise it is derived from the repository URL just as
+// git-lfs does.
+func newLFSClient(cloned *clonedRepo, tree *object.Tree) (*lfsClient, error) {
+	c := &lfsClient{
+		auth:    cloned.auth,
+		client:  cloned.opts.httpClient(),
+		locator: cloned.Components.String(),
+		offline: cloned.opts.Offline,
+		opts:    cloned.opts,
+	}
+
+	endpoint, err := lfsConfigURL(tree)
+	if err != nil {
+		return nil, err
+	}
+	remote := cloned.Components.fetchURL()
+	if !strings.HasPrefix(remote, "file://") {
+		c.endpoint = remoteLFSEndpoint(remote)
+		if u, err := url.Parse(c.endpoint); err == nil {
+			c.authHost = u.Host
+		}
+	}
+	switch {
+	case endpoint != "":
+		c.endpoint = endpoint
+	case strings.HasPrefix(remote, "file://"):
+		c.localDir = strings.TrimPrefix(remote, "file://")
+	}
+	return c, nil
+}
+
+// remoteLFSEndpoint derives the URL of the LFS server from the repository
+// URL, just as git-lfs does
+func remoteLFSEndpoint(remote string) string {
+	endpoint := remote
+	// SSH remotes are served by the LFS server of the same host
+	if host, path, ok := strings.Cut(strings.TrimPrefix(remote, "git@"), ":"); ok && !strings.Contains(remote, "://") {
+		endpoint = fmt.Sprintf("https://%s/%s", host, strings.Trim(path, "/"))
+	}
+	if !strings.HasSuffix(endpoint, ".git") {
+		endpoint += ".git"
+	}
+	return endpoint + "/info/lfs"
+}
+
+// lfsConfigURL reads the LFS server URL from the .lfsconfig file of a tree.
+// It returns an empty string if it is not set.
+func lfsConfigURL(tree *object.Tree) (string, error) {
+	f, err := tree.File(".lfsconfig")
+	if errors.Is(err, object.ErrFileNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading .lfsconfig: %w", err)
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return "", fmt.Errorf("reading .lfsconfig: %w", err)
+	}
+	defer r.Close() //nolint:errcheck
+
+	cfg := formatcfg.New()
+	if err := formatcfg.NewDecoder(r).Decode(cfg); err != nil {
+		return "", fmt.Errorf("parsing .lfsconfig: %w", err)
+	}
+	return cfg.Section("lfs").Option("url"), nil
+}
+
+// peekedReader reads from a buffered reader and closes the stream beneath
+type peekedReader struct {
+	*bufio.Reader
+	io.Closer
+}
+
+// smudge returns the contents of the LFS object when the stream holds an
+// LFS pointer, along with the parsed pointer. Any other data is returned
+// unchanged and the pointer is nil.
+func (c *lfsClient) smudge(rc io.ReadCloser) (io.ReadCloser, *lfsPointer, error) {
+	br := bufio.NewReaderSize(rc, lfsPointerMaxSize+1)
+	head, err := br.Peek(lfsPointerMaxSize + 1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		rc.Close() //nolint:errcheck,gosec
+		return nil, nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	pointer, ok := parseLFSPointer(head)
+	if !ok {
+		return &peekedReader{Reader: br, Closer: rc}, nil, nil
+	}
+	rc.Close() //nolint:errcheck,gosec
+	r, err := c.open(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, pointer, nil
+}
+
+// open returns a reader of the object referenced by an LFS pointer. The
+// data is checked against the pointer digest and the file size limit as it
+// is read.
+func (c *lfsClient) open(p *lfsPointer) (io.ReadCloser, error) {
+	limit := c.opts.MaxFileSize
+	if limit > 0 && p.Size > limit {
+		return nil, &SizeLimitError{Kind: SizeLimitFile, Limit: limit, Size: p.Size, Object: p.OID}
+	}
+
+	var rc io.ReadCloser
+	var err error
+	if c.localDir != "" {
+		rc, err = c.openLocal(p)
+	} else {
+		rc, err = c.download(p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 {
+		rc = &sizeLimitReader{ReadCloser: rc, limit: limit, object: p.OID}
+	}
+	r, err := newDigestReader(rc, "sha256:"+p.OID, c.locator)
+	if err != nil {
+		rc.Close() //nolint:errcheck,gosec
+		return nil, err
+	}
+	return r, nil
+}
+
+// openLocal opens an LFS object from the storage of a local repository
+func (c *lfsClient) openLocal(p *lfsPointer) (io.ReadCloser, error) {
+	// Worktrees keep the objects in .git/lfs, bare repositories in lfs
+	for _, dir := range []string{filepath.Join(c.localDir, ".git"), c.localDir} {
+		f, err := os.Open(filepath.Join(dir, "lfs", "objects", p.OID[0:2], p.OID[2:4], p.OID))
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("opening LFS object: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("LFS object %s not found in %s", p.OID, c.localDir)
+}
+
+// lfsBatchRequest is the body of a batch API request
+type lfsBatchRequest struct {
+	Operation string           `json:"operation"`
+	Transfers []string         `json:"transfers"`
+	Objects   []lfsBatchObject `json:"objects"`
+}
+
+// lfsBatchObject is an object in the batch API requests and responses
+type lfsBatchObject struct {
+	OID     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions *struct {
+		Download *struct {
+			Href   string            `json:"href"`
+			Header map[string]string `json:"header"`
+		} `json:"download"`
+	} `json:"actions,omitempty"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// download fetches an LFS object from the server with the batch API
+func (c *lfsClient) download(p *lfsPointer) (io.ReadCloser, error) {
+	if c.offline {
+		return nil, &NotCachedError{Locator: c.locator, Revision: "LFS object " + p.OID}
+	}
+	body, err := json.Marshal(&lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   []lfsBatchObject{{OID: p.OID, Size: p.Size}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding LFS batch request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.endpoint, "/")+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating LFS batch request: %w", err)
+	}
+	if err := c.checkHost(req.URL); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	c.setAuth(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting LFS object: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("requesting LFS object: http status %d", resp.StatusCode)
+	}
+
+	batch := struct {
+		Objects []lfsBatchObject `json:"objects"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("decoding LFS batch response: %w", err)
+	}
+
+	for _, obj := range batch.Objects {
+		if obj.OID != p.OID {
+			continue
+		}
+		if obj.Error != nil {
+			return nil, fmt.Errorf("LFS object %s: %s (%d)", p.OID, obj.Error.Message, obj.Error.Code)
+		}
+		if obj.Actions == nil || obj.Actions.Download == nil {
+			return nil, fmt.Errorf("LFS server returned no download action for %s", p.OID)
+		}
+		return c.get(obj.Actions.Download.Href, obj.Actions.Download.Header)
+	}
+	return nil, fmt.Errorf("LFS object %s missing in batch response", p.OID)
+}
+
+// get downloads an object from the URL returned by the batch API
+func (c *lfsClient) get(href string, header map[string]string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, href, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating LFS download request: %w", err)
+	}
+	if err := c.checkHost(req.URL); err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	// Downloads from other hosts (ie storage buckets) carry their own
+	// credentials in the headers
+	if req.Header.Get("Authorization") == "" {
+		c.setAuth(req)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("downloading LFS object: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("downloading LFS object: http status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// checkHost runs the policy checks of the remotes on the host of an LFS
+// request
+func (c *lfsClient) checkHost(u *url.URL) error {
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("unsupported LFS URL scheme %q", u.Scheme)
+	}
+	l := Locator(c.locator)
+	if err := checkHostLists(l, u.Hostname(), &c.opts); err != nil {
+		return err
+	}
+	return c.opts.VCSPolicy.check(l, &Components{Tool: ToolGit, Transport: u.Scheme, Hostname: u.Hostname()})
+}
+
+// setAuth adds the git credentials to a request to the host of the
+// repository remote
+func (c *lfsClient) setAuth(req *http.Request) {
+	if c.authHost == "" || !strings.EqualFold(req.URL.Host, c.authHost) {
+		return
+	}
+	if a, ok := c.auth.(githttp.AuthMethod); ok {
+		a.SetAuth(req)
+	}
+}
@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+// lfsTestPointer returns the pointer file of an LFS object
+func lfsTestPointer(data string) (pointer, oid string) {
+	sum := sha256.Sum256([]byte(data))
+	oid = hex.EncodeToString(sum[:])
+	return fmt.Sprintf("%s\noid sha256:%s\nsize %d\n", lfsSpecVersion, oid, len(data)), oid
+}
+
+// storeTestLFSObject writes an object to the LFS storage of a test repo
+func storeTestLFSObject(t *testing.T, repoDir, oid, data string) {
+	t.Helper()
+	dir := filepath.Join(repoDir, ".git", "lfs", "objects", oid[0:2], oid[2:4])
+	require.NoError(t, os.MkdirAll(dir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, oid), []byte(data), 0o600))
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	t.Parallel()
+	pointer, oid := lfsTestPointer("hello")
+	for _, tc := range []struct {
+		name    string
+		data    string
+		isValid bool
+	}{
+		{"valid", pointer, true},
+		{"extra-keys", lfsSpecVersion + "\next-0-foo sha256:abc\noid sha256:" + oid + "\nsize 5\n", true},
+		{"no-version", "oid sha256:" + oid + "\nsize 5\n", false},
+		{"no-size", lfsSpecVersion + "\noid sha256:" + oid + "\n", false},
+		{"short-oid", lfsSpecVersion + "\noid sha256:abcd\nsize 5\n", false},
+		{"bad-size", lfsSpecVersion + "\noid sha256:" + oid + "\nsize five\n", false},
+		{"text", "hello world\n", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			p, ok := parseLFSPointer([]byte(tc.data))
+			require.Equal(t, tc.isValid, ok)
+			if tc.isValid {
+				require.Equal(t, oid, p.OID)
+				require.Equal(t, int64(5), p.Size)
+			}
+		})
+	}
+}
+
+func TestLFS(t *testing.T) {
+	t.Parallel()
+	noAuth := WithSystemCredentials(false)
+
+	pointer, oid := lfsTestPointer("large binary data")
+	missing, _ := lfsTestPointer("not stored")
+	corrupt, corruptOID := lfsTestPointer("original")
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		"data.bin":    pointer,
+		"missing.bin": missing,
+		"corrupt.bin": corrupt,
+		"plain.txt":   "plain",
+	})
+	storeTestLFSObject(t, repoDir, oid, "large binary data")
+	storeTestLFSObject(t, repoDir, corruptOID, "tampered")
+
+	for _, tc := range []struct {
+		name     string
+		fragment string
+		opts     []fnOpt
+		expected string
+		mustErr  bool
+	}{
+		{"object", "data.bin", nil, "large binary data", false},
+		{"pointer", "data.bin", []fnOpt{WithLFS(false)}, pointer, false},
+		{"plain", "plain.txt", nil, "plain", false},
+		{"missing", "missing.bin", nil, "", true},
+		{"corrupt", "corrupt.bin", nil, "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			err := CopyFile(fileLocator(repoDir, "refs/heads/master", tc.fragment), &buf, append(tc.opts, noAuth)...)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("download", func(t *testing.T) {
+		t.Parallel()
+		dest := filepath.Join(t.TempDir(), "out")
+		require.NoError(t, Download(
+			fileLocator(repoDir, "refs/heads/master", ""), dest, noAuth,
+			WithInclude("data.bin", "plain.txt"),
+		))
+		data, err := os.ReadFile(filepath.Join(dest, "data.bin"))
+		require.NoError(t, err)
+		require.Equal(t, "large binary data", string(data))
+	})
+}
+
+func TestLFSBatch(t *testing.T) {
+	t.Parallel()
+	const data = "object from the server"
+	_, oid := lfsTestPointer(data)
+
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("POST /repo.git/info/lfs/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != lfsMediaType {
+			http.Error(w, "bad accept header", http.StatusBadRequest)
+			return
+		}
+		req := lfsBatchRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := map[string]any{"objects": []map[string]any{}}
+		for _, o := range req.Objects {
+			obj := map[string]any{"oid": o.OID, "size": o.Size}
+			if o.OID == oid {
+				obj["actions"] = map[string]any{"download": map[string]any{
+					"href":   srv.URL + "/objects/" + o.OID,
+					"header": map[string]string{"X-Token": "secret"},
+				}}
+			} else {
+				obj["error"] = map[string]any{"code": 404, "message": "Object does not exist"}
+			}
+			resp["objects"] = append(resp["objects"].([]map[string]any), obj) //nolint:forcetypeassert
+		}
+		w.Header().Set("Content-Type", lfsMediaType)
+		json.NewEncoder(w).Encode(resp) //nolint:errcheck,gosec
+	})
+	mux.HandleFunc("GET /objects/{oid}", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if r.Header.Get("X-Token") != "secret" || !ok || user != "user" || pass != "pass" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, data) //nolint:errcheck,gosec
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	client := &lfsClient{
+		endpoint: srv.URL + "/repo.git/info/lfs",
+		authHost: srv.Listener.Addr().String(),
+		auth:     &githttp.BasicAuth{Username: "user", Password: "pass"},
+		client:   srv.Client(),
+	}
+
+	r, err := client.open(&lfsPointer{OID: oid, Size: int64(len(data))})
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, data, string(got))
+
+	_, missingOID := lfsTestPointer("missing")
+	_, err = client.open(&lfsPointer{OID: missingOID, Size: 7})
+	require.ErrorContains(t, err, "Object does not exist")
+
+	// Objects over the size limit are not requested, pointers understating
+	// the size fail when the limit is reached
+	var sle *SizeLimitError
+	client.opts.MaxFileSize = 10
+	_, err = client.open(&lfsPointer{OID: oid, Size: int64(len(data))})
+	require.ErrorAs(t, err, &sle)
+	r, err = client.open(&lfsPointer{OID: oid, Size: 4})
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	require.ErrorAs(t, err, &sle)
+	require.NoError(t, r.Close())
+}
+
+func TestLFSConfigCredentials(t *testing.T) {
+	t.Parallel()
+	var requests, withAuth atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "" {
+			withAuth.Add(1)
+		}
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		".lfsconfig": fmt.Sprintf("[lfs]\n\turl = %s/lfs\n", srv.URL),
+	})
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	tree, err := commit.Tree()
+	require.NoError(t, err)
+
+	_, oid := lfsTestPointer("data")
+	for _, tc := range []struct {
+		name     string
+		funcs    []fnOpt
+		requests int32
+	}{
+		// The repository chooses the LFS server, it does not get the
+		// credentials of the remote
+		{"other-host", nil, 1},
+		{"blocked-host", []fnOpt{WithBlockedHosts("127.0.0.1")}, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := defaultOptions
+			for _, fn := range tc.funcs {
+				require.NoError(t, fn(&opts))
+			}
+			requests.Store(0)
+			client, err := newLFSClient(&clonedRepo{
+				Components: &Components{Tool: ToolGit, Transport: TransportHTTPS, Hostname: "git.example.com", RepoPath: "/org/repo"},
+				auth:       &githttp.BasicAuth{Username: "user", Password: "pass"},
+				opts:       opts,
+			}, tree)
+			require.NoError(t, err)
+			_, err = client.open(&lfsPointer{OID: oid, Size: 4})
+			require.Error(t, err)
+			require.Equal(t, tc.requests, requests.Load())
+			require.Zero(t, withAuth.Load())
+		})
+	}
+}
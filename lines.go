@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// lineRangeRegex captures a line range at the end of the locator fragment
+// using the forge syntax, for example #main.go#L10-L42 or #main.go#L7.
+var lineRangeRegex = regexp.MustCompile(`^(.*)#L(\d+)(?:-L(\d+))?$`)
+
+// setSubPath parses the locator fragment into the subpath and the line
+// range, if any. When the fragment has no line range, the range set in the
+// options is used.
+func (c *Components) setSubPath(fragment string, opts *options) error {
+	c.SubPath = fragment
+	c.LineStart, c.LineEnd = opts.LineStart, opts.LineEnd
+
+	m := lineRangeRegex.FindStringSubmatch(fragment)
+	if m == nil {
+		return nil
+	}
+
+	start, err := strconv.Atoi(m[2])
+	if err != nil {
+		return fmt.Errorf("parsing line range start: %w", err)
+	}
+	end := 0
+	if m[3] != "" {
+		if end, err = strconv.Atoi(m[3]); err != nil {
+			return fmt.Errorf("parsing line range end: %w", err)
+		}
+	}
+	if err := validateLineRange(start, end); err != nil {
+		return err
+	}
+	c.SubPath = m[1]
+	c.LineStart, c.LineEnd = start, end
+	return nil
+}
+
+// validateLineRange checks the limits of a line range
+func validateLineRange(start, end int) error {
+	if start < 1 {
+		return fmt.Errorf("invalid line range start %d, lines are counted from 1", start)
+	}
+	if end != 0 && end < start {
+		return fmt.Errorf("invalid line range, end %d is before start %d", end, start)
+	}
+	return nil
+}
+
+// formatLineRange renders a line range as a locator fragment suffix
+func formatLineRange(start, end int) string {
+	if end == 0 || end == start {
+		return fmt.Sprintf("L%d", start)
+	}
+	return fmt.Sprintf("L%d-L%d", start, end)
+}
+
+// lineRangeWriter passes through to a writer only the lines in a range,
+// discarding the rest of the data written to it.
+type lineRangeWriter struct {
+	w          io.Writer
+	start, end int
+
+	// line is the number of the line being written
+	line int
+
+	// seen is set when the first line of the range is written
+	seen bool
+}
+
+// newLineRangeWriter wraps w to filter the data written to the lines from
+// start to end. If start is zero, w is returned unchanged.
+func newLineRangeWriter(w io.Writer, start, end int) io.Writer {
+	if start == 0 {
+		return w
+	}
+	if end == 0 {
+		end = start
+	}
+	return &lineRangeWriter{w: w, start: start, end: end, line: 1}
+}
+
+// Write implements io.Writer
+func (lw *lineRangeWriter) Write(p []byte) (int, error) {
+	rest := p
+	for len(rest) > 0 && lw.line <= lw.end {
+		chunk := rest
+		newline := false
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			chunk = rest[:i+1]
+			newline = true
+		}
+		if lw.line >= lw.start {
+			lw.seen = true
+			if _, err := lw.w.Write(chunk); err != nil {
+				return 0, err
+			}
+		}
+		if newline {
+			lw.line++
+		}
+		rest = rest[len(chunk):]
+	}
+	return len(p), nil
+}
+
+// done returns true once all the lines of the range have been written
+func (lw *lineRangeWriter) done() bool {
+	return lw.line > lw.end
+}
+
+// verify returns an error if the data written ended before the range
+func (lw *lineRangeWriter) verify() error {
+	if !lw.seen {
+		return fmt.Errorf("line %d is past the end of the file", lw.start)
+	}
+	return nil
+}
+
+// verifyLineRange checks the range of a writer returned by
+// newLineRangeWriter, it is a no-op for unfiltered writers.
+func verifyLineRange(w io.Writer) error {
+	if lw, ok := w.(*lineRangeWriter); ok {
+		return lw.verify()
+	}
+	return nil
+}
+
+// lineRangeReader reads only the lines in a range from a stream
+type lineRangeReader struct {
+	io.ReadCloser
+	buf   bytes.Buffer
+	chunk []byte
+	lw    *lineRangeWriter
+	err   error
+}
+
+// newLineRangeReader wraps rc to return only the lines from start to end.
+// If start is zero, rc is returned unchanged.
+func newLineRangeReader(rc io.ReadCloser, start, end int) io.ReadCloser {
+	if start == 0 {
+		return rc
+	}
+	lr := &lineRangeReader{ReadCloser: rc, chunk: make([]byte, 32*1024)}
+	lr.lw = newLineRangeWriter(&lr.buf, start, end).(*lineRangeWriter) //nolint:errcheck,forcetypeassert
+	return lr
+}
+
+// Read implements io.Reader
+func (lr *lineRangeReader) Read(p []byte) (int, error) {
+	for lr.buf.Len() == 0 && lr.err == nil {
+		if lr.lw.done() {
+			lr.err = io.EOF
+			break
+		}
+		n, err := lr.ReadCloser.Read(lr.chunk)
+		if n > 0 {
+			lr.lw.Write(lr.chunk[:n]) //nolint:errcheck,gosec // Writes to a buffer
+		}
+		if err != nil {
+			lr.err = err
+		}
+	}
+	if lr.buf.Len() > 0 {
+		return lr.buf.Read(p)
+	}
+	if errors.Is(lr.err, io.EOF) {
+		if err := lr.lw.verify(); err != nil {
+			return 0, err
+		}
+	}
+	return 0, lr.err
+}
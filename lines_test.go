@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLineRange(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name    string
+		locator string
+		opts    []fnOpt
+		subpath string
+		start   int
+		end     int
+		mustErr bool
+	}{
+		{"range", "git+https://github.com/example/test@main#pkg/foo.go#L10-L42", nil, "pkg/foo.go", 10, 42, false},
+		{"single", "git+https://github.com/example/test@main#pkg/foo.go#L7", nil, "pkg/foo.go", 7, 0, false},
+		{"none", "git+https://github.com/example/test@main#pkg/foo.go", nil, "pkg/foo.go", 0, 0, false},
+		{"slug", "example/test#README.md#L1-L2", nil, "README.md", 1, 2, false},
+		{"option", "example/test#README.md", []fnOpt{WithLineRange(3, 4)}, "README.md", 3, 4, false},
+		{"fragment-wins", "example/test#README.md#L1", []fnOpt{WithLineRange(3, 4)}, "README.md", 1, 0, false},
+		{"zero", "example/test#README.md#L0", nil, "", 0, 0, true},
+		{"reversed", "example/test#README.md#L9-L2", nil, "", 0, 0, true},
+		{"bad-option", "example/test#README.md", []fnOpt{WithLineRange(0, 4)}, "", 0, 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := Locator(tc.locator).Parse(tc.opts...)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.subpath, c.SubPath)
+			require.Equal(t, tc.start, c.LineStart)
+			require.Equal(t, tc.end, c.LineEnd)
+		})
+	}
+}
+
+func TestLineRangeString(t *testing.T) {
+	t.Parallel()
+	l := "git+https://github.com/example/test@main#pkg/foo.go#L10-L42"
+	c, err := Locator(l).Parse()
+	require.NoError(t, err)
+	require.Equal(t, l, c.String())
+}
+
+func TestLineRangeWriter(t *testing.T) {
+	t.Parallel()
+	data := "one\ntwo\nthree\nfour"
+	for _, tc := range []struct {
+		name       string
+		start, end int
+		expected   string
+		mustErr    bool
+	}{
+		{"all", 0, 0, data, false},
+		{"single", 2, 0, "two\n", false},
+		{"range", 2, 3, "two\nthree\n", false},
+		{"last-line", 3, 9, "three\nfour", false},
+		{"past-end", 5, 0, "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			// Write one byte at a time to check lines split across writes
+			var buf bytes.Buffer
+			w := newLineRangeWriter(&buf, tc.start, tc.end)
+			for i := range len(data) {
+				_, err := w.Write([]byte{data[i]})
+				require.NoError(t, err)
+			}
+			if tc.mustErr {
+				require.Error(t, verifyLineRange(w))
+				return
+			}
+			require.NoError(t, verifyLineRange(w))
+			require.Equal(t, tc.expected, buf.String())
+
+			r := newLineRangeReader(io.NopCloser(strings.NewReader(data)), tc.start, tc.end)
+			out, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(out))
+		})
+	}
+}
+
+func TestCopyFileLineRange(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(1)\n}\n",
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, CopyFile(fileLocator(repoDir, commit, "main.go#L5-L7"), &buf))
+	require.Equal(t, "func main() {\n\tfmt.Println(1)\n}\n", buf.String())
+
+	r, err := GetReader(fileLocator(repoDir, commit, "main.go#L1"))
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "package main\n", string(data))
+
+	group, err := GetGroup([]string{
+		fileLocator(repoDir, commit, "main.go#L3"),
+		fileLocator(repoDir, commit, "main.go"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "import \"fmt\"\n", string(group[0]))
+	require.Len(t, group[1], 60)
+
+	err = CopyFile(fileLocator(repoDir, commit, "main.go#L20"), io.Discard)
+	require.Error(t, err)
+}
@@ -14,21 +14,25 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/helper/iofs"
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
 	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 const (
-	sha1Pattern      = "^[a-f0-9]{40}$"
-	sha1ShortPattern = "^[a-f0-9]{7}$"
+	sha1Pattern = "^[a-f0-9]{40}$"
+
+	// sha1ShortPattern matches abbreviated commit hashes. Like git, we
+	// accept anything from 7 hex characters up to the full hash length.
+	sha1ShortPattern = "^[a-f0-9]{7,40}$"
 
 	// Supported transport strings
 	TransportSSH   = "ssh"
@@ -38,7 +42,13 @@ const (
 	ToolGit = "git"
 )
 
-var sha1Regex, sha1ShortRegex *regexp.Regexp
+// sha1Regex and sha1ShortRegex match full and abbreviated commit hashes.
+// They are compiled when the package loads so concurrent parsers can
+// share them.
+var (
+	sha1Regex      = regexp.MustCompile(sha1Pattern)
+	sha1ShortRegex = regexp.MustCompile(sha1ShortPattern)
+)
 
 // Locator is a type that wraps a VCS locator string to add functionality to it.
 type Locator string
@@ -82,12 +92,12 @@ func (l Locator) LocalPath(funcs ...fnOpt) (string, error) {
 
 const slugRegexPattern = `^[-A-Za-z0-9_]+/[-A-Za-z0-9_]+$`
 
-var slugRegex *regexp.Regexp
+// slugRegex matches the owner/repo slugs of GitHub repositories
+var slugRegex = regexp.MustCompile(slugRegexPattern)
 
-// Parse a VCS locator and returns its components
+// Parse a VCS locator and returns its components. Locators that cannot be
+// parsed return a *ParseError.
 func (l Locator) Parse(funcs ...fnOpt) (*Components, error) {
-	// For reference, the format is:
-	// <vcs_tool>+<transport>://<host_name>[/<path_to_repository>][@<revision_tag_or_branch>][#<sub_path>]
 	opts := defaultOptions
 	for _, fn := range funcs {
 		if err := fn(&opts); err != nil {
@@ -95,6 +105,17 @@ func (l Locator) Parse(funcs ...fnOpt) (*Components, error) {
 		}
 	}
 
+	c, err := parseResults.parse(l, &opts)
+	if err != nil {
+		return nil, &ParseError{Locator: string(l), Err: err}
+	}
+	return c, nil
+}
+
+// parse splits the locator in its components
+func (l Locator) parse(opts *options) (*Components, error) {
+	// For reference, the format is:
+	// <vcs_tool>+<transport>://<host_name>[/<path_to_repository>][@<revision_tag_or_branch>][#<sub_path>]
 	if l == "" {
 		return nil, errors.New("locator is an empty string")
 	}
@@ -111,26 +132,25 @@ func (l Locator) Parse(funcs ...fnOpt) (*Components, error) {
 	}
 
 	// Here, we detect if we are dealing with a github repo slug:
-	if slugRegex == nil {
-		slugRegex = regexp.MustCompile(slugRegexPattern)
-	}
 	// .. we ONLY treat is a such if there is no hostname, no scheme and....
 	if u.Hostname() == "" && u.Scheme == "" && u.Path != "" {
 		path, ref, _ := strings.Cut(u.Path, "@")
 		// ... we have a path that matches the slug regex (org/repo)
 		if slugRegex.MatchString(path) {
-			tag, branch, commitSha := parseRefString(ref, &opts)
-			return &Components{
+			c := &Components{
 				Tool:      "git",
 				Transport: "https",
 				Hostname:  "github.com",
 				RepoPath:  path,
-				RefString: ref,
-				Tag:       tag,
-				Branch:    branch,
-				Commit:    commitSha,
-				SubPath:   u.Fragment,
-			}, nil
+			}
+			if err := c.setRef(ref, opts); err != nil {
+				return nil, err
+			}
+			if err := c.setSubPath(u.Fragment, opts); err != nil {
+				return nil, err
+			}
+			c.setMirror(opts)
+			return c, nil
 		}
 	}
 
@@ -153,7 +173,6 @@ func (l Locator) Parse(funcs ...fnOpt) (*Components, error) {
 		tool = ""
 	}
 
-	tag, branch, commitSha := parseRefString(ref, &opts)
 	hostname := u.Hostname()
 
 	// If there is a hostname in a file URI, prepend it to the path
@@ -170,17 +189,81 @@ func (l Locator) Parse(funcs ...fnOpt) (*Components, error) {
 		return nil, fmt.Errorf("unable to parse path from file:// locator")
 	}
 
-	return &Components{
+	c := &Components{
 		Tool:      tool,
 		Transport: transp,
 		Hostname:  hostname,
 		RepoPath:  path,
-		RefString: ref,
-		Tag:       tag,
-		Branch:    branch,
-		Commit:    commitSha,
-		SubPath:   u.Fragment,
-	}, nil
+	}
+	if err := c.setRef(ref, opts); err != nil {
+		return nil, err
+	}
+	if err := c.setSubPath(u.Fragment, opts); err != nil {
+		return nil, err
+	}
+	c.setMirror(opts)
+	return c, nil
+}
+
+// refDateRegex captures the date in refs using the git reflog syntax,
+// for example main@{2025-01-01}.
+var refDateRegex = regexp.MustCompile(`^(.*)@\{([^}]+)\}$`)
+
+// refDateFormats are the date formats supported in date refs
+var refDateFormats = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// setRef parses the reference string and sets the ref fields in the
+// components. A date suffix (ie main@{2025-01-01}) in the ref is parsed
+// into the AsOf field.
+func (c *Components) setRef(ref string, opts *options) error {
+	c.RefString = ref
+
+	name := ref
+	if m := refDateRegex.FindStringSubmatch(ref); m != nil {
+		name = m[1]
+		asOf, err := parseRefDate(m[2])
+		if err != nil {
+			return err
+		}
+		c.AsOf = asOf
+	} else if !opts.RefAsOf.IsZero() {
+		c.AsOf = opts.RefAsOf
+	}
+
+	// Expand the shorthand code review refs (ie pull/123)
+	if expanded := expandReviewRef(name); expanded != name {
+		c.RefString = expanded + strings.TrimPrefix(ref, name)
+		name = expanded
+	}
+
+	c.Tag, c.Branch, c.Commit = parseRefString(name, opts)
+
+	// Tags are immutable so dates only make sense applied to branches. When
+	// a date is specified, we treat bare names as branches.
+	if !c.AsOf.IsZero() && c.Tag != "" && !strings.HasPrefix(name, "refs/tags/") {
+		c.Branch = c.Tag
+		c.Tag = ""
+	}
+	return nil
+}
+
+// refName returns the ref string without the date suffix
+func (c *Components) refName() string {
+	if m := refDateRegex.FindStringSubmatch(c.RefString); m != nil {
+		return m[1]
+	}
+	return c.RefString
+}
+
+// parseRefDate parses the date of a date ref. Dates without a timezone are
+// interpreted as UTC.
+func parseRefDate(s string) (time.Time, error) {
+	for _, format := range refDateFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse date in ref: %q", s)
 }
 
 // parseRefString parses a reference string and tries to determine if its a
@@ -188,11 +271,6 @@ func (l Locator) Parse(funcs ...fnOpt) (*Components, error) {
 //
 //	// TODO(puerco): Ensure this follows `man gitrevisions` > SPECIFYING REVISIONS
 func parseRefString(ref string, opts *options) (tag, branch, commitSha string) {
-	if sha1Regex == nil || sha1ShortRegex == nil {
-		sha1Regex = regexp.MustCompile(sha1Pattern)
-		sha1ShortRegex = regexp.MustCompile(sha1ShortPattern)
-	}
-
 	// If the ref looks like a commit, we treat it as such. Other reference
 	// types can be addressed by specifying the full path string (ie refs/tags/XX).
 	if sha1Regex.MatchString(ref) || sha1ShortRegex.MatchString(ref) {
@@ -222,9 +300,128 @@ func CloneRepository[T ~string](locator T, funcs ...fnOpt) (fs.FS, error) {
 		}
 	}
 
-	// Create the locator and parse
-	l := Locator(locator)
-	components, err := l.Parse()
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	return iofs.New(cloned.FS), nil
+}
+
+// OpenFS clones the repository referenced by the locator and returns its
+// worktree as a filesystem rooted at the locator subpath. Locators without a
+// subpath return the whole worktree. The subpath must be a directory.
+func OpenFS[T ~string](locator T, funcs ...fnOpt) (fs.FS, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	fsys := iofs.New(cloned.FS)
+	subpath := strings.Trim(cloned.Components.SubPath, "/")
+	if subpath == "" {
+		return fsys, nil
+	}
+
+	info, err := fs.Stat(fsys, subpath)
+	if err != nil {
+		return nil, fmt.Errorf("opening subpath: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("subpath %q is not a directory", subpath)
+	}
+	return fs.Sub(fsys, subpath)
+}
+
+// clonedRepo captures a repository cloned from a locator
+type clonedRepo struct {
+	Repo       *git.Repository
+	FS         billy.Filesystem
+	Components *Components
+
+	// Commit is the full hash of the commit the locator resolved to and
+	// which is checked out in the filesystem.
+	Commit string
+
+	// submodules holds the trees of the submodules checked out in the
+	// filesystem, keyed by their path.
+	submodules map[string]*object.Tree
+
+	// opts and funcs are the options the repository was cloned with,
+	// used to clone its submodules.
+	opts  options
+	funcs []fnOpt
+
+	// auth is the method used to authenticate to the remote
+	auth transport.AuthMethod
+
+	// cleanup removes the on-disk storage of the repository
+	cleanup func() error
+}
+
+// Close releases the storage of the cloned repository. The filesystem
+// remains usable after closing it.
+func (c *clonedRepo) Close() error {
+	if c.cleanup == nil {
+		return nil
+	}
+	return c.cleanup()
+}
+
+// tree returns the tree of the cloned commit. Paths in the checked out
+// submodules are looked up in their trees.
+func (c *clonedRepo) tree() (*repoTree, error) {
+	commit, err := c.Repo.CommitObject(plumbing.NewHash(c.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit tree: %w", err)
+	}
+	return &repoTree{Tree: tree, submodules: c.submodules}, nil
+}
+
+// cloneHistory clones the repository referenced by a locator like
+// cloneRepo, along with the history of the commit checked out. Pinned
+// commits are otherwise fetched alone, which is not enough to walk the
+// history.
+func cloneHistory(l Locator, opts *options, funcs ...fnOpt) (*clonedRepo, error) {
+	opts.fullHistory = true
+	return cloneRepo(l, opts, funcs...)
+}
+
+// cloneRepo clones the repository referenced by a locator and checks out the
+// commit its reference resolves to. When the remote asks for credentials,
+// the user is prompted for them and the clone is tried again.
+func cloneRepo(l Locator, opts *options, funcs ...fnOpt) (*clonedRepo, error) {
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing locator: %w", err)
+	}
+
+	// Failed clones leave their git directory in the clone path, they
+	// can't be tried again
+	if opts.DiskStorage && opts.ClonePath != "" {
+		return cloneRepoOnce(l, opts, funcs...)
+	}
+	return withCredentialPrompt(components, opts, func() (*clonedRepo, error) {
+		return cloneRepoOnce(l, opts, funcs...)
+	})
+}
+
+// cloneRepoOnce makes a single attempt to clone the repository of a locator
+func cloneRepoOnce(l Locator, opts *options, funcs ...fnOpt) (*clonedRepo, error) {
+	components, err := l.Parse(funcs...)
 	if err != nil {
 		return nil, fmt.Errorf("parsing locator: %w", err)
 	}
@@ -233,6 +430,13 @@ func CloneRepository[T ~string](locator T, funcs ...fnOpt) (fs.FS, error) {
 		return nil, errors.New("only git locators are supported for cloning")
 	}
 
+	// Version queries (latest, v1, ^1.2) are resolved to a tag before cloning
+	if isRefQuery(components.RefString) {
+		if err := resolveRefQuery(l, components, opts, funcs...); err != nil {
+			return nil, fmt.Errorf("resolving version query: %w", err)
+		}
+	}
+
 	// Branches and tags are safe to fetch when cloning. This is not the case
 	// of notes, for example so we only pass a reference to clone if we're
 	// dealing with a brach or tag.
@@ -244,32 +448,30 @@ func CloneRepository[T ~string](locator T, funcs ...fnOpt) (fs.FS, error) {
 		reference = plumbing.NewTagReferenceName(components.Tag)
 	}
 
+	// Without a worktree, the repository is cloned bare
 	var fsobj billy.Filesystem
-	if opts.ClonePath == "" {
-		fsobj = memfs.New()
-	} else {
+	switch {
+	case opts.ClonePath != "":
 		fsobj = osfs.New(opts.ClonePath)
+	case !opts.noCheckout:
+		fsobj = memfs.New()
 	}
 
-	// Handle cloning from repos with file: transport.
-	// We pass the full file:// URL to go-git so it uses local transport.
-	// Passing a bare path can cause go-git to misinterpret it (e.g. on
-	// Windows, D:/path looks like an SCP-style SSH URL host:path).
-	repourl := components.RepoURL()
-	if components.Transport == "file" {
-		repourl = "file://" + components.RepoPath
-	}
+	repourl := components.fetchURL()
 
-	var auth transport.AuthMethod
-	if opts.ReadCredentials && components.Transport != TransportFile {
-		auth, err = GetAuthMethod(l)
-		if err != nil {
-			return nil, fmt.Errorf("getting git auth method: %w", err)
-		}
+	// Locators with a subpath only check out its files, clones are
+	// fetched without a checkout and checked out once the subpath is known.
+	sparse := sparseCheckout(components, opts)
+
+	auth, err := prepareRemote(l, components, opts)
+	if err != nil {
+		return nil, err
 	}
+	opts.progressf("fetching %s\n", repourl)
 
 	// When no branch or tag was requested but we have a ref to resolve
-	// ourselves (e.g. for git notes), we don't need the default branch at all.
+	// ourselves (e.g. for git notes or pull requests), we don't need the
+	// default branch at all.
 	//
 	// Cloning it (even shallow) transfers the entire worktree at HEAD which
 	// is very expensive on large repos.
@@ -278,38 +480,139 @@ func CloneRepository[T ~string](locator T, funcs ...fnOpt) (fs.FS, error) {
 	// ref, then resolve and check out the commit it points.
 	resolveRefLater := reference == "" && components.Commit == "" && components.RefString != ""
 
-	var repo *git.Repository
-	if resolveRefLater {
-		repo, err = git.Init(memory.NewStorage(), fsobj)
+	// Date refs need the history of the ref to find the commit
+	depth := 1
+	if !components.AsOf.IsZero() {
+		depth = 0
+	}
+
+	// Repositories from previous downloads are updated in their storage
+	st, cleanup := opts.refreshStorer, func() error { return nil }
+	if st != nil {
+		st = wrapStorage(st, opts)
+	} else if st, cleanup, err = newStorage(opts, components); err != nil {
+		return nil, err
+	}
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			cleanup() //nolint:errcheck,gosec
+		}
+	}()
+
+	// Objects in a local reference repository are not fetched again
+	if opts.ReferenceRepo != "" {
+		ref, err := newReferenceStorer(st, opts.ReferenceRepo)
 		if err != nil {
-			return nil, fmt.Errorf("initializing repo: %w", err)
+			return nil, err
 		}
+		if err := ref.writeAlternates(st); err != nil {
+			return nil, err
+		}
+		st = ref
+	}
 
-		if _, err = repo.CreateRemote(&config.RemoteConfig{
-			Name: "origin",
-			URLs: []string{repourl},
-		}); err != nil {
-			return nil, fmt.Errorf("creating remote: %w", err)
+	// In-memory worktrees of clones under a memory budget are checked out
+	// once we know if the repository was moved to disk.
+	spill := findSpillStorer(st)
+	deferCheckout := sparse || (spill != nil && opts.ClonePath == "" && fsobj != nil)
+
+	var repo *git.Repository
+	var tip plumbing.Hash
+
+	// Local repositories opened in place and offline clones resolve the
+	// locator ref themselves
+	resolved := false
+	switch {
+	case opts.OpenInPlace && components.Transport == TransportFile && opts.refreshStorer == nil:
+		if repo, tip, fsobj, err = openInPlace(l, components, opts, fsobj); err != nil {
+			return nil, err
+		}
+		resolved = true
+	case opts.Offline:
+		// Offline clones are served from the local copies of the repository
+		if repo, tip, err = offlineClone(l, components, opts, st, fsobj); err != nil {
+			return nil, err
 		}
+		resolved = true
+	}
+
+	// Pinned commits are fetched directly when the remote allows it instead
+	// of cloning a branch and expecting the commit to be reachable from it.
+	if repo == nil && opts.refreshStorer == nil && !opts.fullHistory && components.AsOf.IsZero() && plumbing.IsHash(components.Commit) {
+		var packDepth packp.Depth
+		switch {
+		case !opts.ShallowSince.IsZero():
+			packDepth = packp.DepthSince(opts.ShallowSince)
+		case opts.ReferenceRepo == "":
+			packDepth = packp.DepthCommits(1)
+		}
+		var filter packp.Filter
+		if opts.PartialClone {
+			filter = packp.FilterBlobNone()
+		}
+		repo, err = commitClone(st, fsobj, repourl, plumbing.NewHash(components.Commit), auth, packDepth, filter, opts.Progress)
+		if err != nil && !errors.Is(err, errWantNotAllowed) {
+			return nil, err
+		}
+	}
 
+	switch {
+	case repo != nil:
+		// The pinned commit was fetched by hash or read locally
+	case opts.refreshStorer != nil:
+		// Update a repository from a previous download
+		remoteRef := reference.String()
+		refreshDepth := 0
+		if resolveRefLater {
+			remoteRef = components.refName()
+			refreshDepth = depth
+		}
+		repo, err = refreshRepo(st, fsobj, remoteRef, auth, refreshDepth)
+		if err != nil {
+			return nil, err
+		}
+	case opts.PartialClone || !opts.ShallowSince.IsZero() || opts.ReferenceRepo != "":
+		// Partial clones fetch the commits and trees, blobs are fetched
+		// when read. Pinned commits need the history of the ref. Clones
+		// with a reference repository send its refs as haves.
+		remoteRef := reference.String()
+		if resolveRefLater {
+			remoteRef = components.refName()
+		}
+		var packDepth packp.Depth
+		switch {
+		case !opts.ShallowSince.IsZero():
+			packDepth = packp.DepthSince(opts.ShallowSince)
+		case opts.ReferenceRepo != "":
+			// Remotes send the whole tree of shallow fetches, the history
+			// missing in the reference is usually cheaper
+		case components.Commit == "":
+			packDepth = packp.DepthCommits(depth)
+		}
+		var filter packp.Filter
+		if opts.PartialClone {
+			filter = packp.FilterBlobNone()
+		}
+		repo, tip, err = packClone(st, fsobj, repourl, remoteRef, auth, packDepth, filter, opts.Progress)
+		if err != nil {
+			return nil, err
+		}
+	case resolveRefLater:
 		// Fetch only the target ref (e.g. refs/notes/commits).
-		if err = repo.Fetch(&git.FetchOptions{
-			Auth:  auth,
-			Depth: 1,
-			RefSpecs: []config.RefSpec{
-				config.RefSpec(fmt.Sprintf("%s:%s", components.RefString, components.RefString)),
-			},
-		}); err != nil {
-			return nil, fmt.Errorf("fetching ref %q: %w", components.RefString, err)
+		repo, err = fetchRef(st, fsobj, repourl, components.refName(), auth, depth)
+		if err != nil {
+			return nil, err
 		}
-	} else {
+	default:
 		// Make a clone of the repo to memory
-		repo, err = git.Clone(memory.NewStorage(), fsobj, &git.CloneOptions{
-			URL:  repourl,
-			Auth: auth,
-			// Progress:      os.Stdout,
+		repo, err = git.Clone(st, fsobj, &git.CloneOptions{
+			URL:           repourl,
+			Auth:          auth,
+			Progress:      opts.Progress,
 			ReferenceName: reference,
 			SingleBranch:  true,
+			NoCheckout:    deferCheckout,
 			// RecurseSubmodules: 0,
 			// ShallowSubmodules: false,
 		})
@@ -319,11 +622,16 @@ func CloneRepository[T ~string](locator T, funcs ...fnOpt) (fs.FS, error) {
 	}
 
 	commitHash := components.Commit
+	if !tip.IsZero() && (commitHash == "" || resolved) {
+		// Partial clones are not checked out when fetched. Local and
+		// offline clones already resolved the locator ref.
+		commitHash = tip.String()
+	}
 	// Resolve the ref we fetched ourselves (eg git notes) to a commit hash.
-	if resolveRefLater {
-		ref, err := repo.Reference(plumbing.ReferenceName(components.RefString), true)
+	if resolveRefLater && !resolved {
+		ref, err := repo.Reference(plumbing.ReferenceName(components.refName()), true)
 		if err != nil {
-			return nil, fmt.Errorf("resolving reference %q: %w", components.RefString, err)
+			return nil, fmt.Errorf("resolving reference %q: %w", components.refName(), err)
 		}
 
 		hach, err := repo.ResolveRevision(plumbing.Revision(ref.Name().String()))
@@ -333,21 +641,124 @@ func CloneRepository[T ~string](locator T, funcs ...fnOpt) (fs.FS, error) {
 		commitHash = hach.String()
 	}
 
-	// If a revision was specified, check it out
-	if commitHash != "" {
+	// Abbreviated hashes need to be expanded to the full commit hash
+	// before we can check them out.
+	if commitHash != "" && !sha1Regex.MatchString(commitHash) {
+		hach, err := repo.ResolveRevision(plumbing.Revision(commitHash))
+		if err != nil {
+			return nil, fmt.Errorf("resolving abbreviated commit %q: %w", commitHash, err)
+		}
+		commitHash = hach.String()
+	}
+
+	// Date refs are resolved by walking back the history of the cloned ref
+	// to the newest commit not after the requested date.
+	if !components.AsOf.IsZero() {
+		tip := plumbing.NewHash(commitHash)
+		if commitHash == "" {
+			head, err := repo.Head()
+			if err != nil {
+				return nil, fmt.Errorf("reading repository HEAD: %w", err)
+			}
+			tip = head.Hash()
+		}
+		hach, err := commitAsOf(repo, tip, components.AsOf)
+		if err != nil {
+			return nil, err
+		}
+		commitHash = hach.String()
+	}
+
+	// Fetch the blobs of partial clones in one go before reading them
+	if opts.PartialClone && commitHash != "" {
+		prefetch := ""
+		if fsobj == nil {
+			prefetch = components.SubPath
+		}
+		if err := prefetchBlobs(repo, commitHash, prefetch); err != nil {
+			return nil, err
+		}
+	}
+
+	// Repositories moved to disk get their worktree next to them
+	if spill != nil && spill.dir != "" && opts.ClonePath == "" && fsobj != nil {
+		fsobj = osfs.New(spill.dir)
+		if repo, err = git.Open(repo.Storer, fsobj); err != nil {
+			return nil, fmt.Errorf("opening spilled repository: %w", err)
+		}
+	}
+
+	// Clones check out the remote HEAD or the requested branch or tag.
+	// Tags are peeled to the commit they point to, including annotated
+	// tags pointing to other tag objects.
+	checkout := commitHash != ""
+	if commitHash == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("reading repository HEAD: %w", err)
+		}
+		commitHash = head.Hash().String()
+
+		if components.Tag != "" {
+			commit, err := peelTagRef(repo, components.Tag)
+			if err != nil {
+				return nil, err
+			}
+			checkout = commit.Hash != head.Hash()
+			commitHash = commit.Hash.String()
+		}
+	}
+
+	// Nothing to check out in bare clones, files of bare repositories read
+	// in place come straight from the object store
+	_, fromTree := fsobj.(*treeFS)
+	if fsobj != nil && !fromTree && (checkout || deferCheckout) {
+		var dirs []string
+		if sparse {
+			dirs, err = sparseCheckoutDirs(repo, commitHash, components.SubPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		wt, err := repo.Worktree()
 		if err != nil {
 			return nil, fmt.Errorf("getting repository worktree: %w", err)
 		}
 
+		// The worktree was just created so it is safe to force the checkout.
+		// Symlinks in in-memory worktrees are otherwise reported as changes.
 		if err = wt.Checkout(&git.CheckoutOptions{
-			Hash: plumbing.NewHash(commitHash),
+			Hash:                      plumbing.NewHash(commitHash),
+			Force:                     true,
+			SparseCheckoutDirectories: dirs,
 		}); err != nil {
 			return nil, fmt.Errorf("checking out commit %s: %w", commitHash, err)
 		}
 	}
 
-	return iofs.New(fsobj), nil
+	var submodules map[string]*object.Tree
+	if opts.Submodules && fsobj != nil {
+		if err := requireOnline(l, opts); err != nil {
+			return nil, err
+		}
+		if submodules, err = checkoutSubmodules(repo, components, opts, opts.SubmoduleDepth); err != nil {
+			return nil, err
+		}
+	}
+
+	succeeded = true
+	return &clonedRepo{
+		Repo:       repo,
+		FS:         fsobj,
+		Components: components,
+		Commit:     commitHash,
+		submodules: submodules,
+		opts:       *opts,
+		funcs:      funcs,
+		auth:       auth,
+		cleanup:    cleanup,
+	}, nil
 }
 
 // ReadFromRepo opens a git repository by walking up from startDir toward the
@@ -404,7 +815,7 @@ func ReadFromRepo(startDir string, funcs ...fnOpt) (Locator, error) {
 			return "", fmt.Errorf("path diverged via symlink: %q resolves to %q", current, resolved)
 		}
 
-		repo, err := git.PlainOpen(current)
+		repo, err := openLocal(current)
 		if err == nil {
 			return locatorFromRepo(repo)
 		}
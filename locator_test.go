@@ -4,10 +4,12 @@
 package vcslocator
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -37,6 +39,31 @@ func TestParseLocator(t *testing.T) {
 				Commit: "25c779ba165d1f4fac6fc2ce938bf40c1f8ab1a6", RefString: "25c779ba165d1f4fac6fc2ce938bf40c1f8ab1a6",
 			}, nil, false,
 		},
+		{
+			"commit-abbreviated", Locator("https://github.com/example/test@25c779ba16"),
+			&Components{
+				Transport: "https", Hostname: "github.com", RepoPath: "/example/test",
+				Commit: "25c779ba16", RefString: "25c779ba16",
+			}, nil, false,
+		},
+		{
+			"commit-too-short", Locator("https://github.com/example/test@25c779"),
+			&Components{
+				Transport: "https", Hostname: "github.com", RepoPath: "/example/test",
+				Tag: "25c779", RefString: "25c779",
+			}, nil, false,
+		},
+		{
+			"date-ref", Locator("https://github.com/example/test@main@{2025-01-01}"),
+			&Components{
+				Transport: "https", Hostname: "github.com", RepoPath: "/example/test",
+				Branch: "main", RefString: "main@{2025-01-01}",
+			}, nil, false,
+		},
+		{
+			"date-ref-bad-date", Locator("https://github.com/example/test@main@{yesterday}"),
+			nil, nil, true,
+		},
 		{
 			"full-branch", Locator("git+http://github.com/example/test@abcd#%2egithub/dependabot.yaml"),
 			&Components{
@@ -163,6 +190,47 @@ func TestParseLocator(t *testing.T) {
 	}
 }
 
+func TestParseConcurrent(t *testing.T) {
+	t.Parallel()
+	// Exercises the shared parser state, run with -race to detect races
+	locators := []Locator{
+		"kubernetes/release-sdk@main#README.md",
+		"git+https://github.com/example/test@0123456789abcdef0123456789abcdef01234567#file.txt",
+		"git+https://github.com/example/test@0123456#file.txt",
+		"git+ssh://git@gitlab.com/group/repo@v1.0.0",
+		"git+https://git.sr.ht/~user/repo@main@{2025-01-01}",
+	}
+	expected := make([]*Components, len(locators))
+	for i, l := range locators {
+		c, err := l.Parse()
+		require.NoError(t, err)
+		expected[i] = c
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64*len(locators))
+	for range 64 {
+		for i, l := range locators {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c, err := l.Parse()
+				switch {
+				case err != nil:
+					errs <- err
+				case c.String() != expected[i].String() || c.Commit != expected[i].Commit:
+					errs <- fmt.Errorf("locator %q parsed as %q", l, c.String())
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
 func TestGetGroup(t *testing.T) {
 	t.Parallel()
 	for _, tc := range []struct {
@@ -454,3 +522,58 @@ func TestRemoteURLToLocator(t *testing.T) {
 		})
 	}
 }
+
+func TestMirrors(t *testing.T) {
+	t.Parallel()
+
+	mirrors := WithMirrors(map[string]string{
+		"GitHub.com":  "git.mirror.internal",
+		"gitlab.com":  "git.mirror.internal/gitlab/",
+		"example.com": "https://mirror.example.net/git/",
+	})
+	for _, tc := range []struct {
+		locator  string
+		expected string
+	}{
+		{"git+https://github.com/org/repo", "https://git.mirror.internal/org/repo"},
+		{"org/repo", "https://git.mirror.internal/org/repo"},
+		{"git+ssh://github.com/org/repo", "git@git.mirror.internal:org/repo"},
+		{"git+https://gitlab.com/group/sub/repo", "https://git.mirror.internal/gitlab/group/sub/repo"},
+		{"git+https://example.com/repo.git", "https://mirror.example.net/git/repo.git"},
+		{"git+https://bitbucket.org/org/repo", "https://bitbucket.org/org/repo"},
+	} {
+		t.Run(tc.locator, func(t *testing.T) {
+			t.Parallel()
+			components, err := Locator(tc.locator).Parse(mirrors)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, components.fetchURL())
+
+			// The locator keeps pointing to the original host
+			plain, err := Locator(tc.locator).Parse()
+			require.NoError(t, err)
+			require.Equal(t, plain.String(), components.String())
+		})
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		t.Parallel()
+		repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{"README.md": "mirrored"})
+		mirrorDir := t.TempDir()
+		_, err := git.PlainClone(filepath.Join(mirrorDir, "org", "repo"), true, &git.CloneOptions{URL: repoDir})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(
+			"git+https://github.com/org/repo@"+commitHash+"#README.md", &buf,
+			WithSystemCredentials(false),
+			WithMirrors(map[string]string{"github.com": "file://" + filepath.ToSlash(mirrorDir)}),
+		))
+		require.Equal(t, "mirrored", buf.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		opts := defaultOptions
+		require.Error(t, WithMirrors(map[string]string{"github.com": ""})(&opts))
+	})
+}
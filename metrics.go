@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import "time"
+
+// Metrics receives measurements of the operations of the package, to
+// export them to a monitoring system. Implementations must be safe for
+// concurrent use.
+type Metrics interface {
+	// ForgeRateLimit reports the API quota left in a forge host, read from
+	// the rate limit headers of its responses
+	ForgeRateLimit(host string, remaining, limit int, reset time.Time)
+
+	// ParseCacheLookup reports a lookup in the parse cache, see
+	// SetParseCacheSize
+	ParseCacheLookup(hit bool)
+}
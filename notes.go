@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DefaultNotesRef is the ref git stores notes in when no other is specified
+const DefaultNotesRef = "refs/notes/commits"
+
+// ErrNoteNotFound is returned when an object has no note attached
+var ErrNoteNotFound = errors.New("note not found")
+
+// GetNote fetches the notes ref from the repository referenced by the locator
+// and returns the contents of the note attached to the object identified by
+// objectHash. If the locator ref points to a notes ref (ie refs/notes/review)
+// it is read from it, otherwise notes are read from refs/notes/commits.
+func GetNote[T ~string](locator T, objectHash string, funcs ...fnOpt) ([]byte, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if !sha1Regex.MatchString(objectHash) {
+		return nil, fmt.Errorf("invalid object hash %q", objectHash)
+	}
+
+	l := Locator(locator)
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing locator: %w", err)
+	}
+
+	notesRef := DefaultNotesRef
+	if strings.HasPrefix(components.refName(), "refs/notes/") {
+		notesRef = components.refName()
+	}
+
+	var cleanup func() error
+	repo, err := withCredentialPrompt(components, &opts, func() (*git.Repository, error) {
+		auth, err := prepareRemote(l, components, &opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := requireOnline(l, &opts); err != nil {
+			return nil, err
+		}
+
+		st, done, err := newStorage(&opts, components)
+		if err != nil {
+			return nil, err
+		}
+		repo, err := fetchRef(st, nil, components.fetchURL(), notesRef, auth, 1)
+		if err != nil {
+			done() //nolint:errcheck,gosec
+			return nil, err
+		}
+		cleanup = done
+		return repo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup() //nolint:errcheck
+
+	ref, err := repo.Reference(plumbing.ReferenceName(notesRef), true)
+	if err != nil {
+		return nil, fmt.Errorf("resolving notes ref: %w", err)
+	}
+
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("reading notes commit: %w", err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading notes tree: %w", err)
+	}
+
+	file, err := findNote(tree, objectHash)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("opening note blob: %w", err)
+	}
+	defer reader.Close() //nolint:errcheck
+
+	return io.ReadAll(reader)
+}
+
+// findNote looks up the note of an object in a notes tree. Depending on the
+// number of notes, git stores them using the full object hash as name or
+// fans them out in subdirectories named after the hash prefix
+// (ie 28/a0276dde459992f3d8bbb4cb41cd34313a99ff).
+func findNote(tree *object.Tree, hash string) (*object.File, error) {
+	for i := range tree.Entries {
+		entry := &tree.Entries[i]
+		switch {
+		case entry.Name == hash && entry.Mode.IsFile():
+			return tree.TreeEntryFile(entry)
+		case entry.Mode == filemode.Dir && len(entry.Name) < len(hash) && strings.HasPrefix(hash, entry.Name):
+			subtree, err := tree.Tree(entry.Name)
+			if err != nil {
+				return nil, fmt.Errorf("reading notes subtree: %w", err)
+			}
+			return findNote(subtree, hash[len(entry.Name):])
+		}
+	}
+	return nil, ErrNoteNotFound
+}
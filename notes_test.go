@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/stretchr/testify/require"
+)
+
+// objectEncoder is implemented by the go-git objects (commits, trees, tags)
+type objectEncoder interface {
+	Encode(plumbing.EncodedObject) error
+}
+
+// storeTestObject encodes an object into the repository storage
+func storeTestObject(t *testing.T, s storer.EncodedObjectStorer, o objectEncoder) plumbing.Hash {
+	t.Helper()
+	obj := s.NewEncodedObject()
+	require.NoError(t, o.Encode(obj))
+	h, err := s.SetEncodedObject(obj)
+	require.NoError(t, err)
+	return h
+}
+
+// addTestNote writes a notes commit to ref with a single note attached to
+// object. When fanout is true, the note is stored in a subdirectory named
+// after the first two characters of the object hash.
+func addTestNote(t *testing.T, repoDir, ref, objectHash, note string, fanout bool) {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+
+	blob := repo.Storer.NewEncodedObject()
+	blob.SetType(plumbing.BlobObject)
+	w, err := blob.Writer()
+	require.NoError(t, err)
+	_, err = w.Write([]byte(note))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	blobHash, err := repo.Storer.SetEncodedObject(blob)
+	require.NoError(t, err)
+
+	var treeHash plumbing.Hash
+	if fanout {
+		sub := storeTestObject(t, repo.Storer, &object.Tree{Entries: []object.TreeEntry{
+			{Name: objectHash[2:], Mode: filemode.Regular, Hash: blobHash},
+		}})
+		treeHash = storeTestObject(t, repo.Storer, &object.Tree{Entries: []object.TreeEntry{
+			{Name: objectHash[:2], Mode: filemode.Dir, Hash: sub},
+		}})
+	} else {
+		treeHash = storeTestObject(t, repo.Storer, &object.Tree{Entries: []object.TreeEntry{
+			{Name: objectHash, Mode: filemode.Regular, Hash: blobHash},
+		}})
+	}
+
+	sig := object.Signature{Name: "test", Email: "test@test.com", When: time.Now()}
+	commitHash := storeTestObject(t, repo.Storer, &object.Commit{
+		Author: sig, Committer: sig, Message: "Notes added", TreeHash: treeHash,
+	})
+	setTestRef(t, repoDir, ref, commitHash.String())
+}
+
+func TestGetNote(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	second := commitTestFile(t, repoDir, "hello.txt", "bye")
+	addTestNote(t, repoDir, DefaultNotesRef, first, "flat note", false)
+	addTestNote(t, repoDir, "refs/notes/review", second, "fanout note", true)
+
+	t.Run("default-ref", func(t *testing.T) {
+		t.Parallel()
+		note, err := GetNote(NewFromPath(repoDir), first, noAuth)
+		require.NoError(t, err)
+		require.Equal(t, "flat note", string(note))
+	})
+
+	t.Run("notes-ref-in-locator", func(t *testing.T) {
+		t.Parallel()
+		note, err := GetNote(fileLocator(repoDir, "refs/notes/review", ""), second, noAuth)
+		require.NoError(t, err)
+		require.Equal(t, "fanout note", string(note))
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetNote(NewFromPath(repoDir), second, noAuth)
+		require.ErrorIs(t, err, ErrNoteNotFound)
+	})
+
+	t.Run("invalid-hash", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetNote(NewFromPath(repoDir), "xyz", noAuth)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+// NotCachedError is returned in offline mode when the data referenced by a
+// locator is not available in the local copies of its repository.
+type NotCachedError struct {
+	// Locator is the locator that could not be served
+	Locator string
+
+	// Revision is the commit or reference missing in the local copy. It is
+	// empty when there is no local copy of the repository.
+	Revision string
+}
+
+func (e *NotCachedError) Error() string {
+	if e.Revision == "" {
+		return fmt.Sprintf("repository of %s is not cached locally (offline mode)", e.Locator)
+	}
+	return fmt.Sprintf("%s of %s is not cached locally (offline mode)", e.Revision, e.Locator)
+}
+
+// requireOnline fails with a NotCachedError in offline mode. Functions that
+// can only be served by the remote call it before contacting it.
+func requireOnline(l Locator, opts *options) error {
+	if opts.Offline {
+		return &NotCachedError{Locator: string(l)}
+	}
+	return nil
+}
+
+// offlineClone builds the repository of a locator from its local copies
+// without contacting the remote: the git directory of a previous download
+// (see WithKeepGitDir) or the reference repository (see WithReference).
+// References are only read from reference repositories cloned from the
+// locator remote, commits are looked up by hash in any of them. It returns
+// the commit the locator resolves to.
+func offlineClone(l Locator, components *Components, opts *options, st storage.Storer, fsobj billy.Filesystem) (*git.Repository, plumbing.Hash, error) {
+	var repo, source *git.Repository
+	var err error
+	switch {
+	case opts.refreshStorer != nil:
+		if repo, err = git.Open(st, fsobj); err != nil {
+			return nil, plumbing.ZeroHash, fmt.Errorf("opening cached repository: %w", err)
+		}
+		source = repo
+	case opts.ReferenceRepo != "":
+		source = findReferenceStorer(st).reference
+		if repo, err = git.Init(st, fsobj); err != nil {
+			return nil, plumbing.ZeroHash, fmt.Errorf("initializing repo: %w", err)
+		}
+		if _, err = repo.CreateRemote(&config.RemoteConfig{
+			Name: "origin",
+			URLs: []string{components.fetchURL()},
+		}); err != nil {
+			return nil, plumbing.ZeroHash, fmt.Errorf("creating remote: %w", err)
+		}
+		if !clonedFrom(source, components.fetchURL()) && components.Commit == "" {
+			return nil, plumbing.ZeroHash, &NotCachedError{Locator: string(l)}
+		}
+	default:
+		return nil, plumbing.ZeroHash, &NotCachedError{Locator: string(l)}
+	}
+
+	commit, err := cachedCommit(source, components, remoteHeadRef, plumbing.HEAD)
+	if err != nil {
+		return nil, plumbing.ZeroHash, &NotCachedError{Locator: string(l), Revision: components.refName()}
+	}
+
+	// HEAD is left detached at the commit, as after fetching it by hash
+	if err := st.SetReference(plumbing.NewHashReference(plumbing.HEAD, commit)); err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("storing HEAD: %w", err)
+	}
+	return repo, commit, nil
+}
+
+// clonedFrom checks if the origin remote of a repository points to repourl
+func clonedFrom(repo *git.Repository, repourl string) bool {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return false
+	}
+	urls := remote.Config().URLs
+	return len(urls) > 0 && urls[0] == repourl
+}
+
+// cachedCommit resolves the locator ref to a commit in a local repository.
+// Branches are looked up both as local branches and as remote tracking
+// branches of origin, as clones only have the latter. Locators without a
+// ref resolve to the first of the head references found.
+func cachedCommit(repo *git.Repository, components *Components, head ...plumbing.ReferenceName) (plumbing.Hash, error) {
+	var names []plumbing.ReferenceName
+	switch {
+	case components.Commit != "":
+		hash, err := repo.ResolveRevision(plumbing.Revision(components.Commit))
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		commit, err := peelToCommit(repo, *hash)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return commit.Hash, nil
+	case components.Branch != "":
+		names = []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName(components.Branch),
+			plumbing.NewRemoteReferenceName("origin", components.Branch),
+		}
+	case components.Tag != "":
+		names = []plumbing.ReferenceName{plumbing.NewTagReferenceName(components.Tag)}
+	case components.RefString != "":
+		names = []plumbing.ReferenceName{plumbing.ReferenceName(components.refName())}
+	default:
+		names = head
+	}
+
+	for _, name := range names {
+		ref, err := repo.Reference(name, true)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			continue
+		}
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		commit, err := peelToCommit(repo, ref.Hash())
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return commit.Hash, nil
+	}
+	return plumbing.ZeroHash, plumbing.ErrReferenceNotFound
+}
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// abbrevHash abbreviates a commit hash. Abbreviations of only digits are
+// parsed as version queries so they are extended to the first letter.
+func abbrevHash(hash string) string {
+	n := 8
+	for n < len(hash) && !strings.ContainsAny(hash[:n], "abcdef") {
+		n++
+	}
+	return hash[:n]
+}
+
+func TestOffline(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"README.md": "v1"})
+
+	// The mirror is cloned from the remote before it gets a new commit
+	mirrorDir := filepath.Join(t.TempDir(), "mirror.git")
+	_, err := git.PlainClone(mirrorDir, true, &git.CloneOptions{URL: "file://" + filepath.ToSlash(repoDir)})
+	require.NoError(t, err)
+	second := commitTestFile(t, repoDir, "README.md", "v2")
+
+	for _, tc := range []struct {
+		name     string
+		ref      string
+		opts     []fnOpt
+		expected string
+		revision string
+	}{
+		{"branch", "refs/heads/master", []fnOpt{WithReference(mirrorDir)}, "v1", ""},
+		{"head", "", []fnOpt{WithReference(mirrorDir)}, "v1", ""},
+		{"commit", abbrevHash(first), []fnOpt{WithReference(mirrorDir)}, "v1", ""},
+		{"missing-commit", second, []fnOpt{WithReference(mirrorDir)}, "", second},
+		{"missing-branch", "refs/heads/nope", []fnOpt{WithReference(mirrorDir)}, "", "refs/heads/nope"},
+		{"notes", "refs/notes/commits", []fnOpt{WithReference(mirrorDir)}, "", "refs/notes/commits"},
+		{"no-cache", "refs/heads/master", nil, "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			err := CopyFile(
+				fileLocator(repoDir, tc.ref, "README.md"), &buf,
+				append(tc.opts, noAuth, WithOffline(true))...,
+			)
+			if tc.expected != "" {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, buf.String())
+				return
+			}
+			var nce *NotCachedError
+			require.True(t, errors.As(err, &nce), err)
+			require.Equal(t, tc.revision, nce.Revision)
+		})
+	}
+
+	t.Run("resolve", func(t *testing.T) {
+		t.Parallel()
+		commit, err := Locator(fileLocator(repoDir, "refs/heads/master", "")).Resolve(noAuth, WithReference(mirrorDir), WithOffline(true))
+		require.NoError(t, err)
+		require.Equal(t, first, commit)
+	})
+
+	t.Run("remote-only", func(t *testing.T) {
+		t.Parallel()
+		_, err := ListRemoteRefs(fileLocator(repoDir, "", ""), noAuth, WithOffline(true))
+		var nce *NotCachedError
+		require.True(t, errors.As(err, &nce), err)
+	})
+
+	t.Run("previous-download", func(t *testing.T) {
+		t.Parallel()
+		dest := t.TempDir()
+		locator := fileLocator(repoDir, "refs/heads/master", "")
+		require.NoError(t, Download(locator, dest, noAuth, WithKeepGitDir(true)))
+		require.NoError(t, os.Remove(filepath.Join(dest, "README.md")))
+
+		require.NoError(t, Download(locator, dest, noAuth, WithKeepGitDir(true), WithOffline(true)))
+		data, err := os.ReadFile(filepath.Join(dest, "README.md"))
+		require.NoError(t, err)
+		require.Equal(t, "v2", string(data))
+	})
+}
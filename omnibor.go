@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// omniborManifestHeader is the first line of OmniBOR input manifests using
+// SHA-256 gitoids.
+const omniborManifestHeader = "gitoid:blob:sha256"
+
+// OmniBORManifest is an OmniBOR input manifest listing the artifacts that
+// make up a subtree of a repository.
+type OmniBORManifest struct {
+	// Inputs are the hex encoded SHA-256 gitoids of the input artifacts
+	Inputs []string
+}
+
+// Bytes returns the manifest in the OmniBOR text format: the gitoid header
+// followed by one sorted "blob <gitoid>" line per unique input.
+func (m *OmniBORManifest) Bytes() []byte {
+	inputs := slices.Clone(m.Inputs)
+	slices.Sort(inputs)
+	inputs = slices.Compact(inputs)
+
+	var buf bytes.Buffer
+	buf.WriteString(omniborManifestHeader + "\n")
+	for _, input := range inputs {
+		fmt.Fprintf(&buf, "blob %s\n", input)
+	}
+	return buf.Bytes()
+}
+
+// ID returns the gitoid URI of the manifest, which identifies the
+// artifact tree in OmniBOR.
+func (m *OmniBORManifest) ID() string {
+	data := m.Bytes()
+	h := newGitOIDHasher(int64(len(data)))
+	h.Write(data)
+	return "gitoid:blob:sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// GetOmniBORManifest returns the OmniBOR input manifest of the file or
+// directory referenced by the locator. The manifest ties together the
+// gitoids of all files under the subpath.
+func GetOmniBORManifest[T ~string](locator T, funcs ...fnOpt) (*OmniBORManifest, error) {
+	oids, err := GetGitOIDs(locator, funcs...)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &OmniBORManifest{Inputs: []string{}}
+	for _, oid := range oids {
+		if oid.Type == GitOIDTypeBlob {
+			manifest.Inputs = append(manifest.Inputs, oid.SHA256)
+		}
+	}
+	return manifest, nil
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOmniBORManifest(t *testing.T) {
+	t.Parallel()
+
+	m := &OmniBORManifest{Inputs: []string{"bbbb", "aaaa", "bbbb"}}
+	require.Equal(t, "gitoid:blob:sha256\nblob aaaa\nblob bbbb\n", string(m.Bytes()))
+	require.True(t, strings.HasPrefix(m.ID(), "gitoid:blob:sha256:"))
+	require.Len(t, strings.TrimPrefix(m.ID(), "gitoid:blob:sha256:"), 64)
+	require.Equal(t, []string{"bbbb", "aaaa", "bbbb"}, m.Inputs)
+}
+
+func TestGetOmniBORManifest(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"hello.txt":        "hello",
+		"docs/guide.md":    "# Guide",
+		"docs/api/spec.md": "spec",
+	})
+	locator := fileLocator(repoDir, commitHash, "docs/")
+
+	manifest, err := GetOmniBORManifest(locator, noAuth)
+	require.NoError(t, err)
+	require.Len(t, manifest.Inputs, 2)
+
+	// Download computes the same manifest while copying
+	destDir := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "manifest")
+	require.NoError(t, Download(locator, destDir, noAuth, WithOmniBORManifest(manifestPath)))
+
+	data, err := os.ReadFile(manifestPath)
+	require.NoError(t, err)
+	require.Equal(t, string(manifest.Bytes()), string(data))
+}
@@ -4,7 +4,17 @@
 package vcslocator
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage"
 )
 
 // options is the internal options struct used by the locator functions.
@@ -14,24 +24,268 @@ type options struct {
 	RefIsBranch bool
 	ClonePath   string
 
+	// RefAsOf resolves the locator ref to its state at a point in time
+	RefAsOf time.Time
+
+	// LineStart and LineEnd select a range of lines of the fetched files
+	LineStart int
+	LineEnd   int
+
 	// ReadCredentials controls if the library loads the system git credentials
 	ReadCredentials bool
 
 	// Username and password for HTTP basic config
 	HttpUsername, HttpPassword string
 
+	// AuthMethod overrides the credentials detected for the remotes
+	AuthMethod transport.AuthMethod
+
+	// TokenSource returns the token sent to HTTPS remotes, it is called
+	// before each operation
+	TokenSource func(context.Context) (string, error)
+
+	// Keychain reads the credentials of the hosts from the OS keychain
+	Keychain bool
+
+	// CredentialPrompt asks for the credentials of the HTTPS remotes that
+	// require them when none are configured
+	CredentialPrompt *credentialPrompt
+
+	// AskPass is the askpass program asked for the credentials of HTTPS
+	// remotes and the passphrases of SSH keys
+	AskPass string
+
+	// HttpClient is used for HTTP requests not performed by git (ie go-get
+	// metadata lookups). Defaults to http.DefaultClient.
+	HttpClient *http.Client
+
+	// ExpectedDigests holds the digests the fetched data must match
+	ExpectedDigests []string
+
+	// VCSPolicy restricts the tools and transports allowed per host
+	VCSPolicy vcsPolicy
+
+	// AllowedHosts and BlockedHosts are glob patterns of the hosts locators
+	// are allowed to, or must not, fetch from
+	AllowedHosts []string
+	BlockedHosts []string
+
+	// Mirrors maps hostnames to the mirrors repositories are fetched from
+	Mirrors map[string]string
+
+	// AllowLocal controls if file:// locators are accepted. When nil, the
+	// package default set with SetAllowLocalDefault applies.
+	AllowLocal *bool
+
+	// MaxRepoSize and MaxFileSize limit the size in bytes of the objects
+	// fetched from the remote and of the individual files. Zero means no limit.
+	MaxRepoSize int64
+	MaxFileSize int64
+
+	// BandwidthLimit caps the rate of data received from remotes in bytes
+	// per second. Zero means no limit.
+	BandwidthLimit int64
+
+	// Symlinks is the policy to handle symbolic links when downloading
+	Symlinks string
+
+	// Include and Exclude are glob patterns filtering downloaded files
+	Include []string
+	Exclude []string
+
+	// ExportIgnore skips the paths marked export-ignore in .gitattributes
+	ExportIgnore bool
+
+	// KeepGitDir makes Download write the repository to localDir/.git
+	KeepGitDir bool
+
+	// Progress receives the progress messages of clones and downloads
+	Progress io.Writer
+
+	// refreshStorer holds the repository of a previous download to update
+	// instead of cloning (see WithKeepGitDir)
+	refreshStorer storage.Storer
+
+	// ShallowSince limits the history fetched to the commits after a date
+	ShallowSince time.Time
+
+	// PartialClone omits the blobs when cloning, fetching them when read
+	PartialClone bool
+
+	// Submodules makes clones check out the submodules of the repository,
+	// nested up to SubmoduleDepth levels (zero means no limit)
+	Submodules     bool
+	SubmoduleDepth int
+
+	// DiskStorage writes the object database of clones to a temporary
+	// directory instead of keeping it in memory
+	DiskStorage bool
+
+	// StorageDir is where the temporary directories of clones stored on
+	// disk (or spilled over the memory budget) are created, the system
+	// temp dir when empty
+	StorageDir string
+
+	// MemoryBudget is the size of the objects kept in memory when cloning,
+	// larger repositories are moved to a temporary directory
+	MemoryBudget int64
+
+	// OpenInPlace reads file:// repositories where they are instead of
+	// cloning them
+	OpenInPlace bool
+
+	// Offline serves clones only from the local copies of the repositories
+	Offline bool
+
+	// ForgeAPI fetches single files at pinned commits through the API of
+	// the forge hosting the repository instead of cloning it
+	ForgeAPI bool
+
+	// APICache stores the responses of forge APIs to revalidate them
+	APICache APICache
+
+	// RateLimitWait is the longest a forge API request waits for the
+	// quota of the host when it is running out
+	RateLimitWait time.Duration
+
+	// BreakerThreshold is the number of consecutive failures of a host
+	// after which group operations skip it, zero disables the breaker
+	BreakerThreshold int
+
+	// Metrics receives the measurements of the operations
+	Metrics Metrics
+
+	// Providers assign the forge providers to hostname patterns, they
+	// take precedence over the registered ones
+	Providers []providerRule
+
+	// ReferenceRepo is the path of a local repository to borrow objects
+	// from instead of fetching them
+	ReferenceRepo string
+
+	// StorerFactory creates the storers repositories are cloned into
+	StorerFactory StorerFactory
+
+	// LFS makes files stored in Git LFS return their contents instead of
+	// the pointer files recorded in the repository
+	LFS bool
+
+	// noCheckout clones the repository without a worktree. Functions that
+	// read the objects directly set it to skip writing the files.
+	noCheckout bool
+
+	// fullHistory makes pinned commits be cloned along with their history
+	// for functions walking it, instead of fetching only the commit.
+	fullHistory bool
+
+	// ArchiveMtime is the modification time of archive entries
+	ArchiveMtime time.Time
+
+	// BundleDepth limits the commits written by CopyBundle
+	BundleDepth int
+
+	// Sync makes Download remove files not present in the source
+	Sync bool
+
+	// IllegalNames is the policy for paths that cannot be written on windows
+	IllegalNames string
+
+	// ChecksumManifestPath is where Download writes the checksum manifest
+	ChecksumManifestPath string
+
+	// OmniBORManifestPath is the file where Download writes the OmniBOR
+	// input manifest of the downloaded files
+	OmniBORManifestPath string
+
 	// TopLevelPath sets the uppermost directory to search when walking up the
 	// filesystem looking for a git repository. Defaults to the filesystem root.
 	TopLevelPath string
 }
 
 var defaultOptions = options{
-	ReadCredentials: true,
-	RefIsBranch:     false,
+	ReadCredentials:  true,
+	Symlinks:         SymlinksSkip,
+	LFS:              true,
+	ForgeAPI:         true,
+	RateLimitWait:    defaultRateLimitWait,
+	BreakerThreshold: defaultBreakerThreshold,
+	IllegalNames:     IllegalNamesReject,
+	RefIsBranch:      false,
 }
 
 type fnOpt func(*options) error
 
+// Option is a functional option of the package functions. Programs use it
+// to build the list of options to pass to them.
+type Option = fnOpt
+
+// defaultAllowLocal is the package wide default of WithAllowLocal
+var defaultAllowLocal atomic.Bool
+
+func init() {
+	defaultAllowLocal.Store(true)
+}
+
+// SetAllowLocalDefault sets if file:// locators are accepted when
+// WithAllowLocal is not specified. Local locators are allowed by default,
+// servers processing untrusted locators should disable them at startup.
+func SetAllowLocalDefault(yesno bool) {
+	defaultAllowLocal.Store(yesno)
+}
+
+// allowLocal returns true if local locators are allowed
+func (o *options) allowLocal() bool {
+	if o.AllowLocal != nil {
+		return *o.AllowLocal
+	}
+	return defaultAllowLocal.Load()
+}
+
+// progressf writes a message to the progress writer, if one is set
+func (o *options) progressf(format string, args ...any) {
+	if o.Progress != nil {
+		fmt.Fprintf(o.Progress, format, args...) //nolint:errcheck
+	}
+}
+
+// httpClient returns the configured HTTP client or the default one
+func (o *options) httpClient() *http.Client {
+	if o.HttpClient != nil {
+		return o.HttpClient
+	}
+	return http.DefaultClient
+}
+
+// credentialPrompt returns the prompt asking for the credentials of the
+// remotes requiring them. Like git, the askpass program set in the
+// environment is run when no prompt is set.
+func (o *options) credentialPrompt() *credentialPrompt {
+	if o.CredentialPrompt != nil {
+		return o.CredentialPrompt
+	}
+	if o.AskPass != "" {
+		return askPassCredentials(o.AskPass)
+	}
+	return nil
+}
+
+// forgeClient returns the HTTP client for forge API requests. Requests are
+// paced according to the API quota of the hosts and go through the API
+// cache when one is set.
+func (o *options) forgeClient() *http.Client {
+	client := *o.httpClient()
+	var tr http.RoundTripper = http.DefaultTransport
+	if client.Transport != nil {
+		tr = client.Transport
+	}
+	tr = &rateLimitTransport{base: tr, maxWait: o.RateLimitWait, metrics: o.Metrics}
+	if o.APICache != nil {
+		tr = &cachingTransport{base: tr, cache: o.APICache}
+	}
+	client.Transport = tr
+	return &client
+}
+
 // WithRefAsBranch instructs the parser to treat the ref as branch name instead
 // of a tag name.
 func WithRefAsBranch(sino bool) fnOpt { //nolint:revive
@@ -46,6 +300,391 @@ func WithRefAsBranch(sino bool) fnOpt { //nolint:revive
 	}
 }
 
+// WithRefAsOf resolves the locator reference to the newest commit not after
+// the specified time. This is equivalent to adding a date to the locator
+// ref using the git reflog syntax: main@{2025-01-01}.
+func WithRefAsOf(date time.Time) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.RefAsOf = date
+		return nil
+	}
+}
+
+// WithLineRange makes the file reading functions return only the lines
+// from start to end (inclusive, counting from 1). An end of zero selects
+// the start line only. This is equivalent to a locator with a line range in
+// its fragment: #main.go#L10-L42.
+func WithLineRange(start, end int) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if err := validateLineRange(start, end); err != nil {
+			return err
+		}
+		o.LineStart = start
+		o.LineEnd = end
+		return nil
+	}
+}
+
+// WithShallowSince limits the history fetched when cloning to the commits
+// newer than the specified date (git clone --shallow-since). Locators
+// pinned to older commits fail to clone.
+func WithShallowSince(date time.Time) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.ShallowSince = date
+		return nil
+	}
+}
+
+// WithPartialClone clones repositories with --filter=blob:none semantics:
+// commits and trees are fetched when cloning and the file contents are
+// fetched later, only for the files read. The remote must support partial
+// clone filters and fetching objects by hash.
+func WithPartialClone(partial bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.PartialClone = partial
+		return nil
+	}
+}
+
+// WithSubmodules makes Download, OpenFS and the other functions reading the
+// worktree check out the submodules of the repository at the commits pinned
+// in it. Depth limits how many levels of nested submodules are fetched: 1
+// only checks out the submodules of the repository itself and 0 recurses
+// into all of them.
+func WithSubmodules(recurse bool, depth int) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if depth < 0 {
+			return errors.New("submodule depth cannot be negative")
+		}
+		o.Submodules = recurse
+		o.SubmoduleDepth = depth
+		return nil
+	}
+}
+
+// WithLFS sets if the contents of files stored in Git LFS are fetched from
+// the LFS server when reading or downloading them. It is enabled by default,
+// disabling it returns the LFS pointer files unchanged.
+func WithLFS(fetch bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.LFS = fetch
+		return nil
+	}
+}
+
+// WithDiskStorage stores the git objects of the cloned repositories on disk
+// instead of memory, keeping memory use low when cloning large repositories.
+// The objects are written to a temporary directory created under dir (or
+// the one set with WithStorageDir if dir is empty) which is removed once the
+// repository is no longer needed. If a clone path is set, the objects are written to
+// its .git directory instead, leaving a regular git clone in it.
+func WithDiskStorage(dir string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.DiskStorage = true
+		if dir != "" {
+			o.StorageDir = dir
+		}
+		return nil
+	}
+}
+
+// WithStorageDir sets the directory where the temporary directories of the
+// clones stored on disk (see WithDiskStorage) or spilled over the memory
+// budget (see WithMemoryBudget) are created. It defaults to the system temp
+// dir.
+func WithStorageDir(dir string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.StorageDir = dir
+		return nil
+	}
+}
+
+// WithMemoryBudget caps the memory used to hold cloned repositories. Clones
+// stay in memory while their objects add up to less than bytes, larger ones
+// are moved to a temporary directory on disk (created under the directory
+// set with WithStorageDir), along with their checkout. The directory is
+// removed once the repository is no longer needed. Zero, the default, keeps
+// all clones in memory.
+func WithMemoryBudget(bytes int64) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if bytes < 0 {
+			return errors.New("memory budget cannot be negative")
+		}
+		o.MemoryBudget = bytes
+		return nil
+	}
+}
+
+// WithReference clones repositories with git clone --reference semantics:
+// objects found in the local repository at path (a clone or a bare mirror)
+// are read from it and the remote only sends the objects missing in it.
+// Repositories written to disk record the reference in their alternates
+// file, so it must not be removed while they are in use.
+func WithReference(path string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.ReferenceRepo = path
+		return nil
+	}
+}
+
+// WithOpenInPlace makes file:// locators read the repository where it is on
+// disk instead of cloning it, which is much faster for large repositories.
+// Objects are read directly from its object database and files are checked
+// out to memory (or the clone path). Files of bare repositories are read
+// straight from the object store unless a clone path is set. The repository
+// is never modified.
+func WithOpenInPlace(inPlace bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.OpenInPlace = inPlace
+		return nil
+	}
+}
+
+// WithOffline makes the functions fetching from repositories work without
+// network access, as required in air-gapped environments. Clones are served
+// from the local copies of the repositories: the reference repository set
+// with WithReference and, when downloading with WithKeepGitDir, the git
+// directory of the previous download. If the repository or the revision is
+// not available locally, or the function needs the remote (ie listing its
+// references), a *NotCachedError is returned.
+func WithOffline(offline bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.Offline = offline
+		return nil
+	}
+}
+
+// WithForgeAPI controls if files at pinned commits are fetched through the
+// API of the forge hosting the repository (ie the GitHub contents API)
+// instead of cloning it, which is much faster when reading a single file.
+// If the API request fails, the repository is cloned. Enabled by default.
+func WithForgeAPI(useAPI bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.ForgeAPI = useAPI
+		return nil
+	}
+}
+
+// WithGitHubEnterprise declares hostnames as GitHub Enterprise servers, so
+// files from their repositories can be fetched through the GitHub API.
+func WithGitHubEnterprise(hosts ...string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		rules, err := newProviderRules(GitHubProvider{}, hosts...)
+		if err != nil {
+			return err
+		}
+		o.Providers = append(o.Providers, rules...)
+		return nil
+	}
+}
+
+// WithGitLabHosts declares hostnames as self-managed GitLab instances, so
+// files from their repositories can be fetched through the GitLab API.
+func WithGitLabHosts(hosts ...string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		rules, err := newProviderRules(GitLabProvider{}, hosts...)
+		if err != nil {
+			return err
+		}
+		o.Providers = append(o.Providers, rules...)
+		return nil
+	}
+}
+
+// WithGiteaHosts declares hostnames as Gitea or Forgejo servers, so files
+// from their repositories can be fetched through the Gitea API.
+func WithGiteaHosts(hosts ...string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		rules, err := newProviderRules(GiteaProvider{}, hosts...)
+		if err != nil {
+			return err
+		}
+		o.Providers = append(o.Providers, rules...)
+		return nil
+	}
+}
+
+// WithBitbucketServerHosts declares hostnames as Bitbucket Server (or Data
+// Center) instances, so files from their repositories can be fetched
+// through the Bitbucket Server REST API.
+func WithBitbucketServerHosts(hosts ...string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		rules, err := newProviderRules(BitbucketServerProvider{}, hosts...)
+		if err != nil {
+			return err
+		}
+		o.Providers = append(o.Providers, rules...)
+		return nil
+	}
+}
+
+// WithAPICache stores the responses of forge APIs in cache, revalidating
+// them with conditional requests (ETag and Last-Modified) so files fetched
+// again cost a 304 response instead of a full transfer. See
+// NewMemoryAPICache and NewDirAPICache.
+func WithAPICache(cache APICache) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.APICache = cache
+		return nil
+	}
+}
+
+// WithRateLimitWait sets the longest a forge API request waits when the
+// quota of the host is running out (one minute by default). Requests are
+// paced when less than a tenth of the quota is left, when it is exhausted
+// and does not reset in time the file is fetched by cloning instead.
+func WithRateLimitWait(wait time.Duration) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if wait < 0 {
+			return errors.New("rate limit wait cannot be negative")
+		}
+		o.RateLimitWait = wait
+		return nil
+	}
+}
+
+// WithCircuitBreaker sets the number of consecutive failures of a host
+// (five by default) after which CopyFileGroup and GetGroup stop contacting
+// it, failing the rest of its locators with a HostUnavailableError. Errors
+// caused by the locators, such as missing files or rejected credentials,
+// are not counted. Zero disables the breaker.
+func WithCircuitBreaker(threshold int) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if threshold < 0 {
+			return errors.New("circuit breaker threshold cannot be negative")
+		}
+		o.BreakerThreshold = threshold
+		return nil
+	}
+}
+
+// WithMetrics reports measurements of the operations, such as the API
+// quota left in the forges, to m.
+func WithMetrics(m Metrics) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.Metrics = m
+		return nil
+	}
+}
+
+// WithHostAlias declares that the hosts matching a pattern run the forge
+// of a provider (github, gitlab, gitea, bitbucket-cloud, bitbucket-server
+// or the name of a registered provider), so its API fast paths, auth
+// conventions and token environment variables apply to them. For example,
+// WithHostAlias("git.corp.example.com", "github") declares a GitHub
+// Enterprise server.
+func WithHostAlias(pattern, forge string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		p := providerNamed(forge)
+		if p == nil {
+			return fmt.Errorf("unknown forge %q", forge)
+		}
+		rules, err := newProviderRules(p, pattern)
+		if err != nil {
+			return err
+		}
+		o.Providers = append(o.Providers, rules...)
+		return nil
+	}
+}
+
+// WithProvider fetches data from the hosts matching a glob pattern (ie
+// *.example.com) through a forge provider. Providers set in the options take
+// precedence over the registered ones, see RegisterProvider.
+func WithProvider(pattern string, p Provider) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		rules, err := newProviderRules(p, pattern)
+		if err != nil {
+			return err
+		}
+		o.Providers = append(o.Providers, rules...)
+		return nil
+	}
+}
+
+// WithStorerFactory makes clones write the repositories to the go-git
+// storers returned by factory, such as storers backed by a shared object
+// cache or a database. It takes precedence over WithDiskStorage. The
+// storers are owned by the caller, they are not closed nor removed.
+func WithStorerFactory(factory StorerFactory) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.StorerFactory = factory
+		return nil
+	}
+}
+
 // WithClonePath specifies the directory to clone the repository. When
 func WithClonePath(path string) fnOpt {
 	return func(o *options) error {
@@ -94,3 +733,465 @@ func WithHttpAuth(user, password string) fnOpt {
 		return nil
 	}
 }
+
+// WithAuthMethod sets the auth method used to talk to the remotes (and the
+// forge APIs when it sends HTTP credentials), instead of the one detected
+// from the transport of the locator. It is used even if the system
+// credentials are turned off.
+func WithAuthMethod(auth transport.AuthMethod) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.AuthMethod = auth
+		return nil
+	}
+}
+
+// WithTokenSource sets a function returning the token sent to HTTPS
+// remotes. It is called again before each clone or fetch, so tokens that
+// expire during long runs (ie GitHub App installation tokens or OIDC
+// tokens in group fetches) are refreshed. Sources should cache the token
+// until it is close to expiring. The token takes precedence over the
+// detected credentials.
+func WithTokenSource(source func(ctx context.Context) (string, error)) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.TokenSource = source
+		return nil
+	}
+}
+
+// WithKeychain reads the credentials of HTTPS remotes from the OS keychain
+// (macOS Keychain, Windows Credential Manager or the Secret Service of
+// libsecret) when none are configured. The entries are looked up under the
+// KeychainService service by hostname.
+func WithKeychain(yesno bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.Keychain = yesno
+		return nil
+	}
+}
+
+// WithCredentialPrompt sets a function asking the user for the credentials
+// (ie a username and a password or token) of the HTTPS remotes rejecting an
+// operation when no credentials are set in the options. The operation is
+// tried again with the answer, which is reused for later operations on the
+// same host. If the answer is rejected too the operation fails, and the
+// next operation on the host prompts again.
+func WithCredentialPrompt(prompt func(host string) (user, secret string, err error)) fnOpt {
+	p := newCredentialPrompt(prompt)
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if prompt == nil {
+			return errors.New("credential prompt is nil")
+		}
+		o.CredentialPrompt = p
+		return nil
+	}
+}
+
+// WithAskPass sets an askpass program (like the ones git runs from
+// GIT_ASKPASS) to ask for the credentials of the HTTPS remotes rejecting an
+// operation and for the passphrases of encrypted SSH keys. It is not used
+// when a prompt is set with WithCredentialPrompt.
+func WithAskPass(program string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.AskPass = program
+		return nil
+	}
+}
+
+// WithExpectedDigest verifies the data fetched by CopyFile against a digest
+// in the form algorithm:hex (ie sha256:e3b0c4...). Supported algorithms are
+// sha1, sha256, sha384 and sha512. A mismatch is reported as a
+// *DigestMismatchError.
+//
+// When copying groups of files, pass one digest per locator in the same
+// order. Empty strings skip the check for the locator in that position.
+func WithExpectedDigest(digests ...string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		for _, d := range digests {
+			if d == "" {
+				continue
+			}
+			if _, _, err := parseDigest(d); err != nil {
+				return err
+			}
+		}
+		o.ExpectedDigests = digests
+		return nil
+	}
+}
+
+// WithOmniBORManifest makes Download write the OmniBOR input manifest of the
+// downloaded files to path. The gitoids are computed while the files are
+// copied.
+func WithOmniBORManifest(path string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.OmniBORManifestPath = path
+		return nil
+	}
+}
+
+// WithChecksumManifest makes Download write a JSON manifest to path listing
+// the SHA-256 digest of every downloaded file along with the locator and the
+// commit it resolved to (see ChecksumManifest).
+func WithChecksumManifest(path string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.ChecksumManifestPath = path
+		return nil
+	}
+}
+
+// WithHttpClient sets the HTTP client used for requests that are not
+// performed through git, such as the go-get metadata lookups.
+func WithHttpClient(client *http.Client) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.HttpClient = client
+		return nil
+	}
+}
+
+// WithVCSPolicy restricts the VCS tools and transports locators can use
+// per host, similar to the GOVCS environment variable. The policy is a comma
+// separated list of pattern:list rules where the pattern is a glob matched
+// against the locator hostname and the list contains | separated tools
+// (git) or tool+transport pairs (git+https). The special lists "all" and
+// "off" allow or reject everything for the host.
+//
+// The first rule matching the hostname applies, hosts matching no rule are
+// allowed. For example, to only allow https access to GitHub and block any
+// other host:
+//
+//	WithVCSPolicy("github.com:git+https,*:off")
+//
+// Locators rejected by the policy fail with a *PolicyViolationError before
+// any connection is attempted. Local file:// locators have an empty hostname.
+func WithVCSPolicy(policy string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		p, err := parseVCSPolicy(policy)
+		if err != nil {
+			return err
+		}
+		o.VCSPolicy = p
+		return nil
+	}
+}
+
+// WithAllowedHosts confines remote operations to hosts matching the
+// patterns. Patterns are globs matched against the locator hostname
+// (ie github.com or *.example.com). Locators pointing to other hosts fail
+// with a *PolicyViolationError before any network activity.
+func WithAllowedHosts(patterns ...string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		hosts, err := normalizeHostPatterns(patterns)
+		if err != nil {
+			return err
+		}
+		o.AllowedHosts = hosts
+		return nil
+	}
+}
+
+// WithMirrors fetches the repositories of locators pointing to a host from
+// a mirror instead, as needed in air-gapped environments. The mapping keys
+// are hostnames and the values either the mirror hostname, optionally
+// followed by a path prefix (ie git.mirror.internal/github), or a base URL
+// the repository path is appended to (ie file:///srv/mirrors/github.com).
+//
+// Only the URL contacted changes: locators, and the documents generated
+// from them, keep pointing to the original host, and host policies are
+// checked against it. Calling WithMirrors more than once adds to the
+// mapping.
+func WithMirrors(mirrors map[string]string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		m := make(map[string]string, len(o.Mirrors)+len(mirrors))
+		for host, mirror := range o.Mirrors {
+			m[host] = mirror
+		}
+		for host, mirror := range mirrors {
+			host = strings.ToLower(strings.TrimSpace(host))
+			mirror = strings.TrimSpace(mirror)
+			if host == "" || mirror == "" {
+				return fmt.Errorf("invalid mirror mapping %q -> %q", host, mirror)
+			}
+			m[host] = mirror
+		}
+		o.Mirrors = m
+		return nil
+	}
+}
+
+// WithBlockedHosts rejects locators pointing to hosts matching the patterns
+// with a *PolicyViolationError before any network activity. Blocked hosts
+// take precedence over allowed hosts.
+func WithBlockedHosts(patterns ...string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		hosts, err := normalizeHostPatterns(patterns)
+		if err != nil {
+			return err
+		}
+		o.BlockedHosts = hosts
+		return nil
+	}
+}
+
+// WithAllowLocal controls if file:// locators pointing to local repositories
+// are accepted. Rejected locators fail with a *PolicyViolationError. When not
+// set, the default configured with SetAllowLocalDefault applies.
+func WithAllowLocal(yesno bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.AllowLocal = &yesno
+		return nil
+	}
+}
+
+// WithMaxRepoSize aborts fetching data from a repository once the objects
+// received exceed size bytes. The size counts the uncompressed objects
+// stored, which are held in memory unless a clone path is set. Exceeding
+// the limit returns a *SizeLimitError.
+func WithMaxRepoSize(size int64) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if size < 0 {
+			return errors.New("maximum repository size cannot be negative")
+		}
+		o.MaxRepoSize = size
+		return nil
+	}
+}
+
+// WithMaxFileSize aborts fetching data from a repository when any of the
+// files received is larger than size bytes, returning a *SizeLimitError.
+func WithMaxFileSize(size int64) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if size < 0 {
+			return errors.New("maximum file size cannot be negative")
+		}
+		o.MaxFileSize = size
+		return nil
+	}
+}
+
+// WithBandwidthLimit limits the rate at which repository data is received
+// to bytesPerSecond. The limit applies to the packfiles transferred when
+// cloning or fetching, each operation is limited independently.
+func WithBandwidthLimit(bytesPerSecond int64) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if bytesPerSecond < 0 {
+			return errors.New("bandwidth limit cannot be negative")
+		}
+		o.BandwidthLimit = bytesPerSecond
+		return nil
+	}
+}
+
+// WithSymlinks sets how Download handles symbolic links in the repository:
+// SymlinksSkip (the default) ignores them, SymlinksReject fails the download,
+// SymlinksResolve writes the contents of the linked file and SymlinksPreserve
+// recreates the link. Links leading out of the repository or the destination
+// directory are never followed.
+func WithSymlinks(policy string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		switch policy {
+		case SymlinksSkip, SymlinksReject, SymlinksResolve, SymlinksPreserve:
+		default:
+			return fmt.Errorf("unknown symlink policy %q", policy)
+		}
+		o.Symlinks = policy
+		return nil
+	}
+}
+
+// WithIllegalNames sets how Download handles repository paths that cannot be
+// written on windows (reserved device names like CON or NUL, reserved
+// characters and names ending in a dot or space). The policy can be
+// IllegalNamesReject (the default), IllegalNamesSkip or IllegalNamesRename.
+// It has no effect on other systems.
+func WithIllegalNames(policy string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		switch policy {
+		case IllegalNamesReject, IllegalNamesSkip, IllegalNamesRename:
+		default:
+			return fmt.Errorf("unknown illegal names policy %q", policy)
+		}
+		o.IllegalNames = policy
+		return nil
+	}
+}
+
+// WithSync makes Download remove the files in the downloaded subtree of the
+// destination directory that do not exist in the source, leaving it as an
+// exact copy of the subtree at the locator ref. Files outside the subtree
+// are not touched.
+func WithSync(sync bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.Sync = sync
+		return nil
+	}
+}
+
+// WithInclude makes Download copy only the files matching at least one of
+// the glob patterns. Patterns are matched against the file path relative to
+// the locator subpath and ** matches any number of directories, for example
+// **/*.yaml matches all YAML files in the subtree.
+func WithInclude(patterns ...string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		for _, p := range patterns {
+			if err := validateGlob(p); err != nil {
+				return err
+			}
+		}
+		o.Include = append(o.Include, patterns...)
+		return nil
+	}
+}
+
+// WithExclude makes Download skip the files matching any of the glob
+// patterns (ie vendor/** or **/testdata/**). Exclusions take precedence
+// over the patterns set with WithInclude.
+func WithExclude(patterns ...string) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		for _, p := range patterns {
+			if err := validateGlob(p); err != nil {
+				return err
+			}
+		}
+		o.Exclude = append(o.Exclude, patterns...)
+		return nil
+	}
+}
+
+// WithExportIgnore makes Download skip the paths marked with the
+// export-ignore attribute in the repository .gitattributes files, producing
+// the same tree git archive would.
+func WithExportIgnore(exportIgnore bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.ExportIgnore = exportIgnore
+		return nil
+	}
+}
+
+// WithKeepGitDir makes Download write the repository data to a .git
+// directory in the destination, turning it into a working clone that tools
+// like git describe can operate on. HEAD is detached at the locator commit.
+// When only a subpath is downloaded, git reports the rest of the tree as
+// deleted.
+//
+// If the destination has a .git directory from a previous download of the
+// same repository, it is refreshed: only the missing objects are fetched and
+// only the files that changed are written.
+func WithKeepGitDir(keep bool) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.KeepGitDir = keep
+		return nil
+	}
+}
+
+// WithProgress makes the functions cloning repositories report their
+// progress to w: the repository being fetched, the transfer progress sent
+// by the remote and, in Download, the files being written.
+func WithProgress(w io.Writer) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.Progress = w
+		return nil
+	}
+}
+
+// WithArchiveMtime sets the modification time recorded for all the entries
+// of the archives written by CopyTar and CopyZip (ie from SOURCE_DATE_EPOCH).
+// By default entries get the commit date.
+func WithArchiveMtime(mtime time.Time) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		o.ArchiveMtime = mtime
+		return nil
+	}
+}
+
+// WithBundleDepth limits the history written by CopyBundle to the last
+// depth commits. Zero, the default, bundles the whole history.
+func WithBundleDepth(depth int) fnOpt {
+	return func(o *options) error {
+		if o == nil {
+			return errors.New("options are nil")
+		}
+		if depth < 0 {
+			return errors.New("bundle depth cannot be negative")
+		}
+		o.BundleDepth = depth
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"container/list"
+	"sync"
+)
+
+// parseKey identifies a parse result. Besides the locator, it holds the
+// options changing how locators are parsed.
+type parseKey struct {
+	locator     Locator
+	refIsBranch bool
+	asOf        int64
+	lineStart   int
+	lineEnd     int
+}
+
+func newParseKey(l Locator, opts *options) parseKey {
+	k := parseKey{
+		locator:     l,
+		refIsBranch: opts.RefIsBranch,
+		lineStart:   opts.LineStart,
+		lineEnd:     opts.LineEnd,
+	}
+	if !opts.RefAsOf.IsZero() {
+		k.asOf = opts.RefAsOf.UnixNano()
+	}
+	return k
+}
+
+// parseEntry is an element of the LRU list of the parse cache
+type parseEntry struct {
+	key        parseKey
+	components Components
+}
+
+// parseCache is an LRU cache of the components of parsed locators. A cache
+// with zero capacity is disabled.
+type parseCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[parseKey]*list.Element
+	order    *list.List // Most recently used first
+}
+
+func newParseCache(capacity int) *parseCache {
+	pc := &parseCache{
+		entries: map[parseKey]*list.Element{},
+		order:   list.New(),
+	}
+	pc.resize(capacity)
+	return pc
+}
+
+// parseResults caches the results of Locator.Parse, see SetParseCacheSize
+var parseResults = newParseCache(0)
+
+// SetParseCacheSize caches the results of parsing up to size locators, which
+// saves parsing them again when batch workloads handle the same locators
+// many times (ie in CopyFileGroup and then CloneRepository). The cache is
+// disabled by default, a size of zero disables it and drops its entries.
+// Its hits and misses are reported to the Metrics set with WithMetrics.
+func SetParseCacheSize(size int) {
+	parseResults.resize(size)
+}
+
+// resize changes the capacity of the cache, evicting the least recently
+// used entries that don't fit
+func (pc *parseCache) resize(capacity int) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.capacity = max(capacity, 0)
+	if pc.capacity == 0 {
+		clear(pc.entries)
+		pc.order.Init()
+		return
+	}
+	pc.evict()
+}
+
+// evict drops the least recently used entries over the capacity. The lock
+// must be held by the caller.
+func (pc *parseCache) evict() {
+	for pc.order.Len() > pc.capacity {
+		e := pc.order.Back()
+		pc.order.Remove(e)
+		delete(pc.entries, e.Value.(*parseEntry).key) //nolint:forcetypeassert
+	}
+}
+
+// len returns the number of entries in the cache
+func (pc *parseCache) len() int {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return pc.order.Len()
+}
+
+// parse returns the components of a locator from the cache, parsing it if
+// not found. Callers get their own copy of the components to modify. Only
+// successful results are cached.
+func (pc *parseCache) parse(l Locator, opts *options) (*Components, error) {
+	pc.mu.Lock()
+	if pc.capacity == 0 {
+		pc.mu.Unlock()
+		return l.parse(opts)
+	}
+
+	key := newParseKey(l, opts)
+	if e, ok := pc.entries[key]; ok {
+		pc.order.MoveToFront(e)
+		c := e.Value.(*parseEntry).components //nolint:forcetypeassert
+		pc.mu.Unlock()
+		if opts.Metrics != nil {
+			opts.Metrics.ParseCacheLookup(true)
+		}
+		// Mirrors don't change the parsed components, they are mapped
+		// again on each hit.
+		c.setMirror(opts)
+		return &c, nil
+	}
+	pc.mu.Unlock()
+	if opts.Metrics != nil {
+		opts.Metrics.ParseCacheLookup(false)
+	}
+
+	c, err := l.parse(opts)
+	if err != nil {
+		return nil, err
+	}
+	entry := &parseEntry{key: key, components: *c}
+	entry.components.mirror = ""
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if pc.capacity == 0 {
+		return c, nil
+	}
+	if e, ok := pc.entries[key]; ok {
+		e.Value = entry
+		pc.order.MoveToFront(e)
+		return c, nil
+	}
+	pc.entries[key] = pc.order.PushFront(entry)
+	pc.evict()
+	return c, nil
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCache(t *testing.T) {
+	t.Parallel()
+	const (
+		locA = Locator("git+https://github.com/example/a@main#README.md")
+		locB = Locator("git+https://github.com/example/b@v1")
+		locC = Locator("git+https://github.com/example/c")
+	)
+	pc := newParseCache(2)
+	metrics := &testMetrics{}
+	opts := defaultOptions
+	require.NoError(t, WithMetrics(metrics)(&opts))
+	lookups := func(hits, misses int) {
+		t.Helper()
+		metrics.mu.Lock()
+		defer metrics.mu.Unlock()
+		require.Equal(t, hits, metrics.hits, "hits")
+		require.Equal(t, misses, metrics.misses, "misses")
+	}
+
+	c, err := pc.parse(locA, &opts)
+	require.NoError(t, err)
+	require.Equal(t, "README.md", c.SubPath)
+	require.Equal(t, 1, pc.len())
+	lookups(0, 1)
+
+	// Changing the returned components does not alter the cached copy
+	c.SubPath = "changed"
+	c, err = pc.parse(locA, &opts)
+	require.NoError(t, err)
+	require.Equal(t, "README.md", c.SubPath)
+	lookups(1, 1)
+
+	// Options changing the result are part of the key
+	branchOpts := opts
+	require.NoError(t, WithRefAsBranch(true)(&branchOpts))
+	c, err = pc.parse(locA, &branchOpts)
+	require.NoError(t, err)
+	require.Equal(t, "main", c.Branch)
+	lookups(1, 2)
+
+	// Mirrors are mapped on hits too
+	mirrorOpts := opts
+	require.NoError(t, WithMirrors(map[string]string{"github.com": "mirror.example.com"})(&mirrorOpts))
+	c, err = pc.parse(locA, &mirrorOpts)
+	require.NoError(t, err)
+	require.Equal(t, "mirror.example.com", c.mirror)
+	c, err = pc.parse(locA, &opts)
+	require.NoError(t, err)
+	require.Empty(t, c.mirror)
+
+	// The least recently used entries are evicted
+	_, err = pc.parse(locB, &opts)
+	require.NoError(t, err)
+	_, err = pc.parse(locC, &opts)
+	require.NoError(t, err)
+	require.Equal(t, 2, pc.len())
+	_, err = pc.parse(locA, &opts)
+	require.NoError(t, err)
+	lookups(3, 5)
+
+	// Errors are not cached
+	_, err = pc.parse("", &opts)
+	require.Error(t, err)
+	require.Equal(t, 2, pc.len())
+
+	// Resizing to zero disables the cache
+	pc.resize(0)
+	_, err = pc.parse(locC, &opts)
+	require.NoError(t, err)
+	require.Zero(t, pc.len())
+	lookups(3, 6)
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// GetPaths reads several files from the repository referenced by a single
+// locator. The repository is parsed and cloned once and each path is read
+// relative to the locator subpath. Paths may carry a line range suffix just
+// as locator fragments (ie main.go#L10-L42).
+func GetPaths[T ~string](locator T, paths []string, funcs ...fnOpt) ([][]byte, error) {
+	buffers := make([]io.Writer, len(paths))
+	for i := range paths {
+		buffers[i] = &bytes.Buffer{}
+	}
+
+	if err := CopyPaths(locator, paths, buffers, funcs...); err != nil {
+		return nil, err
+	}
+
+	ret := make([][]byte, 0, len(paths))
+	for _, w := range buffers {
+		ret = append(ret, w.(*bytes.Buffer).Bytes()) //nolint:errcheck,forcetypeassert
+	}
+	return ret, nil
+}
+
+// CopyPaths copies several files from the repository referenced by the
+// locator to the specified writers. Expected digests, if set, are checked
+// against each file in order. Failures to read a path are returned in an
+// ErrorList with one entry per path.
+func CopyPaths[T ~string](locator T, paths []string, writers []io.Writer, funcs ...fnOpt) error {
+	if len(paths) != len(writers) {
+		return errors.New("number of writers does not match the number of paths")
+	}
+
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return err
+		}
+	}
+	if len(opts.ExpectedDigests) != 0 && len(opts.ExpectedDigests) != len(paths) {
+		return errors.New("number of expected digests does not match the number of paths")
+	}
+
+	l := Locator(locator)
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return fmt.Errorf("parsing locator: %w", err)
+	}
+
+	// Parse the paths before cloning to catch errors early
+	files := make([]*Components, len(paths))
+	for i, p := range paths {
+		files[i] = &Components{}
+		if err := files[i].setSubPath(p, &opts); err != nil {
+			return fmt.Errorf("parsing path %d: %w", i, err)
+		}
+		sub := path.Join(strings.Trim(components.SubPath, "/"), strings.Trim(files[i].SubPath, "/"))
+		if sub == "" || sub == "." || sub == ".." || strings.HasPrefix(sub, "../") {
+			return fmt.Errorf("invalid path %d: %q", i, p)
+		}
+		files[i].SubPath = sub
+	}
+
+	// Files are read from the object store, skip the checkout
+	opts.noCheckout = true
+	cloned, err := cloneRepo(l, &opts, funcs...)
+	if err != nil {
+		return fmt.Errorf("cloning repository: %w", err)
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	errs := make([]error, len(paths))
+	var failed bool
+	for i, f := range files {
+		digest := ""
+		if len(opts.ExpectedDigests) != 0 {
+			digest = opts.ExpectedDigests[i]
+		}
+		if errs[i] = copyRepoFile(cloned, f, writers[i], digest); errs[i] != nil {
+			failed = true
+		}
+	}
+	if failed {
+		return &ErrorList{Errors: errs}
+	}
+	return nil
+}
+
+// copyRepoFile copies the file in the subpath of the components from the
+// objects of a cloned repository, applying the line range and verifying
+// the digest if not empty.
+func copyRepoFile(cloned *clonedRepo, c *Components, w io.Writer, digest string) error {
+	dw, err := newDigestWriter(w, digest)
+	if err != nil {
+		return err
+	}
+
+	f, err := openRepoFile(cloned, c.SubPath)
+	if err != nil {
+		return fmt.Errorf("opening %q: %w", c.SubPath, err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	lw := newLineRangeWriter(dw, c.LineStart, c.LineEnd)
+	if _, err := io.Copy(lw, f); err != nil {
+		return fmt.Errorf("copying %q: %w", c.SubPath, err)
+	}
+	if err := verifyLineRange(lw); err != nil {
+		return fmt.Errorf("reading %q: %w", c.SubPath, err)
+	}
+
+	locator := *cloned.Components
+	locator.SubPath, locator.LineStart, locator.LineEnd = c.SubPath, c.LineStart, c.LineEnd
+	return dw.verify(locator.String())
+}
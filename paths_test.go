@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPaths(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		"a.txt":       "a\n",
+		"b/c.txt":     "c1\nc2\nc3\n",
+		"b/d/e.txt":   "e\n",
+		"README.md":   "readme\n",
+		"SECURITY.md": "security\n",
+	})
+
+	t.Run("root", func(t *testing.T) {
+		t.Parallel()
+		data, err := GetPaths(fileLocator(repoDir, commit, ""), []string{"a.txt", "b/c.txt#L2", "SECURITY.md"})
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("a\n"), []byte("c2\n"), []byte("security\n")}, data)
+	})
+
+	t.Run("relative-to-subpath", func(t *testing.T) {
+		t.Parallel()
+		data, err := GetPaths(fileLocator(repoDir, commit, "b"), []string{"c.txt", "d/e.txt"})
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("c1\nc2\nc3\n"), []byte("e\n")}, data)
+	})
+
+	t.Run("digests", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetPaths(
+			fileLocator(repoDir, commit, ""), []string{"a.txt", "README.md"},
+			WithExpectedDigest("", sha256Digest("readme\n")),
+		)
+		require.NoError(t, err)
+
+		_, err = GetPaths(
+			fileLocator(repoDir, commit, ""), []string{"a.txt", "README.md"},
+			WithExpectedDigest("", sha256Digest("nope")),
+		)
+		var mismatch *DigestMismatchError
+		require.True(t, errors.As(err, &mismatch))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetPaths(fileLocator(repoDir, commit, ""), []string{"a.txt", "nope.txt"})
+		require.Error(t, err)
+		var list *ErrorList
+		require.True(t, errors.As(err, &list))
+		require.Len(t, list.Errors, 2)
+		require.NoError(t, list.Errors[0])
+		require.Error(t, list.Errors[1])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, p := range []string{"", "../x", "a.txt#L0"} {
+			_, err := GetPaths(fileLocator(repoDir, commit, ""), []string{p})
+			require.Error(t, err, p)
+		}
+	})
+}
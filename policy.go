@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Special entries in VCS policy lists
+const (
+	vcsPolicyAll = "all"
+	vcsPolicyOff = "off"
+)
+
+// PolicyViolationError is returned when a locator is not allowed by the
+// configured policy.
+type PolicyViolationError struct {
+	// Locator is the locator that was rejected
+	Locator string
+
+	// Hostname, Tool and Transport are the parts of the locator checked
+	Hostname  string
+	Tool      string
+	Transport string
+
+	// Rule is the policy rule that rejected the locator
+	Rule string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf(
+		"locator %s not allowed by policy rule %q (host %q, tool %q, transport %q)",
+		e.Locator, e.Rule, e.Hostname, e.Tool, e.Transport,
+	)
+}
+
+// vcsPolicyRule is a host pattern with the tools and transports allowed
+type vcsPolicyRule struct {
+	pattern string
+	allowed []string
+	raw     string
+}
+
+// vcsPolicy is a list of rules, the first one matching a host applies
+type vcsPolicy []vcsPolicyRule
+
+// parseVCSPolicy parses a policy string in a format modeled after GOVCS: a
+// comma separated list of pattern:list rules. Patterns are globs matched
+// against the locator hostname, the lists are | separated tools (git) or
+// tool+transport pairs (git+https), all or off.
+func parseVCSPolicy(spec string) (vcsPolicy, error) {
+	policy := vcsPolicy{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, list, ok := strings.Cut(item, ":")
+		pattern, list = strings.TrimSpace(pattern), strings.TrimSpace(list)
+		if !ok || pattern == "" || list == "" {
+			return nil, fmt.Errorf("malformed VCS policy rule %q, expected pattern:list", item)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid host pattern in VCS policy rule %q: %w", item, err)
+		}
+
+		allowed := []string{}
+		for _, entry := range strings.Split(list, "|") {
+			entry = strings.ToLower(strings.TrimSpace(entry))
+			if entry == "" {
+				return nil, fmt.Errorf("empty entry in VCS policy rule %q", item)
+			}
+			if (entry == vcsPolicyAll || entry == vcsPolicyOff) && len(strings.Split(list, "|")) > 1 {
+				return nil, fmt.Errorf("%s cannot be combined with other entries in VCS policy rule %q", entry, item)
+			}
+			allowed = append(allowed, entry)
+		}
+		policy = append(policy, vcsPolicyRule{pattern: strings.ToLower(pattern), allowed: allowed, raw: item})
+	}
+	return policy, nil
+}
+
+// check returns a *PolicyViolationError if the policy does not allow the
+// locator components. Locators whose host matches no rule are allowed.
+func (p vcsPolicy) check(l Locator, components *Components) error {
+	host := strings.ToLower(components.Hostname)
+	tool := components.Tool
+	if tool == "" {
+		tool = ToolGit
+	}
+
+	for _, rule := range p {
+		if ok, _ := path.Match(rule.pattern, host); !ok { //nolint:errcheck // Patterns are validated when parsing
+			continue
+		}
+		if rule.allowed[0] == vcsPolicyAll ||
+			slices.Contains(rule.allowed, tool) ||
+			slices.Contains(rule.allowed, tool+"+"+components.Transport) {
+			return nil
+		}
+		return &PolicyViolationError{
+			Locator:   string(l),
+			Hostname:  components.Hostname,
+			Tool:      tool,
+			Transport: components.Transport,
+			Rule:      rule.raw,
+		}
+	}
+	return nil
+}
+
+// checkHostLists returns a *PolicyViolationError if the host is blocked or
+// not in the list of allowed hosts. Blocked hosts take precedence. Local
+// locators have no host and are not subject to the lists.
+func checkHostLists(l Locator, hostname string, opts *options) error {
+	if hostname == "" {
+		return nil
+	}
+	host := strings.ToLower(hostname)
+
+	if pattern := matchHostPattern(opts.BlockedHosts, host); pattern != "" {
+		return &PolicyViolationError{Locator: string(l), Hostname: hostname, Rule: "blocked host " + pattern}
+	}
+
+	if len(opts.AllowedHosts) > 0 && matchHostPattern(opts.AllowedHosts, host) == "" {
+		return &PolicyViolationError{Locator: string(l), Hostname: hostname, Rule: "host not in allowed list"}
+	}
+	return nil
+}
+
+// matchHostPattern returns the first pattern in the list matching the host
+func matchHostPattern(patterns []string, host string) string {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, host); ok { //nolint:errcheck // Patterns are validated in the options
+			return pattern
+		}
+	}
+	return ""
+}
+
+// normalizeHostPatterns lowercases host patterns and checks they are valid
+func normalizeHostPatterns(patterns []string) ([]string, error) {
+	ret := []string{}
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			return nil, fmt.Errorf("empty host pattern")
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid host pattern %q: %w", p, err)
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
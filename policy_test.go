@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVCSPolicy(t *testing.T) {
+	t.Parallel()
+	for spec, mustErr := range map[string]bool{
+		"github.com:git":                     false,
+		"github.com:git+https|git+ssh,*:off": false,
+		"*.example.com:all, *:git":           false,
+		"":                                   false,
+		"github.com":                         true,
+		"github.com:":                        true,
+		":git":                               true,
+		"github.com:git|off":                 true,
+		"github.com:git||hg":                 true,
+		"[:git":                              true,
+	} {
+		_, err := parseVCSPolicy(spec)
+		if mustErr {
+			require.Error(t, err, spec)
+		} else {
+			require.NoError(t, err, spec)
+		}
+	}
+}
+
+func TestVCSPolicyCheck(t *testing.T) {
+	t.Parallel()
+	policy, err := parseVCSPolicy("github.com:git+https,*.example.com:git,gitlab.com:all,evil.com:off")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		locator string
+		allowed bool
+	}{
+		{"git+https://github.com/example/test", true},
+		{"https://github.com/example/test", true},
+		{"git+ssh://github.com/example/test", false},
+		{"git+ssh://git.example.com/test", true},
+		{"hg+https://hg.example.com/test", false},
+		{"git+ssh://gitlab.com/example/test", true},
+		{"git+https://evil.com/example/test", false},
+		{"git+https://other.com/example/test", true},
+		{"file:///tmp/repo", true},
+	} {
+		c, err := Locator(tc.locator).Parse()
+		require.NoError(t, err)
+		err = policy.check(Locator(tc.locator), c)
+		if tc.allowed {
+			require.NoError(t, err, tc.locator)
+			continue
+		}
+		var pv *PolicyViolationError
+		require.True(t, errors.As(err, &pv), tc.locator)
+		require.Equal(t, tc.locator, pv.Locator)
+	}
+}
+
+func TestWithVCSPolicy(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	locator := fileLocator(repoDir, commitHash, "hello.txt")
+
+	// Local repositories have no hostname, they only match catch-all rules
+	require.NoError(t, CopyFile(locator, io.Discard, noAuth, WithVCSPolicy("github.com:off")))
+
+	err := CopyFile(locator, io.Discard, noAuth, WithVCSPolicy("github.com:git,*:off"))
+	var pv *PolicyViolationError
+	require.True(t, errors.As(err, &pv))
+	require.Equal(t, "*:off", pv.Rule)
+
+	_, err = ListRemoteRefs(locator, noAuth, WithVCSPolicy("*:git+https"))
+	require.True(t, errors.As(err, &pv))
+	require.Equal(t, TransportFile, pv.Transport)
+
+	_, err = Locator(fileLocator(repoDir, "master", "")).Resolve(noAuth, WithVCSPolicy("*:off"))
+	require.True(t, errors.As(err, &pv))
+}
+
+func TestHostLists(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name    string
+		host    string
+		allowed []string
+		blocked []string
+		mustErr bool
+	}{
+		{"no-lists", "github.com", nil, nil, false},
+		{"allowed", "github.com", []string{"github.com", "gitlab.com"}, nil, false},
+		{"allowed-glob", "git.example.com", []string{"*.example.com"}, nil, false},
+		{"not-allowed", "evil.com", []string{"github.com"}, nil, true},
+		{"blocked", "evil.com", nil, []string{"evil.com"}, true},
+		{"blocked-case", "Evil.com", nil, []string{"EVIL.com"}, true},
+		{"blocked-wins", "bad.example.com", []string{"*.example.com"}, []string{"bad.example.com"}, true},
+		{"local", "", []string{"github.com"}, []string{"*"}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			opts := defaultOptions
+			if tc.allowed != nil {
+				require.NoError(t, WithAllowedHosts(tc.allowed...)(&opts))
+			}
+			if tc.blocked != nil {
+				require.NoError(t, WithBlockedHosts(tc.blocked...)(&opts))
+			}
+			err := checkHostLists("test", tc.host, &opts)
+			if !tc.mustErr {
+				require.NoError(t, err)
+				return
+			}
+			var pv *PolicyViolationError
+			require.True(t, errors.As(err, &pv))
+		})
+	}
+
+	t.Run("invalid-pattern", func(t *testing.T) {
+		t.Parallel()
+		opts := defaultOptions
+		require.Error(t, WithAllowedHosts("[")(&opts))
+		require.Error(t, WithBlockedHosts("")(&opts))
+	})
+
+	t.Run("enforced-before-fetching", func(t *testing.T) {
+		t.Parallel()
+		var pv *PolicyViolationError
+		_, err := ListRemoteRefs("git+https://git.example.com/org/repo", WithSystemCredentials(false), WithBlockedHosts("*.example.com"))
+		require.True(t, errors.As(err, &pv))
+		err = CopyFile("git+https://git.example.com/org/repo#file.txt", io.Discard, WithAllowedHosts("github.com"))
+		require.True(t, errors.As(err, &pv))
+		_, err = ResolveGoModule("go.example.com/mod", WithBlockedHosts("go.example.com"))
+		require.True(t, errors.As(err, &pv))
+	})
+}
+
+func TestWithAllowLocal(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	locator := fileLocator(repoDir, commitHash, "hello.txt")
+
+	require.NoError(t, CopyFile(locator, io.Discard, noAuth, WithAllowLocal(true)))
+
+	var pv *PolicyViolationError
+	err := CopyFile(locator, io.Discard, noAuth, WithAllowLocal(false))
+	require.True(t, errors.As(err, &pv))
+	require.Equal(t, TransportFile, pv.Transport)
+
+	_, err = ListRemoteRefs(locator, noAuth, WithAllowLocal(false))
+	require.True(t, errors.As(err, &pv))
+
+	// The package default applies when the option is not set
+	opts := defaultOptions
+	require.True(t, opts.allowLocal())
+	require.NoError(t, WithAllowLocal(false)(&opts))
+	require.False(t, opts.allowLocal())
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// credentialPrompt asks for the credentials of the HTTPS remotes that
+// require them. The answers are remembered per host.
+type credentialPrompt struct {
+	fn      func(host string) (user, secret string, err error)
+	mu      sync.Mutex
+	answers map[string]*githttp.BasicAuth
+}
+
+func newCredentialPrompt(fn func(host string) (user, secret string, err error)) *credentialPrompt {
+	return &credentialPrompt{fn: fn, answers: map[string]*githttp.BasicAuth{}}
+}
+
+// answer returns the credentials given for a host, nil if none
+func (p *credentialPrompt) answer(host string) transport.AuthMethod {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if a, ok := p.answers[strings.ToLower(host)]; ok {
+		return a
+	}
+	return nil
+}
+
+// ask runs the prompt for the credentials of a host, unless they were
+// answered since the operation that failed started
+func (p *credentialPrompt) ask(host string, before transport.AuthMethod) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if a, ok := p.answers[strings.ToLower(host)]; ok && transport.AuthMethod(a) != before {
+		return nil
+	}
+
+	user, secret, err := p.fn(host)
+	if err != nil {
+		return fmt.Errorf("reading credentials for %s: %w", host, err)
+	}
+	p.answers[strings.ToLower(host)] = &githttp.BasicAuth{Username: user, Password: secret}
+	return nil
+}
+
+// promptHost returns the host asked for credentials when fetching the
+// repository of the components, or an empty string when the remote is not
+// reached over HTTPS
+func promptHost(c *Components) string {
+	u, err := url.Parse(c.fetchURL())
+	if err != nil || u.Scheme != "https" {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// isAuthError checks if the remote rejected an operation for missing or
+// wrong credentials
+func isAuthError(err error) bool {
+	return errors.Is(err, transport.ErrAuthenticationRequired) ||
+		errors.Is(err, transport.ErrAuthorizationFailed)
+}
+
+// withCredentialPrompt runs an operation talking to the remote of the
+// components. When the remote rejects it asking for credentials, the user
+// is prompted for them and the operation runs once more. The operation
+// must get its credentials from prepareRemote, which returns the answers
+// of the prompt. Credentials set explicitly in the options are never
+// replaced.
+func withCredentialPrompt[T any](c *Components, opts *options, op func() (T, error)) (T, error) {
+	prompt := opts.credentialPrompt()
+	host := promptHost(c)
+	if prompt == nil || host == "" || opts.AuthMethod != nil || opts.TokenSource != nil ||
+		opts.HttpUsername != "" || opts.HttpPassword != "" {
+		return op()
+	}
+
+	before := prompt.answer(host)
+	ret, err := op()
+	if !isAuthError(err) {
+		return ret, err
+	}
+	if err := prompt.ask(host, before); err != nil {
+		var zero T
+		return zero, err
+	}
+	return op()
+}
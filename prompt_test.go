@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+// promptedAuth runs an operation through withCredentialPrompt. The
+// operation fails with the error returned by check for the credentials it
+// gets from prepareRemote and returns them otherwise.
+func promptedAuth(t *testing.T, locator string, check func(transport.AuthMethod) error, funcs ...fnOpt) (transport.AuthMethod, error) {
+	t.Helper()
+	opts := defaultOptions
+	for _, fn := range funcs {
+		require.NoError(t, fn(&opts))
+	}
+	l := Locator(locator)
+	c, err := l.Parse()
+	require.NoError(t, err)
+	return withCredentialPrompt(c, &opts, func() (transport.AuthMethod, error) {
+		auth, err := prepareRemote(l, c, &opts)
+		if err != nil {
+			return nil, err
+		}
+		return auth, check(auth)
+	})
+}
+
+func TestCredentialPrompt(t *testing.T) {
+	t.Parallel()
+	var prompts atomic.Int32
+	prompt := func(host string) (string, string, error) {
+		prompts.Add(1)
+		if host != "git.example.com" {
+			return "", "", errors.New("unexpected host")
+		}
+		return "user", "pass", nil
+	}
+	funcs := []fnOpt{WithSystemCredentials(false), WithCredentialPrompt(prompt)}
+	private := func(auth transport.AuthMethod) error {
+		if auth == nil {
+			return transport.ErrAuthenticationRequired
+		}
+		return nil
+	}
+
+	// Operations that succeed don't prompt
+	a, err := promptedAuth(t, "git+https://git.example.com/org/public", func(transport.AuthMethod) error { return nil }, funcs...)
+	require.NoError(t, err)
+	require.Nil(t, a)
+	require.Zero(t, prompts.Load())
+
+	// Rejected operations prompt once and run again, the answer is reused
+	for range 2 {
+		a, err = promptedAuth(t, "git+https://git.example.com/org/private", private, funcs...)
+		require.NoError(t, err)
+		require.Equal(t, &githttp.BasicAuth{Username: "user", Password: "pass"}, a)
+	}
+	require.Equal(t, int32(1), prompts.Load())
+
+	// Rejected answers prompt again, the operation only runs once more
+	var runs int
+	_, err = promptedAuth(t, "git+https://git.example.com/org/private", func(transport.AuthMethod) error {
+		runs++
+		return transport.ErrAuthorizationFailed
+	}, funcs...)
+	require.ErrorIs(t, err, transport.ErrAuthorizationFailed)
+	require.Equal(t, 2, runs)
+	require.Equal(t, int32(2), prompts.Load())
+
+	// Errors of the prompt are returned
+	_, err = promptedAuth(t, "git+https://other.example.com/org/private", private, funcs...)
+	require.ErrorContains(t, err, "unexpected host")
+	require.Equal(t, int32(3), prompts.Load())
+
+	// Explicit credentials are never replaced
+	_, err = promptedAuth(t, "git+https://git.example.com/org/private", func(transport.AuthMethod) error {
+		return transport.ErrAuthorizationFailed
+	}, append(funcs, WithAuthMethod(&githttp.TokenAuth{Token: "secret"}))...)
+	require.ErrorIs(t, err, transport.ErrAuthorizationFailed)
+	require.Equal(t, int32(3), prompts.Load())
+
+	require.Error(t, WithCredentialPrompt(nil)(&options{}))
+}
@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Provider fetches data of repositories through the API of the forge
+// hosting them, handling its URL layout and the credentials it expects.
+// Providers are selected by the hostname of the locators, see
+// RegisterProvider and WithProvider.
+type Provider interface {
+	// Name identifies the forge (github, gitlab, etc)
+	Name() string
+
+	// OpenFile requests the raw contents of the file in the subpath of the
+	// components at their commit. The response must have a 200 status.
+	OpenFile(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error)
+
+	// OpenArchive requests a gzipped tarball of the tree of the commit,
+	// with the entries nested in a top level directory. The response must
+	// have a 200 status.
+	OpenArchive(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error)
+}
+
+// EnvTokenProvider is implemented by the providers that know the
+// environment variables where the tools of their forge read API tokens from.
+// The tokens are used when no credentials are set in the options.
+type EnvTokenProvider interface {
+	// EnvToken returns the credentials set in the environment for a host,
+	// nil if there are none
+	EnvToken(host string) transport.AuthMethod
+}
+
+// TreeProvider is implemented by the providers that can list the files in
+// the tree of a commit. Unless export-ignore is honored, Download only uses
+// the archives of these forges, as it needs to check all the .gitattributes
+// files of the commit first.
+type TreeProvider interface {
+	// ListFiles returns the paths of all the files in the tree of the
+	// commit of the components
+	ListFiles(client *http.Client, auth transport.AuthMethod, c *Components) ([]string, error)
+}
+
+// providerRule assigns a provider to the hosts matching a pattern
+type providerRule struct {
+	pattern  string
+	provider Provider
+}
+
+var (
+	// builtinProviders are the providers of the public forges
+	builtinProviders = []providerRule{
+		{pattern: gitHubHost, provider: GitHubProvider{}},
+		{pattern: gitLabHost, provider: GitLabProvider{}},
+		{pattern: bitbucketCloudHost, provider: BitbucketCloudProvider{}},
+		{pattern: codebergHost, provider: GiteaProvider{}},
+		{pattern: sourceHutHost, provider: SourceHutProvider{}},
+	}
+
+	// providerKinds are the built in providers, looked up by their name
+	// to alias hosts to them
+	providerKinds = []Provider{
+		GitHubProvider{}, GitLabProvider{}, GiteaProvider{},
+		BitbucketCloudProvider{}, BitbucketServerProvider{}, SourceHutProvider{},
+	}
+
+	// registeredProviders are the rules added with RegisterProvider, the
+	// latest first
+	registeredProviders []providerRule
+	providersMu         sync.RWMutex
+)
+
+// newProviderRules returns the rules assigning a provider to hostname
+// patterns (ie *.example.com)
+func newProviderRules(p Provider, patterns ...string) ([]providerRule, error) {
+	if p == nil {
+		return nil, errors.New("provider is nil")
+	}
+	patterns, err := normalizeHostPatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]providerRule, 0, len(patterns))
+	for _, pattern := range patterns {
+		rules = append(rules, providerRule{pattern: pattern, provider: p})
+	}
+	return rules, nil
+}
+
+// RegisterProvider makes the package fetch data from the hosts matching a
+// glob pattern (ie *.example.com) through a provider. Providers registered
+// later take precedence over the earlier ones and the built in providers,
+// the ones set with WithProvider take precedence over all of them.
+func RegisterProvider(pattern string, p Provider) error {
+	rules, err := newProviderRules(p, pattern)
+	if err != nil {
+		return err
+	}
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	registeredProviders = append(rules, registeredProviders...)
+	return nil
+}
+
+// matchProvider returns the provider of the first rule matching the host
+func matchProvider(rules []providerRule, host string) Provider {
+	for _, r := range rules {
+		if ok, _ := path.Match(r.pattern, host); ok { //nolint:errcheck // Patterns are validated when added
+			return r.provider
+		}
+	}
+	return nil
+}
+
+// providerNamed returns the registered or built in provider with a name
+func providerNamed(name string) Provider {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	for _, r := range registeredProviders {
+		if strings.EqualFold(r.provider.Name(), name) {
+			return r.provider
+		}
+	}
+	for _, p := range providerKinds {
+		if strings.EqualFold(p.Name(), name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// lookupProvider returns the provider assigned to a host in the options,
+// the registry or the built in providers, in that order
+func lookupProvider(host string, opts *options) Provider {
+	host = strings.ToLower(host)
+	if p := matchProvider(opts.Providers, host); p != nil {
+		return p
+	}
+
+	providersMu.RLock()
+	p := matchProvider(registeredProviders, host)
+	providersMu.RUnlock()
+	if p != nil {
+		return p
+	}
+	return matchProvider(builtinProviders, host)
+}
+
+// envTokenAuth returns the credentials set in the environment for the
+// forge of a host. Hosts are not probed, only the providers assigned to
+// them read the environment.
+func envTokenAuth(host string, opts *options) transport.AuthMethod {
+	if p, ok := lookupProvider(host, opts).(EnvTokenProvider); ok {
+		return p.EnvToken(host)
+	}
+	return nil
+}
+
+// firstEnv returns the value of the first environment variable set
+func firstEnv(vars ...string) string {
+	for _, v := range vars {
+		if value := os.Getenv(v); value != "" {
+			return value
+		}
+	}
+	return ""
+}
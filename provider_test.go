@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+// testProvider serves the files in a map
+type testProvider struct {
+	files map[string]string
+}
+
+func (testProvider) Name() string {
+	return "test"
+}
+
+func (p testProvider) OpenFile(_ *http.Client, _ transport.AuthMethod, c *Components) (*http.Response, error) {
+	data, ok := p.files[c.SubPath]
+	if !ok {
+		return nil, errors.New("file not found")
+	}
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Body:          io.NopCloser(strings.NewReader(data)),
+		ContentLength: int64(len(data)),
+	}, nil
+}
+
+func (testProvider) OpenArchive(*http.Client, transport.AuthMethod, *Components) (*http.Response, error) {
+	return nil, errors.New("archives not supported")
+}
+
+func TestProviderFor(t *testing.T) {
+	t.Parallel()
+	custom := testProvider{}
+
+	for _, tc := range []struct {
+		name   string
+		host   string
+		funcs  []fnOpt
+		expect Provider
+	}{
+		{"github", "github.com", nil, GitHubProvider{}},
+		{"github-enterprise", "ghe.example.com", []fnOpt{WithGitHubEnterprise("GHE.example.com")}, GitHubProvider{}},
+		{"gitlab", "gitlab.com", nil, GitLabProvider{}},
+		{"gitlab-self-managed", "gl.example.com", []fnOpt{WithGitLabHosts("gl.example.com")}, GitLabProvider{}},
+		{"bitbucket-cloud", "bitbucket.org", nil, BitbucketCloudProvider{}},
+		{"bitbucket-server", "bb.example.com", []fnOpt{WithBitbucketServerHosts("bb.example.com")}, BitbucketServerProvider{}},
+		{"codeberg", "codeberg.org", nil, GiteaProvider{}},
+		{"gitea", "gitea.example.com", []fnOpt{WithGiteaHosts("gitea.example.com")}, GiteaProvider{}},
+		{"sourcehut", "git.sr.ht", nil, SourceHutProvider{}},
+		{"sourcehut-alias", "sr.example.com", []fnOpt{WithHostAlias("sr.example.com", "sourcehut")}, SourceHutProvider{}},
+		{"unknown", "unknown.example.com", nil, nil},
+		{"pattern", "git.corp.example.com", []fnOpt{WithProvider("*.corp.example.com", custom)}, custom},
+		{"override", "github.com", []fnOpt{WithProvider("github.com", custom)}, custom},
+		{"alias", "git.corp.example.com", []fnOpt{WithHostAlias("*.corp.example.com", "GitHub")}, GitHubProvider{}},
+		{"alias-bitbucket", "bb.corp.example.com", []fnOpt{WithHostAlias("bb.corp.example.com", "bitbucket-server")}, BitbucketServerProvider{}},
+		{"first-match", "gl.example.com", []fnOpt{WithGitLabHosts("gl.example.com"), WithProvider("*.example.com", custom)}, GitLabProvider{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			opts := defaultOptions
+			for _, fn := range tc.funcs {
+				require.NoError(t, fn(&opts))
+			}
+			require.Equal(t, tc.expect, lookupProvider(tc.host, &opts))
+		})
+	}
+}
+
+func TestRegisterProvider(t *testing.T) {
+	t.Parallel()
+	custom := testProvider{files: map[string]string{"README.md": "registered\n"}}
+	require.NoError(t, RegisterProvider("*.registered.example.com", GitLabProvider{}))
+	require.NoError(t, RegisterProvider("git.registered.example.com", custom))
+	require.Error(t, RegisterProvider("[", custom))
+	require.Error(t, RegisterProvider("git.registered.example.com", nil))
+
+	opts := defaultOptions
+	require.Equal(t, Provider(custom), lookupProvider("GIT.registered.example.com", &opts))
+	require.Equal(t, Provider(GitLabProvider{}), lookupProvider("gl.registered.example.com", &opts))
+
+	// Options take precedence over the registry
+	require.NoError(t, WithGiteaHosts("git.registered.example.com")(&opts))
+	require.Equal(t, Provider(GiteaProvider{}), lookupProvider("git.registered.example.com", &opts))
+
+	var b bytes.Buffer
+	require.NoError(t, CopyFile("git+https://git.registered.example.com/org/repo@"+testCommit+"#README.md", &b))
+	require.Equal(t, "registered\n", b.String())
+}
+
+func TestWithProvider(t *testing.T) {
+	t.Parallel()
+	custom := testProvider{files: map[string]string{"dir/file.txt": "from the provider\n"}}
+	base := "git+https://code.example.com/org/repo@" + testCommit + "#"
+
+	var b bytes.Buffer
+	require.NoError(t, CopyFile(base+"dir/file.txt", &b, WithProvider("code.example.com", custom)))
+	require.Equal(t, "from the provider\n", b.String())
+
+	require.Error(t, WithProvider("", custom)(&options{}))
+	require.Error(t, WithHostAlias("code.example.com", "sourceforge")(&options{}))
+	require.Error(t, WithProvider("code.example.com", nil)(&options{}))
+}
+
+func TestEnvTokenAuth(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "public-token")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_ENTERPRISE_TOKEN", "enterprise-token")
+	t.Setenv("GITLAB_TOKEN", "gitlab-token")
+
+	for _, tc := range []struct {
+		name   string
+		host   string
+		funcs  []fnOpt
+		expect transport.AuthMethod
+	}{
+		{"github", "github.com", nil, &githttp.BasicAuth{Username: "x-access-token", Password: "public-token"}},
+		{"enterprise", "git.corp.example.com", []fnOpt{WithHostAlias("git.corp.example.com", "github")}, &githttp.BasicAuth{Username: "x-access-token", Password: "enterprise-token"}},
+		{"gitlab", "gl.corp.example.com", []fnOpt{WithHostAlias("gl.corp.example.com", "gitlab")}, &githttp.BasicAuth{Username: "oauth2", Password: "gitlab-token"}},
+		{"gitea", "codeberg.org", nil, nil},
+		{"unknown", "git.corp.example.com", nil, nil},
+		{"explicit", "github.com", []fnOpt{WithHttpAuth("user", "pass")}, &githttp.BasicAuth{Username: "user", Password: "pass"}},
+		{"no-system-credentials", "github.com", []fnOpt{WithSystemCredentials(false)}, nil},
+		{"auth-method", "github.com", []fnOpt{WithSystemCredentials(false), WithAuthMethod(&githttp.TokenAuth{Token: "t"})}, &githttp.TokenAuth{Token: "t"}},
+		{"mirror", "github.com", []fnOpt{WithMirrors(map[string]string{"github.com": "mirror.example.com"})}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := defaultOptions
+			for _, fn := range tc.funcs {
+				require.NoError(t, fn(&opts))
+			}
+			l := Locator("git+https://" + tc.host + "/org/repo")
+			c, err := l.Parse(tc.funcs...)
+			require.NoError(t, err)
+			auth, err := prepareRemote(l, c, &opts)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, auth)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+	"golang.org/x/mod/module"
+)
+
+// purlHosts maps the purl types of code hosting services to their hostnames
+var purlHosts = map[string]string{
+	packageurl.TypeGithub:    "github.com",
+	packageurl.TypeGitlab:    "gitlab.com",
+	packageurl.TypeBitbucket: "bitbucket.org",
+}
+
+// majorVersionSuffixRegex matches the major version suffix of go module paths
+var majorVersionSuffixRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// purlQualifierVCSURL is the purl qualifier carrying the VCS locator of
+// a package
+const purlQualifierVCSURL = "vcs_url"
+
+// FromPurl converts a package URL into a VCS locator. Supported purls are:
+//
+//   - pkg:github, pkg:gitlab and pkg:bitbucket purls
+//   - pkg:golang purls of modules hosted in GitHub, GitLab, Bitbucket,
+//     Codeberg or SourceHut.
+//     Pseudo-versions are converted to their commit and versions of modules
+//     in subdirectories to their prefixed tags (ie sub/v1.0.0).
+//   - any purl with a vcs_url qualifier
+func FromPurl(purl string) (Locator, error) {
+	p, err := packageurl.FromString(purl)
+	if err != nil {
+		return "", fmt.Errorf("parsing purl: %w", err)
+	}
+
+	switch p.Type {
+	case packageurl.TypeGithub, packageurl.TypeGitlab, packageurl.TypeBitbucket:
+		if p.Namespace == "" {
+			return "", fmt.Errorf("%s purl has no namespace", p.Type)
+		}
+		c := &Components{
+			Tool:      "git",
+			Transport: "https",
+			Hostname:  purlHosts[p.Type],
+			RepoPath:  "/" + p.Namespace + "/" + p.Name,
+			RefString: p.Version,
+			SubPath:   p.Subpath,
+		}
+		return Locator(c.String()), nil
+	case packageurl.TypeGolang:
+		return goPurlToLocator(&p)
+	}
+
+	if vcsURL, ok := p.Qualifiers.Map()[purlQualifierVCSURL]; ok && vcsURL != "" {
+		return Locator(vcsURL), nil
+	}
+	return "", fmt.Errorf("unable to convert purls of type %q to a VCS locator", p.Type)
+}
+
+// goPurlToLocator converts a golang purl into a VCS locator
+func goPurlToLocator(p *packageurl.PackageURL) (Locator, error) {
+	modPath := strings.Trim(p.Namespace+"/"+p.Name, "/")
+	parts := strings.Split(modPath, "/")
+
+	if !isGoRepoHost(parts[0]) || len(parts) < 3 {
+		return "", fmt.Errorf("unable to determine the repository of go module %q", modPath)
+	}
+
+	moduleDir := goModuleDir(parts[3:])
+	ref, err := goModuleRef(p.Version, moduleDir)
+	if err != nil {
+		return "", err
+	}
+
+	c := &Components{
+		Tool:      "git",
+		Transport: "https",
+		Hostname:  parts[0],
+		RepoPath:  "/" + parts[1] + "/" + parts[2],
+		RefString: ref,
+		SubPath:   strings.Trim(path.Join(moduleDir, p.Subpath), "/"),
+	}
+	return Locator(c.String()), nil
+}
+
+// ToPurl converts a VCS locator into a package URL. Locators of repositories
+// hosted in GitHub, GitLab or Bitbucket are converted to purls of their
+// type, any other locator is returned as a generic purl with the locator
+// recorded in its vcs_url qualifier.
+func ToPurl[T ~string](locator T) (string, error) {
+	c, err := Locator(locator).Parse()
+	if err != nil {
+		return "", fmt.Errorf("parsing locator: %w", err)
+	}
+
+	repoPath := strings.Trim(c.RepoPath, "/")
+	if repoPath == "" {
+		return "", errors.New("locator has no repository path")
+	}
+	namespace, name := path.Split(repoPath)
+	namespace = strings.TrimSuffix(namespace, "/")
+	name = strings.TrimSuffix(name, ".git")
+	version := c.Commit
+	if version == "" {
+		version = c.RefString
+	}
+	subpath := strings.Trim(c.SubPath, "/")
+
+	for purlType, host := range purlHosts {
+		if c.Hostname != host || c.Transport == TransportFile {
+			continue
+		}
+		if namespace == "" {
+			return "", fmt.Errorf("locator repository path has no namespace: %q", c.RepoPath)
+		}
+		return packageurl.NewPackageURL(purlType, namespace, name, version, nil, subpath).ToString(), nil
+	}
+
+	qualifiers := packageurl.QualifiersFromMap(map[string]string{
+		purlQualifierVCSURL: c.String(),
+	})
+	return packageurl.NewPackageURL(packageurl.TypeGeneric, "", name, version, qualifiers, "").ToString(), nil
+}
+
+// goModuleDir returns the directory of a module in its repository from the
+// module path segments under the repository root. Major version suffixes
+// (/v2) are not directories.
+func goModuleDir(dir []string) string {
+	if len(dir) > 0 && majorVersionSuffixRegex.MatchString(dir[len(dir)-1]) {
+		dir = dir[:len(dir)-1]
+	}
+	return strings.Join(dir, "/")
+}
+
+// goModuleRef converts a go module version into the git ref it was
+// published from. Pseudo-versions are converted to their commit and the
+// tags of modules in subdirectories are prefixed with the module directory.
+func goModuleRef(version, moduleDir string) (string, error) {
+	ref := strings.TrimSuffix(version, "+incompatible")
+	switch {
+	case ref == "":
+	case module.IsPseudoVersion(ref):
+		rev, err := module.PseudoVersionRev(ref)
+		if err != nil {
+			return "", fmt.Errorf("parsing pseudo-version: %w", err)
+		}
+		ref = rev
+	case moduleDir != "":
+		// Tags of nested modules are prefixed with their directory
+		ref = moduleDir + "/" + ref
+	}
+	return ref, nil
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromPurl(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name    string
+		purl    string
+		expect  string
+		mustErr bool
+	}{
+		{"github", "pkg:github/example/test@v1.0.0", "git+https://github.com/example/test@v1.0.0", false},
+		{"github-subpath", "pkg:github/example/test@main#docs/README.md", "git+https://github.com/example/test@main#docs/README.md", false},
+		{"gitlab-subgroup", "pkg:gitlab/group/sub/test", "git+https://gitlab.com/group/sub/test", false},
+		{"bitbucket", "pkg:bitbucket/example/test@abc1234", "git+https://bitbucket.org/example/test@abc1234", false},
+		{"golang", "pkg:golang/github.com/example/test@v1.2.3", "git+https://github.com/example/test@v1.2.3", false},
+		{"golang-major", "pkg:golang/github.com/example/test/v2@v2.0.1", "git+https://github.com/example/test@v2.0.1", false},
+		{"golang-nested", "pkg:golang/github.com/example/test/tools@v0.1.0", "git+https://github.com/example/test@tools/v0.1.0#tools", false},
+		{"golang-pseudo", "pkg:golang/github.com/example/test@v0.0.0-20240101120000-25c779ba165d", "git+https://github.com/example/test@25c779ba165d", false},
+		{"golang-incompatible", "pkg:golang/github.com/example/test@v3.0.0%2Bincompatible", "git+https://github.com/example/test@v3.0.0", false},
+		{"golang-sourcehut", "pkg:golang/git.sr.ht/~user/test@v1.0.0", "git+https://git.sr.ht/~user/test@v1.0.0", false},
+		{"golang-unknown-host", "pkg:golang/golang.org/x/mod@v0.30.0", "", true},
+		{"vcs-url", "pkg:generic/test@1.0?vcs_url=git%2Bhttps://example.com/test%401.0", "git+https://example.com/test@1.0", false},
+		{"unsupported", "pkg:npm/left-pad@1.0.0", "", true},
+		{"invalid", "not a purl", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			l, err := FromPurl(tc.purl)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, string(l))
+		})
+	}
+}
+
+func TestToPurl(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name    string
+		locator string
+		expect  string
+		mustErr bool
+	}{
+		{"github", "git+https://github.com/example/test@v1.0.0", "pkg:github/example/test@v1.0.0", false},
+		{"github-subpath", "git+https://github.com/example/test@main#docs/README.md", "pkg:github/example/test@main#docs/README.md", false},
+		{"slug", "example/test", "pkg:github/example/test", false},
+		{"gitlab-subgroup", "git+https://gitlab.com/group/sub/test.git@v1", "pkg:gitlab/group/sub/test@v1", false},
+		{"generic", "git+https://git.example.com/test@v1", "pkg:generic/test@v1?vcs_url=git%2Bhttps:%2F%2Fgit.example.com%2Ftest%40v1", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			purl, err := ToPurl(tc.locator)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, purl)
+		})
+	}
+
+	t.Run("round-trip", func(t *testing.T) {
+		t.Parallel()
+		purl, err := ToPurl("git+https://git.example.com/org/test@v1#file.txt")
+		require.NoError(t, err)
+		l, err := FromPurl(purl)
+		require.NoError(t, err)
+		require.Equal(t, "git+https://git.example.com/org/test@v1#file.txt", string(l))
+	})
+}
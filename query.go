@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/mod/semver"
+)
+
+// RefLatest is the special reference that resolves to the highest semver tag
+// in the repository.
+const RefLatest = "latest"
+
+// partialVersionRegex matches version prefixes such as v1 or v1.2. Full
+// versions (v1.2.3) are not queries, they are treated as exact tag names.
+var partialVersionRegex = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)?$`)
+
+// isRefQuery returns true if a reference string is a version query that
+// needs to be resolved against the repository tags. Supported queries are:
+//
+//   - latest: the highest released semver tag
+//   - v1, v1.2: the highest tag with the version prefix
+//   - ^1.2, ~1.2.3: caret and tilde ranges
+//   - >=1.2,<2: comma separated lists of comparisons
+func isRefQuery(ref string) bool {
+	if ref == RefLatest || partialVersionRegex.MatchString(ref) {
+		return true
+	}
+	return ref != "" && strings.ContainsAny(ref[0:1], "^~<>=")
+}
+
+// resolveRefQuery contacts the remote to resolve a version query in the
+// locator ref. If the query matches a tag, the components are rewritten to
+// point to it. References that exist verbatim in the repository (a tag named
+// "latest" or "v1" for example) always take precedence over the query.
+func resolveRefQuery(l Locator, components *Components, opts *options, funcs ...fnOpt) error {
+	refs, err := withCredentialPrompt(components, opts, func() ([]*plumbing.Reference, error) {
+		auth, err := prepareRemote(l, components, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := requireOnline(l, opts); err != nil {
+			return nil, err
+		}
+		return listRemoteReferences(components, auth)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range candidateRefNames(components, opts) {
+		if lookupRemoteRef(refs, name) != "" {
+			return nil
+		}
+	}
+
+	tag, err := matchRefQuery(components.RefString, refs)
+	if err != nil {
+		// Numeric abbreviated commit hashes look like version queries
+		if components.Commit != "" {
+			return nil
+		}
+		return err
+	}
+
+	components.RefString = plumbing.NewTagReferenceName(tag).String()
+	components.Tag = tag
+	components.Branch = ""
+	return nil
+}
+
+// matchRefQuery returns the name of the highest semver tag in the remote
+// references that satisfies the query.
+func matchRefQuery(query string, refs []*plumbing.Reference) (string, error) {
+	constraints, err := parseVersionQuery(query)
+	if err != nil {
+		return "", err
+	}
+
+	var best, bestPre, bestVersion, bestPreVersion string
+	for _, ref := range refs {
+		if !ref.Name().IsTag() || strings.HasSuffix(ref.Name().String(), "^{}") {
+			continue
+		}
+		tag := ref.Name().Short()
+		v := "v" + strings.TrimPrefix(tag, "v")
+		if !semver.IsValid(v) || !constraints.match(v) {
+			continue
+		}
+
+		// Prereleases are only considered when no release matches
+		if semver.Prerelease(v) != "" {
+			if bestPreVersion == "" || semver.Compare(v, bestPreVersion) > 0 {
+				bestPre, bestPreVersion = tag, v
+			}
+			continue
+		}
+
+		if bestVersion == "" || semver.Compare(v, bestVersion) > 0 {
+			best, bestVersion = tag, v
+		}
+	}
+
+	if best == "" {
+		best = bestPre
+	}
+	if best == "" {
+		return "", fmt.Errorf("no tag matches version query %q", query)
+	}
+	return best, nil
+}
+
+// versionConstraint is a single comparison against a version
+type versionConstraint struct {
+	op      string
+	version string
+}
+
+// versionQuery is a set of constraints that must all match
+type versionQuery []versionConstraint
+
+func (q versionQuery) match(v string) bool {
+	for _, c := range q {
+		cmp := semver.Compare(v, c.version)
+		var ok bool
+		switch c.op {
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseVersionQuery converts a version query string into the list of
+// constraints a tag has to satisfy.
+func parseVersionQuery(query string) (versionQuery, error) {
+	if query == RefLatest {
+		return versionQuery{}, nil
+	}
+
+	ret := versionQuery{}
+	for _, part := range strings.Split(query, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, errors.New("empty constraint in version query")
+		}
+
+		// Extract the operator
+		op := ""
+		for _, candidate := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+
+		raw := "v" + strings.TrimPrefix(strings.TrimPrefix(part, op), "v")
+		lower := semver.Canonical(raw)
+		if lower == "" {
+			return nil, fmt.Errorf("invalid version in query: %q", part)
+		}
+
+		switch op {
+		case "":
+			// Bare partial versions (v1, v1.2) match any version in the prefix
+			ret = append(ret,
+				versionConstraint{">=", lower},
+				versionConstraint{"<", nextVersion(raw, strings.Count(raw, "."))},
+			)
+		case "^":
+			// Caret allows changes that don't modify the leftmost non-zero
+			// component, ^1.2 := >=1.2.0 <2.0.0, ^0.2 := >=0.2.0 <0.3.0
+			pos := 0
+			if semver.Major(lower) == "v0" && strings.Count(raw, ".") > 0 {
+				pos = 1
+			}
+			ret = append(ret, versionConstraint{">=", lower}, versionConstraint{"<", nextVersion(raw, pos)})
+		case "~":
+			// Tilde allows patch level changes, ~1.2.3 := >=1.2.3 <1.3.0
+			pos := 1
+			if strings.Count(raw, ".") == 0 {
+				pos = 0
+			}
+			ret = append(ret, versionConstraint{">=", lower}, versionConstraint{"<", nextVersion(raw, pos)})
+		default:
+			ret = append(ret, versionConstraint{op, lower})
+		}
+	}
+	return ret, nil
+}
+
+// nextVersion bumps the version component at position pos (0 = major,
+// 1 = minor) and returns the canonical version. For example nextVersion("v1.2", 1)
+// returns v1.3.0.
+func nextVersion(v string, pos int) string {
+	c := semver.Canonical(v)
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSuffix(c, semver.Prerelease(c)), "v"), ".")
+	if len(parts) != 3 || pos > 2 {
+		return ""
+	}
+
+	var n int
+	if _, err := fmt.Sscanf(parts[pos], "%d", &n); err != nil {
+		return ""
+	}
+	parts[pos] = fmt.Sprintf("%d", n+1)
+	for i := pos + 1; i < 3; i++ {
+		parts[i] = "0"
+	}
+	return "v" + strings.Join(parts, ".")
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRefQuery(t *testing.T) {
+	t.Parallel()
+	for ref, expect := range map[string]bool{
+		"latest":            true,
+		"v1":                true,
+		"v1.2":              true,
+		"1.2":               true,
+		"^1.2":              true,
+		"~1.2.3":            true,
+		">=1.2,<2":          true,
+		"v1.2.3":            false,
+		"main":              false,
+		"refs/tags/v1":      false,
+		"":                  false,
+		"25c779ba165d1f4fa": false,
+	} {
+		require.Equal(t, expect, isRefQuery(ref), ref)
+	}
+}
+
+func TestMatchRefQuery(t *testing.T) {
+	t.Parallel()
+	refs := []*plumbing.Reference{}
+	for _, tag := range []string{
+		"v0.1.0", "v0.2.0", "v0.2.5", "v1.0.0", "v1.2.0", "v1.2.7", "v1.3.0",
+		"v2.0.0-rc.1", "1.4.0", "not-a-version",
+	} {
+		refs = append(refs, plumbing.NewHashReference(plumbing.NewTagReferenceName(tag), plumbing.ZeroHash))
+	}
+	refs = append(refs, plumbing.NewHashReference(plumbing.NewBranchReferenceName("v9.0.0"), plumbing.ZeroHash))
+
+	for _, tc := range []struct {
+		query   string
+		expect  string
+		mustErr bool
+	}{
+		{"latest", "1.4.0", false},
+		{"v1", "1.4.0", false},
+		{"v1.2", "v1.2.7", false},
+		{"v0", "v0.2.5", false},
+		{"^1.2", "1.4.0", false},
+		{"^0.1", "v0.1.0", false},
+		{"~1.2.3", "v1.2.7", false},
+		{">=1.0,<1.3", "v1.2.7", false},
+		{">1.4", "v2.0.0-rc.1", false},
+		{"=1.0.0", "v1.0.0", false},
+		{"v3", "", true},
+		{">=bad", "", true},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			t.Parallel()
+			tag, err := matchRefQuery(tc.query, refs)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, tag)
+		})
+	}
+}
+
+func TestCloneVersionQuery(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"version.txt": "1.0.0"})
+	tagTestRepo(t, repoDir, "v1.0.0", first, "")
+	second := commitTestFile(t, repoDir, "version.txt", "1.1.0")
+	tagTestRepo(t, repoDir, "v1.1.0", second, "release v1.1.0")
+	third := commitTestFile(t, repoDir, "version.txt", "2.0.0")
+	tagTestRepo(t, repoDir, "v2.0.0", third, "")
+
+	for query, expect := range map[string]string{
+		"latest": "2.0.0",
+		"v1":     "1.1.0",
+		"^1.0":   "1.1.0",
+		"~1.0.0": "1.0.0",
+	} {
+		t.Run(query, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			err := CopyFile(fileLocator(repoDir, query, "version.txt"), &buf, noAuth)
+			require.NoError(t, err)
+			require.Equal(t, expect, buf.String())
+		})
+	}
+
+	t.Run("resolve", func(t *testing.T) {
+		t.Parallel()
+		commit, err := Locator(fileLocator(repoDir, "v1", "")).Resolve(noAuth)
+		require.NoError(t, err)
+		require.Equal(t, second, commit)
+	})
+	t.Run("numeric-commit", func(t *testing.T) {
+		t.Parallel()
+		// Abbreviated hashes made of digits are not version queries
+		var buf bytes.Buffer
+		err := CopyFile(fileLocator(repoDir, "1234567", "version.txt"), &buf, noAuth)
+		require.Error(t, err)
+		require.NotContains(t, err.Error(), "version query")
+	})
+}
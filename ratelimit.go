@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// rateLimitReserve is the percentage of the API quota below which the
+	// requests to a host are paced to last until the quota resets
+	rateLimitReserve = 10
+
+	// defaultRateLimitWait is the longest a request waits for its turn
+	// when the quota is running out, see WithRateLimitWait
+	defaultRateLimitWait = time.Minute
+)
+
+// rateLimit is the API quota of a host as reported by its responses
+type rateLimit struct {
+	mu        sync.Mutex
+	remaining int
+	limit     int
+	reset     time.Time
+
+	// next is the earliest time the next paced request can be sent
+	next time.Time
+}
+
+// rateLimits holds the quota of each host and credential
+var rateLimits sync.Map
+
+// reserve takes a request from the quota, returning how long to wait
+// before sending it. Requests are paced when the quota is below the
+// reserve and wait for the reset when it is exhausted. It fails if the
+// wait would exceed maxWait.
+func (rl *rateLimit) reserve(now time.Time, maxWait time.Duration) (time.Duration, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// Unknown or renewed quotas are not throttled
+	if rl.reset.IsZero() || !now.Before(rl.reset) {
+		return 0, nil
+	}
+
+	var wait time.Duration
+	switch {
+	case rl.remaining <= 0:
+		wait = rl.reset.Sub(now)
+	case rl.limit > 0 && rl.remaining*100 > rl.limit*rateLimitReserve:
+		wait = 0
+	default:
+		start := now
+		if rl.next.After(start) {
+			start = rl.next
+		}
+		wait = start.Sub(now)
+		if wait <= maxWait {
+			rl.next = start.Add(rl.reset.Sub(start) / time.Duration(rl.remaining+1))
+		}
+	}
+	if wait > maxWait {
+		return 0, fmt.Errorf("API rate limit exhausted until %s", rl.reset.Format(time.RFC3339))
+	}
+	rl.remaining--
+	return wait, nil
+}
+
+// update records the quota reported in the headers of a response. GitHub
+// sends the X-RateLimit headers, secondary limits are reported with a
+// Retry-After header. It returns false if the response has no quota.
+func (rl *rateLimit) update(resp *http.Response, now time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	h := resp.Header
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if secs, err := strconv.Atoi(h.Get("Retry-After")); err == nil {
+			rl.remaining = 0
+			rl.reset = now.Add(time.Duration(secs) * time.Second)
+			return true
+		}
+	}
+
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return false
+	}
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return false
+	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return false
+	}
+	rl.remaining = remaining
+	rl.limit = limit
+	rl.reset = time.Unix(reset, 0)
+	return true
+}
+
+// rateLimitKey identifies the quota of a request. Quotas are assigned to
+// each credential, anonymous requests share the quota of the client.
+func rateLimitKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization") + "\n" + req.Header.Get("PRIVATE-TOKEN")))
+	return strings.ToLower(req.URL.Hostname()) + "/" + hex.EncodeToString(sum[:8])
+}
+
+// rateLimitTransport paces the requests to forge APIs according to the
+// quota reported by their responses, reporting it to the metrics.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	maxWait time.Duration
+	metrics Metrics
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	v, _ := rateLimits.LoadOrStore(rateLimitKey(req), &rateLimit{})
+	rl := v.(*rateLimit) //nolint:forcetypeassert
+
+	wait, err := rl.reserve(time.Now(), t.maxWait)
+	if err != nil {
+		return nil, fmt.Errorf("requesting %s: %w", req.URL.Hostname(), err)
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if rl.update(resp, time.Now()) && t.metrics != nil {
+		rl.mu.Lock()
+		remaining, limit, reset := rl.remaining, rl.limit, rl.reset
+		rl.mu.Unlock()
+		t.metrics.ForgeRateLimit(req.URL.Hostname(), remaining, limit, reset)
+	}
+	return resp, nil
+}
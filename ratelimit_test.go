@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testMetrics records the measurements reported
+type testMetrics struct {
+	mu           sync.Mutex
+	remainings   []int
+	hits, misses int
+}
+
+func (m *testMetrics) ForgeRateLimit(_ string, remaining, _ int, _ time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remainings = append(m.remainings, remaining)
+}
+
+func (m *testMetrics) ParseCacheLookup(hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.hits++
+	} else {
+		m.misses++
+	}
+}
+
+func TestRateLimitReserve(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		rl      *rateLimit
+		maxWait time.Duration
+		expect  []time.Duration
+		mustErr bool
+	}{
+		{"unknown", &rateLimit{}, time.Minute, []time.Duration{0, 0}, false},
+		{"plenty", &rateLimit{remaining: 4000, limit: 5000, reset: now.Add(time.Hour)}, time.Minute, []time.Duration{0, 0}, false},
+		{"reset", &rateLimit{remaining: 0, limit: 5000, reset: now.Add(-time.Second)}, time.Minute, []time.Duration{0}, false},
+		// 3 requests left in 40 seconds are sent every 10 seconds
+		{"paced", &rateLimit{remaining: 3, limit: 60, reset: now.Add(40 * time.Second)}, time.Minute, []time.Duration{0, 10 * time.Second, 20 * time.Second}, false},
+		{"exhausted", &rateLimit{remaining: 0, limit: 60, reset: now.Add(30 * time.Second)}, time.Minute, []time.Duration{30 * time.Second}, false},
+		{"exhausted-too-long", &rateLimit{remaining: 0, limit: 60, reset: now.Add(time.Hour)}, time.Minute, nil, true},
+		{"no-wait", &rateLimit{remaining: 0, limit: 60, reset: now.Add(time.Second)}, 0, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			rl := tc.rl
+			for _, expect := range tc.expect {
+				wait, err := rl.reserve(now, tc.maxWait)
+				require.NoError(t, err)
+				require.Equal(t, expect, wait)
+			}
+			if tc.mustErr {
+				_, err := rl.reserve(now, tc.maxWait)
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestRateLimitUpdate(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name      string
+		status    int
+		header    map[string]string
+		expect    bool
+		remaining int
+		reset     time.Time
+	}{
+		{"quota", http.StatusOK, map[string]string{
+			"X-RateLimit-Remaining": "10", "X-RateLimit-Limit": "60", "X-RateLimit-Reset": strconv.FormatInt(now.Add(time.Hour).Unix(), 10),
+		}, true, 10, now.Add(time.Hour)},
+		{"retry-after", http.StatusTooManyRequests, map[string]string{"Retry-After": "30"}, true, 0, now.Add(30 * time.Second)},
+		{"retry-after-ok", http.StatusOK, map[string]string{"Retry-After": "30"}, false, 0, time.Time{}},
+		{"incomplete", http.StatusOK, map[string]string{"X-RateLimit-Remaining": "10"}, false, 0, time.Time{}},
+		{"none", http.StatusOK, nil, false, 0, time.Time{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			resp := &http.Response{StatusCode: tc.status, Header: http.Header{}}
+			for k, v := range tc.header {
+				resp.Header.Set(k, v)
+			}
+			rl := &rateLimit{}
+			require.Equal(t, tc.expect, rl.update(resp, now))
+			require.Equal(t, tc.remaining, rl.remaining)
+			require.True(t, tc.reset.Equal(rl.reset))
+		})
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The quota runs out with the first request
+		requests.Add(1)
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", reset)
+		w.Header().Set("Content-Type", gitHubRawMediaType)
+		io.WriteString(w, "data\n") //nolint:errcheck,gosec
+	}))
+	t.Cleanup(srv.Close)
+
+	metrics := &testMetrics{}
+	locator := "git+https://ratelimit.example.com/org/repo@" + testCommit + "#README.md"
+	funcs := []fnOpt{
+		WithHttpClient(forgeTestClient(srv)), WithGitHubEnterprise("ratelimit.example.com"),
+		WithMetrics(metrics), WithHttpAuth("user", "ratelimit-test"),
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, CopyFile(locator, &b, funcs...))
+	require.Equal(t, "data\n", b.String())
+	require.Equal(t, []int{0}, metrics.remainings)
+
+	// The quota does not reset within the wait, the request is not sent
+	// and falling back to cloning fails as the server is not a remote
+	require.Error(t, CopyFile(locator, io.Discard, funcs...))
+	require.Equal(t, int32(1), requests.Load())
+
+	require.Error(t, WithRateLimitWait(-time.Second)(&options{}))
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// referenceStorer wraps the storer of a clone to read the objects missing
+// in it from a local reference repository, as git does with alternates.
+// Objects found in the reference are never copied to the clone.
+type referenceStorer struct {
+	storage.Storer
+	reference *git.Repository
+}
+
+// newReferenceStorer opens the repository at path (a worktree or a bare
+// repository) and wraps the storer to read objects from it.
+func newReferenceStorer(st storage.Storer, path string) (*referenceStorer, error) {
+	reference, err := openLocal(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening reference repository: %w", err)
+	}
+	return &referenceStorer{Storer: st, reference: reference}, nil
+}
+
+// EncodedObject returns an object from the clone or the reference repository
+func (s *referenceStorer) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := s.Storer.EncodedObject(t, h)
+	if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return obj, err
+	}
+	// go-git compares the not found error by value
+	if obj, err := s.reference.Storer.EncodedObject(t, h); err == nil {
+		return obj, nil
+	}
+	return nil, plumbing.ErrObjectNotFound
+}
+
+// HasEncodedObject checks if the object is in the clone or the reference
+func (s *referenceStorer) HasEncodedObject(h plumbing.Hash) error {
+	if err := s.Storer.HasEncodedObject(h); !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return err
+	}
+	if s.reference.Storer.HasEncodedObject(h) == nil {
+		return nil
+	}
+	return plumbing.ErrObjectNotFound
+}
+
+// EncodedObjectSize returns the size of an object in the clone or the reference
+func (s *referenceStorer) EncodedObjectSize(h plumbing.Hash) (int64, error) {
+	size, err := s.Storer.EncodedObjectSize(h)
+	if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return size, err
+	}
+	if size, err := s.reference.Storer.EncodedObjectSize(h); err == nil {
+		return size, nil
+	}
+	return 0, plumbing.ErrObjectNotFound
+}
+
+// haves returns the commits at the tips of the reference repository refs.
+// They are sent to the remote when fetching so it leaves out of the
+// packfile the objects the reference already has.
+func (s *referenceStorer) haves() ([]plumbing.Hash, error) {
+	refs, err := s.reference.References()
+	if err != nil {
+		return nil, fmt.Errorf("listing reference repository refs: %w", err)
+	}
+	seen := map[plumbing.Hash]struct{}{}
+	haves := []plumbing.Hash{}
+	if err := refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		if _, ok := seen[ref.Hash()]; ok {
+			return nil
+		}
+		seen[ref.Hash()] = struct{}{}
+		haves = append(haves, ref.Hash())
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("listing reference repository refs: %w", err)
+	}
+	return haves, nil
+}
+
+// writeAlternates records the objects directory of the reference repository
+// in the objects/info/alternates file of a repository written to disk, so
+// git finds the objects it borrows from it. Storers not on disk are left
+// untouched.
+func (s *referenceStorer) writeAlternates(st storage.Storer) error {
+	clone, ok := unwrapLimits(st).(*filesystem.Storage)
+	if !ok {
+		return nil
+	}
+	reference, ok := s.reference.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil
+	}
+	objects, err := filepath.Abs(filepath.Join(commonGitDir(reference.Filesystem().Root()), "objects"))
+	if err != nil {
+		return fmt.Errorf("resolving reference objects directory: %w", err)
+	}
+	info := filepath.Join(clone.Filesystem().Root(), "objects", "info")
+	if err := os.MkdirAll(info, 0o755); err != nil {
+		return fmt.Errorf("creating objects info directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(info, "alternates"), []byte(objects+"\n"), 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("writing alternates file: %w", err)
+	}
+	return nil
+}
+
+// findReferenceStorer returns the reference storer wrapping a clone storer,
+// or nil if the clone has no reference repository.
+func findReferenceStorer(st storage.Storer) *referenceStorer {
+	for {
+		switch s := st.(type) {
+		case *referenceStorer:
+			return s
+		case *promisorStorer:
+			st = s.Storer
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReference(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git-upload-pack"); err != nil {
+		t.Skip("git-upload-pack binary not found")
+	}
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		"big.bin": strings.Repeat("large file\n", 512),
+	})
+
+	// The reference mirror falls behind the remote
+	refDir := filepath.Join(t.TempDir(), "mirror.git")
+	_, err := git.PlainClone(refDir, true, &git.CloneOptions{URL: repoDir})
+	require.NoError(t, err)
+	second := commitTestFile(t, repoDir, "README.md", "readme\n")
+
+	bigBlob := plumbing.ComputeHash(plumbing.BlobObject, []byte(strings.Repeat("large file\n", 512)))
+	readmeBlob := plumbing.ComputeHash(plumbing.BlobObject, []byte("readme\n"))
+
+	for _, ref := range []string{"refs/heads/master", second} {
+		t.Run(ref, func(t *testing.T) {
+			t.Parallel()
+			var st *memory.Storage
+			factory := func(*Components) (storage.Storer, error) {
+				st = memory.NewStorage()
+				return st, nil
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, CopyFile(
+				fileLocator(repoDir, ref, "big.bin"), &buf,
+				noAuth, WithReference(refDir), WithStorerFactory(factory),
+			))
+			require.Equal(t, strings.Repeat("large file\n", 512), buf.String())
+
+			// Only the objects missing in the mirror were fetched
+			require.ErrorIs(t, st.HasEncodedObject(bigBlob), plumbing.ErrObjectNotFound)
+			require.NoError(t, st.HasEncodedObject(readmeBlob))
+		})
+	}
+
+	t.Run("alternates", func(t *testing.T) {
+		t.Parallel()
+		clonePath := t.TempDir()
+		_, err := CloneRepository(
+			fileLocator(repoDir, "refs/heads/master", ""),
+			noAuth, WithReference(refDir), WithClonePath(clonePath), WithDiskStorage(""),
+		)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(clonePath, ".git", "objects", "info", "alternates"))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(refDir, "objects")+"\n", string(data))
+
+		if _, err := exec.LookPath("git"); err != nil {
+			return
+		}
+		out, err := exec.Command("git", "-C", clonePath, "cat-file", "-p", bigBlob.String()).CombinedOutput() //nolint:gosec
+		require.NoError(t, err, string(out))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		err := CopyFile(fileLocator(repoDir, second, "README.md"), &buf, noAuth, WithReference(t.TempDir()))
+		require.ErrorContains(t, err, "opening reference repository")
+	})
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// remoteHeadRef is where the remote HEAD is stored when refreshing
+const remoteHeadRef = "refs/remotes/origin/HEAD"
+
+// openPreviousDownload returns the storage of the git directory left in
+// root by a previous download (see WithKeepGitDir) if its origin remote
+// points to repourl.
+func openPreviousDownload(root, repourl string) (storage.Storer, bool) {
+	dir := filepath.Join(root, gitDirName)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, false
+	}
+
+	st := filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault())
+	cfg, err := st.Config()
+	if err != nil {
+		return nil, false
+	}
+	remote, ok := cfg.Remotes["origin"]
+	if !ok || len(remote.URLs) == 0 || remote.URLs[0] != repourl {
+		return nil, false
+	}
+	return st, true
+}
+
+// refreshRepo fetches a reference into an existing repository and checks it
+// out in fsobj, leaving the repository as a fresh clone would. Only the
+// objects missing in the storage are transferred. An empty reference
+// fetches the remote HEAD.
+func refreshRepo(st storage.Storer, fsobj billy.Filesystem, remoteRef string, auth transport.AuthMethod, depth int) (*git.Repository, error) {
+	repo, err := git.Open(st, fsobj)
+	if err != nil {
+		return nil, fmt.Errorf("opening repository: %w", err)
+	}
+
+	localRef := remoteRef
+	if remoteRef == "" {
+		remoteRef = plumbing.HEAD.String()
+		localRef = remoteHeadRef
+	}
+
+	if err := repo.Fetch(&git.FetchOptions{
+		RemoteName: "origin",
+		Auth:       auth,
+		Depth:      depth,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+%s:%s", remoteRef, localRef)),
+		},
+	}); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("fetching ref %q: %w", remoteRef, err)
+	}
+
+	ref, err := repo.Reference(plumbing.ReferenceName(localRef), true)
+	if err != nil {
+		return nil, fmt.Errorf("resolving fetched ref: %w", err)
+	}
+
+	// Point HEAD to the fetched commit and check it out as a clone would
+	if err := st.SetReference(plumbing.NewHashReference(plumbing.HEAD, ref.Hash())); err != nil {
+		return nil, fmt.Errorf("updating HEAD: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("getting repository worktree: %w", err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: ref.Hash(), Force: true}); err != nil {
+		return nil, fmt.Errorf("checking out %s: %w", ref.Hash(), err)
+	}
+	return repo, nil
+}
+
+// unchangedFile checks if the file at p under root has the contents of the
+// blob identified by hash and the permissions in perm. If it does, the
+// SHA-256 gitoid of the file is returned too.
+func unchangedFile(root, p string, hash plumbing.Hash, perm os.FileMode) (string, bool) {
+	path, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(p)))
+	if err != nil || !isWithin(root, path) {
+		return "", false
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm() != perm {
+		return "", false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close() //nolint:errcheck
+
+	hasher := plumbing.NewHasher(plumbing.BlobObject, info.Size())
+	oid := newGitOIDHasher(info.Size())
+	if _, err := io.Copy(io.MultiWriter(hasher, oid), f); err != nil {
+		return "", false
+	}
+	if hasher.Sum() != hash {
+		return "", false
+	}
+	return hex.EncodeToString(oid.Sum(nil)), true
+}
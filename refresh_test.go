@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadRefresh(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		"same.txt":    "same",
+		"changes.txt": "v1",
+		"edited.txt":  "pristine",
+	})
+	locator := fileLocator(repoDir, "refs/heads/master", "")
+
+	dest := t.TempDir()
+	require.NoError(t, Download(locator, dest, noAuth, WithKeepGitDir(true)))
+
+	// Mark the files to detect which ones are written again
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range []string{"same.txt", "changes.txt"} {
+		require.NoError(t, os.Chtimes(filepath.Join(dest, name), past, past))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dest, "edited.txt"), []byte("local edit"), 0o644)) //nolint:gosec
+
+	second := commitTestFile(t, repoDir, "changes.txt", "v2")
+	require.NoError(t, Download(locator, dest, noAuth, WithKeepGitDir(true)))
+
+	info, err := os.Stat(filepath.Join(dest, "same.txt"))
+	require.NoError(t, err)
+	require.True(t, info.ModTime().Equal(past), "unchanged file was rewritten")
+
+	for name, content := range map[string]string{"changes.txt": "v2", "edited.txt": "pristine"} {
+		data, err := os.ReadFile(filepath.Join(dest, name))
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	}
+
+	repo, err := git.PlainOpen(dest)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, second, head.Hash().String())
+
+	// Downloads from other repositories are not refreshed
+	otherDir, otherCommit := initTestRepoWithFiles(t, map[string]string{"other.txt": "other"})
+	require.NoError(t, Download(fileLocator(otherDir, otherCommit, ""), dest, noAuth, WithKeepGitDir(true)))
+	repo, err = git.PlainOpen(dest)
+	require.NoError(t, err)
+	head, err = repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, otherCommit, head.Hash().String())
+}
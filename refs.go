@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Types of references returned when listing a remote
+const (
+	RefTypeHead   = "head"
+	RefTypeBranch = "branch"
+	RefTypeTag    = "tag"
+	RefTypePull   = "pull"
+	RefTypeChange = "change"
+	RefTypeOther  = "other"
+)
+
+// RemoteRef captures a reference advertised by a remote repository.
+type RemoteRef struct {
+	// Name is the full name of the reference (ie refs/heads/main)
+	Name string
+
+	// Type is the kind of reference: head, branch, tag, pull (pull and merge
+	// requests), change (Gerrit patchsets) or other
+	Type string
+
+	// Hash is the object the reference points to. For annotated tags this
+	// is the hash of the tag object.
+	Hash string
+
+	// Commit is the commit the reference resolves to. It is the same as
+	// Hash except for annotated tags where it is the peeled commit.
+	Commit string
+
+	// Target is the reference pointed to by symbolic references (ie HEAD)
+	Target string
+}
+
+// ShortName returns the reference name without its namespace prefix
+// (ie "main" for refs/heads/main or "v1.0.0" for refs/tags/v1.0.0).
+func (r *RemoteRef) ShortName() string {
+	return plumbing.ReferenceName(r.Name).Short()
+}
+
+// ListRemoteRefs performs the equivalent of git ls-remote on the repository
+// referenced by the locator. It returns the HEAD, branch and tag references
+// advertised by the remote without transferring any objects.
+func ListRemoteRefs[T ~string](locator T, funcs ...fnOpt) ([]RemoteRef, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	l := Locator(locator)
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing locator: %w", err)
+	}
+
+	refs, err := withCredentialPrompt(components, &opts, func() ([]*plumbing.Reference, error) {
+		auth, err := prepareRemote(l, components, &opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := requireOnline(l, &opts); err != nil {
+			return nil, err
+		}
+		return listRemoteReferences(components, auth)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []RemoteRef{}
+	for _, ref := range refs {
+		name := ref.Name()
+
+		// Peeled tags are folded into their tag entry
+		if strings.HasSuffix(name.String(), "^{}") {
+			continue
+		}
+
+		rref := RemoteRef{
+			Name: name.String(),
+			Type: RefTypeOther,
+		}
+
+		switch {
+		case name == plumbing.HEAD:
+			rref.Type = RefTypeHead
+		case name.IsBranch():
+			rref.Type = RefTypeBranch
+		case name.IsTag():
+			rref.Type = RefTypeTag
+		case isPullRequestRef(name.String()):
+			rref.Type = RefTypePull
+		case isGerritChangeRef(name.String()):
+			rref.Type = RefTypeChange
+		}
+
+		if ref.Type() == plumbing.SymbolicReference {
+			rref.Target = ref.Target().String()
+		} else {
+			rref.Hash = ref.Hash().String()
+		}
+		rref.Commit = lookupRemoteRef(refs, name)
+
+		ret = append(ret, rref)
+	}
+
+	slices.SortFunc(ret, func(a, b RemoteRef) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return ret, nil
+}
+
+// ListTags returns the tags defined in the repository referenced by the
+// locator. Annotated tags are returned with their peeled commit.
+func ListTags[T ~string](locator T, funcs ...fnOpt) ([]RemoteRef, error) {
+	return listRefsOfType(Locator(locator), RefTypeTag, funcs...)
+}
+
+// ListBranches returns the branches defined in the repository referenced by
+// the locator along with the commit at their tip.
+func ListBranches[T ~string](locator T, funcs ...fnOpt) ([]RemoteRef, error) {
+	return listRefsOfType(Locator(locator), RefTypeBranch, funcs...)
+}
+
+// listRefsOfType lists the remote references and filters them by type
+func listRefsOfType(l Locator, refType string, funcs ...fnOpt) ([]RemoteRef, error) {
+	refs, err := ListRemoteRefs(l, funcs...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []RemoteRef{}
+	for _, ref := range refs {
+		if ref.Type == refType {
+			ret = append(ret, ref)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRemoteRefs(t *testing.T) {
+	t.Parallel()
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	tagTestRepo(t, repoDir, "v1.0.0", first, "")
+	tagTestRepo(t, repoDir, "v1.0.1", first, "annotated release")
+	head := commitTestFile(t, repoDir, "hello.txt", "bye")
+
+	refs, err := ListRemoteRefs(NewFromPath(repoDir), WithSystemCredentials(false))
+	require.NoError(t, err)
+
+	byName := map[string]RemoteRef{}
+	for _, r := range refs {
+		byName[r.Name] = r
+	}
+
+	require.Contains(t, byName, "HEAD")
+	require.Equal(t, RefTypeHead, byName["HEAD"].Type)
+	require.Equal(t, head, byName["HEAD"].Commit)
+
+	require.Contains(t, byName, "refs/heads/master")
+	require.Equal(t, RefTypeBranch, byName["refs/heads/master"].Type)
+	require.Equal(t, head, byName["refs/heads/master"].Commit)
+	require.Equal(t, "master", (&RemoteRef{Name: "refs/heads/master"}).ShortName())
+
+	require.Contains(t, byName, "refs/tags/v1.0.0")
+	require.Equal(t, RefTypeTag, byName["refs/tags/v1.0.0"].Type)
+	require.Equal(t, first, byName["refs/tags/v1.0.0"].Hash)
+	require.Equal(t, first, byName["refs/tags/v1.0.0"].Commit)
+
+	// Annotated tags point to the tag object but resolve to the commit
+	require.Contains(t, byName, "refs/tags/v1.0.1")
+	require.NotEqual(t, first, byName["refs/tags/v1.0.1"].Hash)
+	require.Equal(t, first, byName["refs/tags/v1.0.1"].Commit)
+
+	require.NotContains(t, byName, "refs/tags/v1.0.1^{}")
+}
+
+func TestListTagsAndBranches(t *testing.T) {
+	t.Parallel()
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	tagTestRepo(t, repoDir, "v1.0.0", first, "")
+	tagTestRepo(t, repoDir, "v1.0.1", first, "annotated release")
+	head := commitTestFile(t, repoDir, "hello.txt", "bye")
+
+	tags, err := ListTags(NewFromPath(repoDir), WithSystemCredentials(false))
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	for _, tag := range tags {
+		require.Equal(t, RefTypeTag, tag.Type)
+		require.Equal(t, first, tag.Commit)
+	}
+	require.Equal(t, "v1.0.0", tags[0].ShortName())
+	require.Equal(t, "v1.0.1", tags[1].ShortName())
+
+	branches, err := ListBranches(NewFromPath(repoDir), WithSystemCredentials(false))
+	require.NoError(t, err)
+	require.Len(t, branches, 1)
+	require.Equal(t, "master", branches[0].ShortName())
+	require.Equal(t, head, branches[0].Commit)
+}
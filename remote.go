@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// prepareRemote runs the preflight checks required before contacting the
+// remote of a parsed locator and returns the auth method to use. All
+// functions talking to a remote must call it first.
+//
+// The returned auth method is nil when reading credentials is disabled in
+// the options (and none was set with WithAuthMethod or prompted) or when
+// the transport does not need authentication.
+func prepareRemote(l Locator, components *Components, opts *options) (transport.AuthMethod, error) {
+	if components.Transport == TransportFile && !opts.allowLocal() {
+		return nil, &PolicyViolationError{
+			Locator:   string(l),
+			Transport: TransportFile,
+			Rule:      "local locators not allowed",
+		}
+	}
+
+	if err := checkHostLists(l, components.Hostname, opts); err != nil {
+		return nil, err
+	}
+
+	if err := opts.VCSPolicy.check(l, components); err != nil {
+		return nil, err
+	}
+
+	if components.Transport == TransportFile {
+		return nil, nil
+	}
+	if opts.AuthMethod != nil {
+		return opts.AuthMethod, nil
+	}
+	if opts.TokenSource != nil && components.Transport == TransportHTTPS {
+		return tokenSourceAuth(opts)
+	}
+
+	// Credentials answered to the prompt replace the detected ones, which
+	// were missing or rejected by the remote
+	if prompt := opts.credentialPrompt(); prompt != nil {
+		if host := promptHost(components); host != "" {
+			if a := prompt.answer(host); a != nil {
+				return a, nil
+			}
+		}
+	}
+
+	var auth transport.AuthMethod
+	if opts.ReadCredentials {
+		var err error
+		if auth, err = authMethod(components, opts); err != nil {
+			return nil, fmt.Errorf("getting git auth method: %w", err)
+		}
+
+		// Forges get the tokens their tools read from the environment,
+		// unless the repository is fetched from a mirror
+		if auth == nil && components.Transport == TransportHTTPS && components.mirror == "" {
+			auth = envTokenAuth(components.Hostname, opts)
+		}
+	}
+
+	// Tokens stored in the keychain are used next, also only for the
+	// locator host itself
+	if auth == nil && opts.Keychain && components.Transport == TransportHTTPS && components.mirror == "" {
+		var err error
+		if auth, err = keychainAuth(components.Hostname); err != nil {
+			return nil, err
+		}
+	}
+
+	return auth, nil
+}
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// Resolve contacts the remote repository and returns the full commit hash
+// that the locator's reference points to. Branches and tags (annotated tags
+// are peeled) are resolved to the commit they point to, version queries
+// (latest, v1, ^1.2) to the best matching tag and locators without a
+// reference resolve to the commit at the remote HEAD. Date refs
+// (main@{2025-01-01}) require cloning the history of the ref.
+func (l Locator) Resolve(funcs ...fnOpt) (string, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return "", err
+		}
+	}
+
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return "", fmt.Errorf("parsing locator: %w", err)
+	}
+
+	// Full commit hashes are already pinned, no need to hit the network
+	if sha1Regex.MatchString(components.Commit) {
+		return components.Commit, nil
+	}
+
+	// Date refs can only be resolved by walking the history of the ref.
+	// Offline, refs are resolved in the cached repository.
+	if !components.AsOf.IsZero() || opts.Offline {
+		cloned, err := cloneRepo(l, &opts, funcs...)
+		if err != nil {
+			return "", err
+		}
+		defer cloned.Close() //nolint:errcheck
+		return cloned.Commit, nil
+	}
+
+	refs, err := withCredentialPrompt(components, &opts, func() ([]*plumbing.Reference, error) {
+		auth, err := prepareRemote(l, components, &opts)
+		if err != nil {
+			return nil, err
+		}
+		return listRemoteReferences(components, auth)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Abbreviated hashes can only be resolved if a remote ref points to them
+	if components.Commit != "" {
+		for _, ref := range refs {
+			if ref.Type() == plumbing.HashReference && strings.HasPrefix(ref.Hash().String(), components.Commit) {
+				return ref.Hash().String(), nil
+			}
+		}
+		return "", fmt.Errorf("unable to resolve abbreviated commit %q from the remote references: %w", components.Commit, plumbing.ErrObjectNotFound)
+	}
+
+	for _, name := range candidateRefNames(components, &opts) {
+		if hash := lookupRemoteRef(refs, name); hash != "" {
+			return hash, nil
+		}
+	}
+
+	if isRefQuery(components.RefString) {
+		tag, err := matchRefQuery(components.RefString, refs)
+		if err != nil {
+			return "", err
+		}
+		return lookupRemoteRef(refs, plumbing.NewTagReferenceName(tag)), nil
+	}
+
+	if components.RefString == "" {
+		return "", errors.New("unable to resolve remote HEAD")
+	}
+	return "", fmt.Errorf("reference %q not found in remote: %w", components.RefString, plumbing.ErrReferenceNotFound)
+}
+
+// Pin resolves the locator's reference and returns a new locator with the
+// reference replaced by the full commit hash. The returned locator is
+// immutable: it will always point to the same content.
+func (l Locator) Pin(funcs ...fnOpt) (Locator, error) {
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return "", fmt.Errorf("parsing locator: %w", err)
+	}
+
+	commit, err := l.Resolve(funcs...)
+	if err != nil {
+		return "", err
+	}
+
+	components.RefString = commit
+	components.Commit = commit
+	components.Tag = ""
+	components.Branch = ""
+	components.AsOf = time.Time{}
+
+	return Locator(components.String()), nil
+}
+
+// listRemoteReferences performs the equivalent of git ls-remote, returning the
+// references advertised by the remote. Annotated tags are returned twice, the
+// peeled commit is appended with the ^{} suffix in its name.
+func listRemoteReferences(components *Components, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{components.fetchURL()},
+	})
+
+	refs, err := remote.List(&git.ListOptions{
+		Auth:          auth,
+		PeelingOption: git.AppendPeeled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing remote references: %w", err)
+	}
+	return refs, nil
+}
+
+// candidateRefNames returns the full reference names a locator ref may refer
+// to, in the order they should be tried. Bare names are looked up as tags
+// and branches, following the git rev-parse precedence unless the options
+// instruct us to treat them as branches.
+func candidateRefNames(components *Components, opts *options) []plumbing.ReferenceName {
+	ref := components.RefString
+	switch {
+	case ref == "":
+		return []plumbing.ReferenceName{plumbing.HEAD}
+	case strings.HasPrefix(ref, "refs/"):
+		return []plumbing.ReferenceName{plumbing.ReferenceName(ref)}
+	case opts.RefIsBranch:
+		return []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref),
+		}
+	default:
+		return []plumbing.ReferenceName{
+			plumbing.NewTagReferenceName(ref), plumbing.NewBranchReferenceName(ref),
+		}
+	}
+}
+
+// lookupRemoteRef finds a reference by name in a list returned by the remote
+// and returns the commit hash it points to. Symbolic references are followed
+// and annotated tags are resolved to their peeled commit.
+func lookupRemoteRef(refs []*plumbing.Reference, name plumbing.ReferenceName) string {
+	byName := map[plumbing.ReferenceName]*plumbing.Reference{}
+	for _, ref := range refs {
+		byName[ref.Name()] = ref
+	}
+
+	// Follow symbolic references (ie HEAD -> refs/heads/main)
+	for range 10 {
+		ref, ok := byName[name]
+		if !ok {
+			return ""
+		}
+		if ref.Type() == plumbing.SymbolicReference {
+			name = ref.Target()
+			continue
+		}
+
+		// If the remote sent a peeled version of the ref, it's an annotated
+		// tag and we need to return the commit it points to.
+		if peeled, ok := byName[plumbing.ReferenceName(name.String()+"^{}")]; ok {
+			return peeled.Hash().String()
+		}
+		return ref.Hash().String()
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// commitTestFile writes a file into an existing test repository and commits
+// it, returning the new commit hash.
+func commitTestFile(t *testing.T, repoDir, relPath, content string) string {
+	t.Helper()
+	return commitTestFileAt(t, repoDir, relPath, content, time.Now())
+}
+
+// commitTestFileAt is like commitTestFile but sets the commit date.
+func commitTestFileAt(t *testing.T, repoDir, relPath, content string, when time.Time) string {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	abs := filepath.Join(repoDir, relPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(abs), 0o750))
+	require.NoError(t, os.WriteFile(abs, []byte(content), 0o600))
+	_, err = wt.Add(relPath)
+	require.NoError(t, err)
+
+	hash, err := wt.Commit("update "+relPath, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@test.com", When: when},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+// tagTestRepo creates a tag in the test repository. If message is not empty
+// the tag is created as an annotated tag.
+func tagTestRepo(t *testing.T, repoDir, name, commit, message string) {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+
+	var opts *git.CreateTagOptions
+	if message != "" {
+		opts = &git.CreateTagOptions{
+			Tagger:  &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()},
+			Message: message,
+		}
+	}
+	_, err = repo.CreateTag(name, plumbing.NewHash(commit), opts)
+	require.NoError(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	tagTestRepo(t, repoDir, "v1.0.0", first, "")
+	tagTestRepo(t, repoDir, "v1.0.1", first, "annotated release")
+	head := commitTestFile(t, repoDir, "hello.txt", "bye")
+
+	for _, tc := range []struct {
+		name    string
+		ref     string
+		opts    []fnOpt
+		expect  string
+		mustErr bool
+	}{
+		{"no-ref", "", nil, head, false},
+		{"full-commit", first, nil, first, false},
+		{"abbreviated-commit", head[:10], nil, head, false},
+		{"branch-ref", "refs/heads/master", nil, head, false},
+		{"bare-branch", "master", nil, head, false},
+		{"bare-branch-as-branch", "master", []fnOpt{WithRefAsBranch(true)}, head, false},
+		{"lightweight-tag", "v1.0.0", nil, first, false},
+		{"annotated-tag", "refs/tags/v1.0.1", nil, first, false},
+		{"unknown-ref", "nope", nil, "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			l := Locator(fileLocator(repoDir, tc.ref, "hello.txt"))
+			if tc.ref == "" {
+				l = NewFromPath(repoDir) + "#hello.txt"
+			}
+			res, err := l.Resolve(append(tc.opts, noAuth)...)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, res)
+
+			pinned, err := l.Pin(append(tc.opts, noAuth)...)
+			require.NoError(t, err)
+			components, err := pinned.Parse()
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, components.Commit)
+			require.Equal(t, "hello.txt", components.SubPath)
+		})
+	}
+}
+
+func TestComponentsString(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name    string
+		locator string
+		expect  string
+	}{
+		{"full", "git+https://github.com/example/test@v1#file.txt", "git+https://github.com/example/test@v1#file.txt"},
+		{"no-tool", "https://github.com/example/test", "https://github.com/example/test"},
+		{"ssh", "git+ssh://github.com/example/test@main", "git+ssh://github.com/example/test@main"},
+		{"slug", "example/test@v1#dir/", "git+https://github.com/example/test@v1#dir/"},
+		{"file", "file:///tmp/repo@abcdef0123#a/b", "file:///tmp/repo@abcdef0123#a/b"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := Locator(tc.locator).Parse()
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, c.String())
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Code review systems publish the proposed changes under their own ref
+// namespaces which are not fetched by default when cloning:
+//
+//	GitHub pull requests:   refs/pull/<number>/head (or /merge)
+//	GitLab merge requests:  refs/merge-requests/<number>/head (or /merge)
+//	Gerrit changes:         refs/changes/<last two digits>/<change>/<patchset>
+var (
+	pullRequestRefRegex       = regexp.MustCompile(`^refs/(?:pull|merge-requests)/([0-9]+)/(?:head|merge)$`)
+	pullRequestShorthandRegex = regexp.MustCompile(`^(pull|merge-requests)/([0-9]+)(/head|/merge)?$`)
+	gerritChangeRefRegex      = regexp.MustCompile(`^refs/changes/([0-9]{2})/([0-9]+)/([0-9]+)$`)
+	gerritShorthandRegex      = regexp.MustCompile(`^(?:refs/)?changes/([0-9]+)/([0-9]+)$`)
+)
+
+// expandReviewRef expands the shorthand forms of code review refs to their
+// full reference name. For example pull/123 is expanded to refs/pull/123/head
+// and changes/12345/2 to the sharded Gerrit ref refs/changes/45/12345/2.
+// Refs in any other form are returned unchanged.
+func expandReviewRef(ref string) string {
+	if m := gerritShorthandRegex.FindStringSubmatch(ref); m != nil {
+		return gerritChangeRef(m[1], m[2])
+	}
+
+	if m := pullRequestShorthandRegex.FindStringSubmatch(ref); m != nil {
+		suffix := m[3]
+		if suffix == "" {
+			suffix = "/head"
+		}
+		return "refs/" + m[1] + "/" + m[2] + suffix
+	}
+	return ref
+}
+
+// gerritChangeRef builds the ref name Gerrit uses to publish a patchset. Changes
+// are sharded in directories named after the last two digits of the change
+// number (zero padded).
+func gerritChangeRef(change, patchset string) string {
+	shard := change
+	if len(shard) < 2 {
+		shard = "0" + shard
+	}
+	return fmt.Sprintf("refs/changes/%s/%s/%s", shard[len(shard)-2:], change, patchset)
+}
+
+// isGerritChangeRef returns true if a full ref name points to a Gerrit
+// patchset and its shard matches the change number.
+func isGerritChangeRef(ref string) bool {
+	m := gerritChangeRefRegex.FindStringSubmatch(ref)
+	return m != nil && gerritChangeRef(m[2], m[3]) == ref
+}
+
+// isPullRequestRef returns true if a full ref name points to a pull request
+// or merge request.
+func isPullRequestRef(ref string) bool {
+	return pullRequestRefRegex.MatchString(ref)
+}
+
+// PullRequest returns the number of the GitHub pull request or GitLab merge
+// request the locator ref points to. If the ref is not a pull request ref,
+// an empty string is returned.
+func (c *Components) PullRequest() string {
+	if m := pullRequestRefRegex.FindStringSubmatch(c.refName()); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// GerritChange returns the change number and patchset of the Gerrit change
+// ref the locator points to. Empty strings are returned if the ref is not a
+// Gerrit change ref.
+func (c *Components) GerritChange() (change, patchset string) {
+	if !isGerritChangeRef(c.refName()) {
+		return "", ""
+	}
+	m := gerritChangeRefRegex.FindStringSubmatch(c.refName())
+	return m[2], m[3]
+}
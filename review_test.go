@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestRef points a reference in the test repository to a commit
+func setTestRef(t *testing.T, repoDir, name, commit string) {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(
+		plumbing.NewHashReference(plumbing.ReferenceName(name), plumbing.NewHash(commit)),
+	))
+}
+
+func TestPullRequestRefs(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	pr := commitTestFile(t, repoDir, "hello.txt", "pull request")
+	mr := commitTestFile(t, repoDir, "hello.txt", "merge request")
+
+	// Move the branch back so the review commits are only reachable from
+	// the review refs.
+	setTestRef(t, repoDir, "refs/heads/master", first)
+	setTestRef(t, repoDir, "refs/pull/12/head", pr)
+	setTestRef(t, repoDir, "refs/merge-requests/7/head", mr)
+
+	for _, tc := range []struct {
+		ref    string
+		number string
+		expect string
+		commit string
+	}{
+		{"refs/pull/12/head", "12", "pull request", pr},
+		{"pull/12", "12", "pull request", pr},
+		{"refs/merge-requests/7/head", "7", "merge request", mr},
+		{"merge-requests/7/head", "7", "merge request", mr},
+	} {
+		t.Run(tc.ref, func(t *testing.T) {
+			t.Parallel()
+			l := Locator(fileLocator(repoDir, tc.ref, "hello.txt"))
+			c, err := l.Parse()
+			require.NoError(t, err)
+			require.Equal(t, tc.number, c.PullRequest())
+			require.Empty(t, c.Tag)
+
+			var buf bytes.Buffer
+			require.NoError(t, CopyFile(l, &buf, noAuth))
+			require.Equal(t, tc.expect, buf.String())
+
+			commit, err := l.Resolve(noAuth)
+			require.NoError(t, err)
+			require.Equal(t, tc.commit, commit)
+		})
+	}
+
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+		refs, err := ListRemoteRefs(NewFromPath(repoDir), noAuth)
+		require.NoError(t, err)
+		pulls := 0
+		for _, r := range refs {
+			if r.Type == RefTypePull {
+				pulls++
+			}
+		}
+		require.Equal(t, 2, pulls)
+	})
+}
+
+func TestExpandReviewRef(t *testing.T) {
+	t.Parallel()
+	for ref, expect := range map[string]string{
+		"pull/1":                     "refs/pull/1/head",
+		"pull/1/merge":               "refs/pull/1/merge",
+		"merge-requests/45":          "refs/merge-requests/45/head",
+		"refs/pull/1/head":           "refs/pull/1/head",
+		"pull/abc":                   "pull/abc",
+		"main":                       "main",
+		"merge-requests/45/merge":    "refs/merge-requests/45/merge",
+		"refs/merge-requests/1/head": "refs/merge-requests/1/head",
+		"changes/12345/2":            "refs/changes/45/12345/2",
+		"refs/changes/12345/2":       "refs/changes/45/12345/2",
+		"changes/7/1":                "refs/changes/07/7/1",
+		"refs/changes/45/12345/2":    "refs/changes/45/12345/2",
+	} {
+		require.Equal(t, expect, expandReviewRef(ref), ref)
+	}
+}
+
+func TestGerritChangeRefs(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	patchset := commitTestFile(t, repoDir, "hello.txt", "patchset 2")
+	setTestRef(t, repoDir, "refs/heads/master", first)
+	setTestRef(t, repoDir, "refs/changes/45/12345/2", patchset)
+
+	for _, ref := range []string{"refs/changes/45/12345/2", "changes/12345/2"} {
+		t.Run(ref, func(t *testing.T) {
+			t.Parallel()
+			l := Locator(fileLocator(repoDir, ref, "hello.txt"))
+			c, err := l.Parse()
+			require.NoError(t, err)
+			change, ps := c.GerritChange()
+			require.Equal(t, "12345", change)
+			require.Equal(t, "2", ps)
+			require.Empty(t, c.PullRequest())
+
+			var buf bytes.Buffer
+			require.NoError(t, CopyFile(l, &buf, noAuth))
+			require.Equal(t, "patchset 2", buf.String())
+		})
+	}
+
+	t.Run("wrong-shard", func(t *testing.T) {
+		t.Parallel()
+		c, err := Locator(fileLocator(repoDir, "refs/changes/44/12345/2", "")).Parse()
+		require.NoError(t, err)
+		change, _ := c.GerritChange()
+		require.Empty(t, change)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+		refs, err := ListRemoteRefs(NewFromPath(repoDir), noAuth)
+		require.NoError(t, err)
+		found := false
+		for _, r := range refs {
+			if r.Type == RefTypeChange {
+				require.Equal(t, patchset, r.Commit)
+				found = true
+			}
+		}
+		require.True(t, found)
+	})
+}
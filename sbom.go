@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha1" //nolint:gosec // Required by the SPDX spec
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// SPDXDocument is a minimal SPDX 2.3 document describing the files of a
+// repository subtree. It marshals to the SPDX JSON format.
+type SPDXDocument struct {
+	SPDXID            string             `json:"SPDXID"`
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Files             []SPDXFile         `json:"files"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+// SPDXCreationInfo records when and by whom the document was created
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// SPDXPackage is the package representing the repository subtree
+type SPDXPackage struct {
+	SPDXID                  string                  `json:"SPDXID"`
+	Name                    string                  `json:"name"`
+	VersionInfo             string                  `json:"versionInfo,omitempty"`
+	DownloadLocation        string                  `json:"downloadLocation"`
+	SourceInfo              string                  `json:"sourceInfo,omitempty"`
+	FilesAnalyzed           bool                    `json:"filesAnalyzed"`
+	PackageVerificationCode *SPDXVerificationCode   `json:"packageVerificationCode,omitempty"`
+	LicenseConcluded        string                  `json:"licenseConcluded"`
+	LicenseDeclared         string                  `json:"licenseDeclared"`
+	CopyrightText           string                  `json:"copyrightText"`
+	ExternalRefs            []SPDXExternalReference `json:"externalRefs,omitempty"`
+}
+
+// SPDXVerificationCode is the SPDX package verification code
+type SPDXVerificationCode struct {
+	Value string `json:"packageVerificationCodeValue"`
+}
+
+// SPDXExternalReference links the package to an external identifier
+type SPDXExternalReference struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+// SPDXFile is a file in the repository subtree
+type SPDXFile struct {
+	SPDXID           string         `json:"SPDXID"`
+	FileName         string         `json:"fileName"`
+	Checksums        []SPDXChecksum `json:"checksums"`
+	LicenseConcluded string         `json:"licenseConcluded"`
+	CopyrightText    string         `json:"copyrightText"`
+}
+
+// SPDXChecksum is a digest of a file
+type SPDXChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+// SPDXRelationship relates two elements of the document
+type SPDXRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+const (
+	// spdxPackageID is the SPDX identifier of the subtree package
+	spdxPackageID = "SPDXRef-Package"
+
+	// spdxNoAssertion is set in the license and copyright fields
+	spdxNoAssertion = "NOASSERTION"
+)
+
+// GetSPDXSBOM fetches the subtree referenced by the locator and returns a
+// file level SPDX 2.3 SBOM of it. The document describes a package with the
+// locator as its download location and the commit as its version, which
+// contains every file in the subtree with its SHA-1 and SHA-256 digests.
+// Symbolic links are not listed. The include/exclude filters and
+// export-ignore options apply.
+func GetSPDXSBOM[T ~string](locator T, funcs ...fnOpt) (*SPDXDocument, error) {
+	cloned, entries, _, err := prepareArchive(Locator(locator), funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	components := cloned.Components
+	name := strings.TrimSuffix(strings.Trim(components.RepoPath, "/"), ".git")
+	if sub := strings.Trim(components.SubPath, "/"); sub != "" {
+		name += "/" + sub
+	}
+	location := components.DownloadLocation()
+
+	ns := sha256.Sum256([]byte(location + "\x00" + cloned.Commit))
+	doc := &SPDXDocument{
+		SPDXID:            "SPDXRef-DOCUMENT",
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		Name:              name,
+		DocumentNamespace: "https://spdx.org/spdxdocs/vcslocator/" + hex.EncodeToString(ns[:]),
+		CreationInfo: SPDXCreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: vcslocator"},
+		},
+		DocumentDescribes: []string{spdxPackageID},
+		Files:             []SPDXFile{},
+		Relationships:     []SPDXRelationship{},
+	}
+
+	sha1s := []string{}
+	for i := range entries {
+		entry := &entries[i]
+		if entry.isDir() || entry.Mode == filemode.Symlink {
+			continue
+		}
+
+		r, _, err := openBlob(cloned, entry)
+		if err != nil {
+			return nil, err
+		}
+		h1, h256 := sha1.New(), sha256.New() //nolint:gosec
+		_, err = io.Copy(io.MultiWriter(h1, h256), r)
+		r.Close() //nolint:errcheck,gosec
+		if err != nil {
+			return nil, fmt.Errorf("hashing %q: %w", entry.Path, err)
+		}
+
+		id := fmt.Sprintf("SPDXRef-File-%d", len(doc.Files)+1)
+		sum1 := hex.EncodeToString(h1.Sum(nil))
+		sha1s = append(sha1s, sum1)
+		doc.Files = append(doc.Files, SPDXFile{
+			SPDXID:   id,
+			FileName: "./" + entry.Path,
+			Checksums: []SPDXChecksum{
+				{Algorithm: "SHA1", Value: sum1},
+				{Algorithm: "SHA256", Value: hex.EncodeToString(h256.Sum(nil))},
+			},
+			LicenseConcluded: spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+		})
+		doc.Relationships = append(doc.Relationships, SPDXRelationship{
+			Element: spdxPackageID, Type: "CONTAINS", Related: id,
+		})
+	}
+
+	// The verification code is the SHA-1 of the sorted file SHA-1s
+	slices.Sort(sha1s)
+	code := sha1.Sum([]byte(strings.Join(sha1s, ""))) //nolint:gosec
+
+	doc.Packages = []SPDXPackage{{
+		SPDXID:                  spdxPackageID,
+		Name:                    name,
+		VersionInfo:             cloned.Commit,
+		DownloadLocation:        location,
+		SourceInfo:              components.sourceInfo(cloned.Commit),
+		FilesAnalyzed:           true,
+		PackageVerificationCode: &SPDXVerificationCode{Value: hex.EncodeToString(code[:])},
+		LicenseConcluded:        spdxNoAssertion,
+		LicenseDeclared:         spdxNoAssertion,
+		CopyrightText:           spdxNoAssertion,
+		ExternalRefs: []SPDXExternalReference{{
+			Category: "PERSISTENT-ID",
+			Type:     "gitoid",
+			Locator:  "gitoid:commit:sha1:" + cloned.Commit,
+		}},
+	}}
+	doc.Relationships = append([]SPDXRelationship{{
+		Element: doc.SPDXID, Type: "DESCRIBES", Related: spdxPackageID,
+	}}, doc.Relationships...)
+	return doc, nil
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSPDXSBOM(t *testing.T) {
+	t.Parallel()
+
+	repoDir, commit := initArchiveTestRepo(t)
+	doc, err := GetSPDXSBOM(fileLocator(repoDir, commit, "pkg/sub"), WithSystemCredentials(false))
+	require.NoError(t, err)
+
+	require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	require.Equal(t, []string{spdxPackageID}, doc.DocumentDescribes)
+	require.Len(t, doc.Packages, 1)
+	pkg := doc.Packages[0]
+	require.Equal(t, commit, pkg.VersionInfo)
+	require.Equal(t, "git+"+fileLocator(repoDir, commit, "pkg/sub"), pkg.DownloadLocation)
+	require.NoError(t, ValidateDownloadLocation(pkg.DownloadLocation))
+
+	require.Len(t, doc.Files, 2)
+	require.Equal(t, "./pkg/sub/data.bin", doc.Files[0].FileName)
+	require.Equal(t, "./pkg/sub/lib.go", doc.Files[1].FileName)
+
+	sum := func(s string) string {
+		h := sha1.Sum([]byte(s)) //nolint:gosec
+		return hex.EncodeToString(h[:])
+	}
+	require.Equal(t, SPDXChecksum{Algorithm: "SHA1", Value: sum("package sub\n")}, doc.Files[1].Checksums[0])
+
+	// Verification code of the sorted file SHA-1s
+	sums := []string{sum("binary"), sum("package sub\n")}
+	if sums[1] < sums[0] {
+		sums[0], sums[1] = sums[1], sums[0]
+	}
+	require.Equal(t, sum(sums[0]+sums[1]), pkg.PackageVerificationCode.Value)
+
+	require.Len(t, doc.Relationships, 3)
+	require.Equal(t, "DESCRIBES", doc.Relationships[0].Type)
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"packageVerificationCodeValue"`)
+}
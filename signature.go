@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Types of objects whose signatures can be verified
+const (
+	ObjectTypeCommit = "commit"
+	ObjectTypeTag    = "tag"
+)
+
+// SignatureVerification captures the result of verifying the signature of
+// a commit or annotated tag.
+type SignatureVerification struct {
+	// ObjectType is the type of the verified object: commit or tag
+	ObjectType string
+
+	// Hash is the hash of the verified object
+	Hash string
+
+	// Signed is true when the object carries a signature
+	Signed bool
+
+	// Verified is true when the signature was verified against the keyring
+	Verified bool
+
+	// KeyID and Fingerprint identify the primary key that signed the object
+	KeyID       string
+	Fingerprint string
+
+	// Identities lists the user ids bound to the signing key
+	Identities []string
+
+	// Reason explains why the verification failed
+	Reason string
+}
+
+// VerifySignature checks the PGP signature of the object referenced by the
+// locator against the public keys in the armored keyring. If the locator
+// points to an annotated tag, the signature of the tag object is verified,
+// otherwise the signature of the commit the locator resolves to.
+//
+// Failing verifications are not returned as errors, the returned structure
+// reports if the object is signed and verified. Errors are only returned if
+// the data could not be fetched.
+func VerifySignature[T ~string](locator T, keyring string, funcs ...fnOpt) (*SignatureVerification, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if keyring == "" {
+		return nil, errors.New("no keyring specified to verify signatures")
+	}
+
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	// Annotated tags carry their own signature
+	if cloned.Components.Tag != "" {
+		ref, err := cloned.Repo.Reference(plumbing.NewTagReferenceName(cloned.Components.Tag), true)
+		if err != nil {
+			return nil, fmt.Errorf("reading tag reference: %w", err)
+		}
+
+		tag, err := cloned.Repo.TagObject(ref.Hash())
+		switch {
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			// Lightweight tags are verified using the commit signature
+		case err != nil:
+			return nil, fmt.Errorf("reading tag object: %w", err)
+		default:
+			res := &SignatureVerification{
+				ObjectType: ObjectTypeTag,
+				Hash:       tag.Hash.String(),
+				Signed:     tag.PGPSignature != "",
+			}
+			if !res.Signed {
+				res.Reason = "tag is not signed"
+				return res, nil
+			}
+			entity, err := tag.Verify(keyring)
+			res.setEntity(entity, err)
+			return res, nil
+		}
+	}
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+
+	res := &SignatureVerification{
+		ObjectType: ObjectTypeCommit,
+		Hash:       commit.Hash.String(),
+		Signed:     commit.PGPSignature != "",
+	}
+	if !res.Signed {
+		res.Reason = "commit is not signed"
+		return res, nil
+	}
+	entity, err := commit.Verify(keyring)
+	res.setEntity(entity, err)
+	return res, nil
+}
+
+// setEntity records the outcome of the verification in the result
+func (sv *SignatureVerification) setEntity(entity *openpgp.Entity, err error) {
+	if err != nil {
+		sv.Reason = err.Error()
+		return
+	}
+
+	sv.Verified = true
+	if entity == nil || entity.PrimaryKey == nil {
+		return
+	}
+
+	sv.KeyID = entity.PrimaryKey.KeyIdString()
+	sv.Fingerprint = fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+	sv.Identities = []string{}
+	for name := range entity.Identities {
+		sv.Identities = append(sv.Identities, name)
+	}
+	slices.Sort(sv.Identities)
+}
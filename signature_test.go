@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPGPKey generates a PGP key and returns it with its armored public key
+func newTestPGPKey(t *testing.T, name string) (entity *openpgp.Entity, armored string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+	return entity, buf.String()
+}
+
+func TestVerifySignature(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	signer, signerKey := newTestPGPKey(t, "signer")
+	_, otherKey := newTestPGPKey(t, "other")
+
+	repoDir, unsigned := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "hello.txt"), []byte("signed"), 0o600))
+	_, err = wt.Add("hello.txt")
+	require.NoError(t, err)
+	sig := &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()}
+	signedHash, err := wt.Commit("signed commit", &git.CommitOptions{Author: sig, SignKey: signer})
+	require.NoError(t, err)
+	signed := signedHash.String()
+
+	_, err = repo.CreateTag("v1.0.0", plumbing.NewHash(signed), &git.CreateTagOptions{
+		Tagger: sig, Message: "signed tag", SignKey: signer,
+	})
+	require.NoError(t, err)
+	tagTestRepo(t, repoDir, "v0.1.0", unsigned, "unsigned tag")
+
+	t.Run("signed-commit", func(t *testing.T) {
+		t.Parallel()
+		res, err := VerifySignature(fileLocator(repoDir, signed, ""), signerKey, noAuth)
+		require.NoError(t, err)
+		require.Equal(t, ObjectTypeCommit, res.ObjectType)
+		require.Equal(t, signed, res.Hash)
+		require.True(t, res.Signed)
+		require.True(t, res.Verified, res.Reason)
+		require.Equal(t, signer.PrimaryKey.KeyIdString(), res.KeyID)
+		require.Equal(t, []string{"signer <signer@example.com>"}, res.Identities)
+	})
+
+	t.Run("wrong-key", func(t *testing.T) {
+		t.Parallel()
+		res, err := VerifySignature(fileLocator(repoDir, signed, ""), otherKey, noAuth)
+		require.NoError(t, err)
+		require.True(t, res.Signed)
+		require.False(t, res.Verified)
+		require.NotEmpty(t, res.Reason)
+	})
+
+	t.Run("unsigned-commit", func(t *testing.T) {
+		t.Parallel()
+		res, err := VerifySignature(fileLocator(repoDir, unsigned, ""), signerKey, noAuth)
+		require.NoError(t, err)
+		require.False(t, res.Signed)
+		require.False(t, res.Verified)
+	})
+
+	t.Run("signed-tag", func(t *testing.T) {
+		t.Parallel()
+		res, err := VerifySignature(fileLocator(repoDir, "v1.0.0", ""), signerKey, noAuth)
+		require.NoError(t, err)
+		require.Equal(t, ObjectTypeTag, res.ObjectType)
+		require.True(t, res.Verified, res.Reason)
+	})
+
+	t.Run("unsigned-tag", func(t *testing.T) {
+		t.Parallel()
+		res, err := VerifySignature(fileLocator(repoDir, "v0.1.0", ""), signerKey, noAuth)
+		require.NoError(t, err)
+		require.Equal(t, ObjectTypeTag, res.ObjectType)
+		require.False(t, res.Signed)
+	})
+
+	t.Run("no-keyring", func(t *testing.T) {
+		t.Parallel()
+		_, err := VerifySignature(fileLocator(repoDir, signed, ""), "", noAuth)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	intoto "github.com/in-toto/attestation/go/v1"
+)
+
+// slsaLegacyCommitDigest is the digest name SLSA v0.2 materials use for git
+// commits.
+const slsaLegacyCommitDigest = "sha1"
+
+// GetSLSAMaterial resolves the locator and returns it as an SLSA provenance
+// material (a resolved dependency in SLSA v1). The uri is the locator in
+// SPDX form and the digest records the commit it resolves to. Resolving the
+// commit only lists the remote references, nothing is cloned.
+func GetSLSAMaterial[T ~string](locator T, funcs ...fnOpt) (*intoto.ResourceDescriptor, error) {
+	l := Locator(locator)
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing locator: %w", err)
+	}
+
+	commit, err := l.Resolve(funcs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &intoto.ResourceDescriptor{
+		Uri: components.DownloadLocation(),
+		Digest: map[string]string{
+			DigestAlgorithmGitCommit: commit,
+		},
+	}, nil
+}
+
+// VerifySLSAMaterials checks that the materials of a provenance attestation
+// include the repository of the locator at the commit it resolves to. Both
+// SLSA v1 (gitCommit) and v0.2 (sha1) commit digests are supported.
+func VerifySLSAMaterials[T ~string](locator T, materials []*intoto.ResourceDescriptor, funcs ...fnOpt) error {
+	l := Locator(locator)
+	components, err := l.Parse(funcs...)
+	if err != nil {
+		return fmt.Errorf("parsing locator: %w", err)
+	}
+
+	// Only contact the remote if the locator is not pinned to a commit
+	commit := components.Commit
+	if len(commit) != 40 {
+		commit, err = l.Resolve(funcs...)
+		if err != nil {
+			return err
+		}
+	}
+
+	found := false
+	for _, m := range materials {
+		if m == nil || m.GetUri() == "" {
+			continue
+		}
+		mc, err := Locator(m.GetUri()).Parse()
+		if err != nil || !sameRepository(components, mc) {
+			continue
+		}
+		found = true
+
+		digest := m.GetDigest()[DigestAlgorithmGitCommit]
+		if digest == "" {
+			digest = m.GetDigest()[slsaLegacyCommitDigest]
+		}
+		if strings.EqualFold(digest, commit) {
+			return nil
+		}
+	}
+
+	if !found {
+		return errors.New("provenance materials do not include the locator repository")
+	}
+	return fmt.Errorf("provenance materials do not record the locator repository at commit %s", commit)
+}
+
+// sameRepository returns true if two sets of components point to the same
+// repository, regardless of tool, ref or subpath.
+func sameRepository(a, b *Components) bool {
+	normalize := func(p string) string {
+		return strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+	}
+	return strings.EqualFold(a.Hostname, b.Hostname) &&
+		normalize(a.RepoPath) == normalize(b.RepoPath)
+}
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+
+	intoto "github.com/in-toto/attestation/go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSLSAMaterial(t *testing.T) {
+	t.Parallel()
+
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{"hello.txt": "hello"})
+	tagTestRepo(t, repoDir, "v1.0.0", commitHash, "release")
+
+	material, err := GetSLSAMaterial(fileLocator(repoDir, "v1.0.0", ""), WithSystemCredentials(false))
+	require.NoError(t, err)
+	require.Equal(t, "git+"+fileLocator(repoDir, "v1.0.0", ""), material.GetUri())
+	require.Equal(t, map[string]string{DigestAlgorithmGitCommit: commitHash}, material.GetDigest())
+}
+
+func TestVerifySLSAMaterials(t *testing.T) {
+	t.Parallel()
+
+	commit := "25c779ba165d1f4fac6fc2ce938bf40c1f8ab1a6"
+	other := "0000000000000000000000000000000000000000"
+	locator := "git+https://github.com/example/test@" + commit + "#file.txt"
+
+	for _, tc := range []struct {
+		name      string
+		materials []*intoto.ResourceDescriptor
+		mustErr   bool
+	}{
+		{"match", []*intoto.ResourceDescriptor{
+			{Uri: "git+https://github.com/example/other", Digest: map[string]string{DigestAlgorithmGitCommit: other}},
+			{Uri: "git+https://github.com/example/test@refs/heads/main", Digest: map[string]string{DigestAlgorithmGitCommit: commit}},
+		}, false},
+		{"legacy-digest", []*intoto.ResourceDescriptor{
+			{Uri: "git+https://GitHub.com/example/test.git", Digest: map[string]string{"sha1": commit}},
+		}, false},
+		{"wrong-commit", []*intoto.ResourceDescriptor{
+			{Uri: "git+https://github.com/example/test", Digest: map[string]string{DigestAlgorithmGitCommit: other}},
+		}, true},
+		{"missing-repo", []*intoto.ResourceDescriptor{
+			{Uri: "git+https://github.com/example/other", Digest: map[string]string{DigestAlgorithmGitCommit: commit}},
+		}, true},
+		{"no-materials", nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := VerifySLSAMaterials(locator, tc.materials)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// sourceHutHost is the hostname of the git service of SourceHut
+const sourceHutHost = "git.sr.ht"
+
+// SourceHutProvider fetches data from the web endpoints of git.sr.ht and
+// other SourceHut instances, which serve raw files and archives at any
+// commit.
+type SourceHutProvider struct{}
+
+// Name returns the name of the forge
+func (SourceHutProvider) Name() string {
+	return "sourcehut"
+}
+
+// sourceHutRepo returns the owner and name of the repository of the
+// components. SourceHut repositories live under the ~user namespace of
+// their owner.
+func sourceHutRepo(c *Components) (owner, repo string, err error) {
+	owner, repo, err = ownerRepo(c)
+	if err != nil {
+		return "", "", err
+	}
+	if !strings.HasPrefix(owner, "~") {
+		return "", "", fmt.Errorf("%q is not a SourceHut ~user/repository path", c.RepoPath)
+	}
+	return owner, repo, nil
+}
+
+// OpenFile requests a file from the blob endpoint, which serves its raw
+// contents
+func (SourceHutProvider) OpenFile(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	owner, repo, err := sourceHutRepo(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"https://%s/%s/%s/blob/%s/%s", c.Hostname,
+		url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(c.Commit), escapePath(c.SubPath),
+	)
+	return forgeGet(client, auth, u, nil)
+}
+
+// OpenArchive requests the tarball of a commit from the archive endpoint
+func (SourceHutProvider) OpenArchive(client *http.Client, auth transport.AuthMethod, c *Components) (*http.Response, error) {
+	owner, repo, err := sourceHutRepo(c)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf(
+		"https://%s/%s/%s/archive/%s.tar.gz", c.Hostname,
+		url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(c.Commit),
+	)
+	return forgeGet(client, auth, u, nil)
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestSourceHutFastPath(t *testing.T) {
+	t.Parallel()
+	archive := newTestArchive(t, []testArchiveEntry{{name: "README.md", data: "hello from sourcehut\n"}})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /~user/repo/blob/{commit}/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("commit") != testCommit {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.PathValue("path") {
+		case "README.md":
+			io.WriteString(w, "hello from sourcehut\n") //nolint:errcheck,gosec
+		case "doc/a b.txt":
+			io.WriteString(w, "nested\n") //nolint:errcheck,gosec
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	mux.HandleFunc("GET /~user/repo/archive/{archive}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("archive") != testCommit+".tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive) //nolint:errcheck,gosec
+	})
+	srv := newForgeTestServer(t, mux)
+	provider := []fnOpt{WithHostAlias("sr.example.com", "sourcehut")}
+	base := "git+https://sr.example.com/~user/repo@" + testCommit
+
+	srv.run(t, copyForgeFile, provider, []forgeTest{
+		{"file", base + "#README.md", nil, "hello from sourcehut\n", false},
+		{"nested", base + "#doc/a b.txt", nil, "nested\n", false},
+		{"not-found", base + "#missing.txt", nil, nil, true},
+		{"no-user", "git+https://sr.example.com/user/repo@" + testCommit + "#README.md", nil, nil, true},
+	})
+
+	// The tree can't be listed to check its attributes, the archive is only
+	// used when export-ignore is honored
+	srv.run(t, downloadForgeTree, provider, []forgeTest{
+		{"download", base, []fnOpt{WithExportIgnore(true)}, map[string]string{"README.md": "hello from sourcehut\n"}, false},
+	})
+}
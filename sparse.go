@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// sparseCheckout checks if the worktree of a locator can be limited to its
+// subpath. Only in-memory worktrees are checked out sparsely and only when
+// nothing outside the subpath is read: links are not resolved and neither
+// .gitattributes nor .gitmodules files are needed.
+func sparseCheckout(components *Components, opts *options) bool {
+	return strings.Trim(components.SubPath, "/") != "" &&
+		opts.ClonePath == "" && !opts.noCheckout && opts.refreshStorer == nil &&
+		!opts.ExportIgnore && !opts.Submodules &&
+		(opts.Symlinks == SymlinksSkip || opts.Symlinks == SymlinksReject)
+}
+
+// sparseCheckoutDirs returns the paths to check out to materialize the
+// subpath in the tree of a commit. It returns nil, meaning the whole tree
+// must be checked out, when the subpath does not exist or is reached
+// through a symbolic link as the link target has to be in the worktree.
+func sparseCheckoutDirs(repo *git.Repository, commitHash, subpath string) ([]string, error) {
+	commit, err := repo.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit tree: %w", err)
+	}
+
+	subpath = strings.Trim(subpath, "/")
+	parts := strings.Split(subpath, "/")
+	for i := range parts {
+		entry, err := tree.FindEntry(strings.Join(parts[:i+1], "/"))
+		if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("looking up %q: %w", subpath, err)
+		}
+		switch entry.Mode {
+		case filemode.Symlink:
+			return nil, nil
+		case filemode.Dir:
+			if i == len(parts)-1 {
+				// Paths are matched by prefix, the slash keeps
+				// siblings sharing it (docs-old) out
+				return []string{subpath + "/"}, nil
+			}
+		}
+	}
+	return []string{subpath}, nil
+}
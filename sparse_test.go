@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparseCheckout(t *testing.T) {
+	t.Parallel()
+	noAuth := WithSystemCredentials(false)
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		"README.md":      "readme",
+		"docs/guide.md":  "guide",
+		"docs-old/a.md":  "old",
+		"src/main.go":    "package main",
+		"src/lib/lib.go": "package lib",
+	})
+	commitTestSymlinks(t, repoDir, map[string]string{"manual": "docs"})
+
+	for _, tc := range []struct {
+		name     string
+		fragment string
+		present  []string
+		missing  []string
+	}{
+		{"dir", "docs", []string{"docs/guide.md"}, []string{"README.md", "docs-old/a.md", "src/main.go"}},
+		{"nested", "src/lib", []string{"src/lib/lib.go"}, []string{"src/main.go", "docs/guide.md"}},
+		{"file", "src/main.go", []string{"src/main.go"}, []string{"src/lib/lib.go", "README.md"}},
+		{"symlink", "manual", []string{"docs/guide.md", "README.md"}, nil},
+		{"no-subpath", "", []string{"docs/guide.md", "README.md"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			opts := defaultOptions
+			require.NoError(t, noAuth(&opts))
+			cloned, err := cloneRepo(Locator(fileLocator(repoDir, "refs/heads/master", tc.fragment)), &opts, noAuth)
+			require.NoError(t, err)
+			for _, p := range tc.present {
+				_, err := cloned.FS.Stat(p)
+				require.NoError(t, err, p)
+			}
+			for _, p := range tc.missing {
+				_, err := cloned.FS.Stat(p)
+				require.Error(t, err, p)
+			}
+		})
+	}
+}
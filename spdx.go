@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Special values of the SPDX 2.3 PackageDownloadLocation field
+const (
+	DownloadLocationNone        = "NONE"
+	DownloadLocationNoAssertion = "NOASSERTION"
+)
+
+// spdxVCSTools are the version control tools allowed in SPDX VCS locators
+var spdxVCSTools = []string{"git", "hg", "svn", "bzr"}
+
+// DownloadLocation returns the components as an SPDX 2.3
+// PackageDownloadLocation VCS locator. Unlike String, the VCS tool is always
+// included as the spec requires it (ie git+https://github.com/org/repo).
+func (c *Components) DownloadLocation() string {
+	cc := *c
+	if cc.Tool == "" {
+		cc.Tool = ToolGit
+	}
+	if cc.Transport == TransportFile {
+		return cc.Tool + "+" + cc.String()
+	}
+	return cc.String()
+}
+
+// ValidateDownloadLocation checks that a string is a valid SPDX 2.3
+// PackageDownloadLocation: NONE, NOASSERTION, a URL or a VCS locator.
+func ValidateDownloadLocation(location string) error {
+	_, err := NormalizeDownloadLocation(location)
+	return err
+}
+
+// NormalizeDownloadLocation validates an SPDX 2.3 PackageDownloadLocation
+// and returns it in its canonical form:
+//
+//   - NONE and NOASSERTION are matched case insensitively and uppercased
+//   - git VCS locators are reassembled from their parsed components
+//   - GitHub slugs (org/repo) are expanded to full VCS locators
+//   - other URLs get their scheme and hostname lowercased
+func NormalizeDownloadLocation(location string) (string, error) {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return "", errors.New("download location is empty")
+	}
+
+	for _, special := range []string{DownloadLocationNone, DownloadLocationNoAssertion} {
+		if strings.EqualFold(location, special) {
+			return special, nil
+		}
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("parsing download location: %w", err)
+	}
+
+	// Scheme-less strings are only valid as repository slugs
+	if u.Scheme == "" {
+		c, err := Locator(location).Parse()
+		if err != nil {
+			return "", fmt.Errorf("download location %q is not a URL or VCS locator", location)
+		}
+		return c.DownloadLocation(), nil
+	}
+
+	tool, transport, isVCS := strings.Cut(u.Scheme, "+")
+	if !isVCS {
+		if u.Host == "" && u.Scheme != TransportFile {
+			return "", fmt.Errorf("download location URL %q has no host", location)
+		}
+		u.Host = strings.ToLower(u.Host)
+		return u.String(), nil
+	}
+
+	if !slices.Contains(spdxVCSTools, tool) {
+		return "", fmt.Errorf("unsupported VCS tool %q in download location", tool)
+	}
+	if transport == "" {
+		return "", fmt.Errorf("download location %q has no transport", location)
+	}
+
+	if tool != ToolGit {
+		if u.Host == "" {
+			return "", fmt.Errorf("download location %q has no host", location)
+		}
+		u.Host = strings.ToLower(u.Host)
+		return u.String(), nil
+	}
+
+	c, err := Locator(location).Parse()
+	if err != nil {
+		return "", fmt.Errorf("parsing VCS locator: %w", err)
+	}
+	if c.Hostname == "" && c.Transport != TransportFile {
+		return "", fmt.Errorf("download location %q has no host", location)
+	}
+	c.Hostname = strings.ToLower(c.Hostname)
+	return c.DownloadLocation(), nil
+}
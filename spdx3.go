@@ -0,0 +1,203 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// Content identifier types defined in SPDX 3.0
+const (
+	ContentIdentifierTypeGitOID = "gitoid"
+	ContentIdentifierTypeSWHID  = "swhid"
+)
+
+// spdx3ContentIdentifierType is the JSON-LD type of content identifiers
+const spdx3ContentIdentifierType = "software_ContentIdentifier"
+
+// SPDX3ContentIdentifier is an SPDX 3.0 software_ContentIdentifier
+type SPDX3ContentIdentifier struct {
+	Type  string `json:"type"`
+	Kind  string `json:"software_contentIdentifierType"`
+	Value string `json:"software_contentIdentifierValue"`
+}
+
+// SPDX3SourceArtifact captures the SPDX 3.0 properties that describe where a
+// software artifact was obtained from. It marshals to the JSON-LD property
+// names so it can be merged into an SPDX 3.0 software element.
+type SPDX3SourceArtifact struct {
+	// DownloadLocation is the software_downloadLocation VCS locator
+	DownloadLocation string `json:"software_downloadLocation,omitempty"`
+
+	// SourceInfo is the free form software_sourceInfo text
+	SourceInfo string `json:"software_sourceInfo,omitempty"`
+
+	// ContentIdentifiers lists the gitoids and SWHIDs of the artifact
+	ContentIdentifiers []SPDX3ContentIdentifier `json:"software_contentIdentifier,omitempty"`
+}
+
+// SPDX3SourceArtifact returns the SPDX 3.0 source properties of the
+// components. Content identifiers are only included when the components
+// point to a commit, use GetSPDX3SourceArtifact to resolve refs and to
+// identify the file or directory in the subpath.
+func (c *Components) SPDX3SourceArtifact() *SPDX3SourceArtifact {
+	a := &SPDX3SourceArtifact{
+		DownloadLocation:   c.DownloadLocation(),
+		ContentIdentifiers: []SPDX3ContentIdentifier{},
+	}
+	// Abbreviated hashes are not valid identifiers
+	if len(c.Commit) == 40 {
+		a.addRevision(c.Commit)
+	}
+	a.SourceInfo = c.sourceInfo(c.Commit)
+	return a
+}
+
+// GetSPDX3SourceArtifact fetches the locator and returns its SPDX 3.0 source
+// properties with the content identifiers of the resolved commit and the
+// object in the subpath.
+func GetSPDX3SourceArtifact[T ~string](locator T, funcs ...fnOpt) (*SPDX3SourceArtifact, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+
+	root, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit tree: %w", err)
+	}
+
+	a := &SPDX3SourceArtifact{
+		DownloadLocation:   cloned.Components.DownloadLocation(),
+		SourceInfo:         cloned.Components.sourceInfo(cloned.Commit),
+		ContentIdentifiers: []SPDX3ContentIdentifier{},
+	}
+	a.addRevision(cloned.Commit)
+
+	subpath := strings.Trim(cloned.Components.SubPath, "/")
+	if subpath == "" {
+		a.addObject(GitOIDTypeTree, root.Hash.String())
+		return a, nil
+	}
+
+	entry, err := root.FindEntry(subpath)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %q: %w", subpath, err)
+	}
+	switch entry.Mode {
+	case filemode.Dir:
+		a.addObject(GitOIDTypeTree, entry.Hash.String())
+	case filemode.Submodule:
+		return nil, fmt.Errorf("%q is a submodule", subpath)
+	default:
+		a.addObject(GitOIDTypeBlob, entry.Hash.String())
+	}
+	return a, nil
+}
+
+// ComponentsFromSPDX3 builds the locator components from SPDX 3.0 source
+// properties. If the download location does not point to a commit, the
+// commit is read from the revision content identifiers.
+func ComponentsFromSPDX3(a *SPDX3SourceArtifact) (*Components, error) {
+	if a == nil {
+		return nil, errors.New("source artifact is nil")
+	}
+
+	location, err := NormalizeDownloadLocation(a.DownloadLocation)
+	if err != nil {
+		return nil, err
+	}
+	if location == DownloadLocationNone || location == DownloadLocationNoAssertion {
+		return nil, fmt.Errorf("download location is %s", location)
+	}
+
+	c, err := Locator(location).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("parsing download location: %w", err)
+	}
+
+	for _, id := range a.ContentIdentifiers {
+		commit := ""
+		switch id.Kind {
+		case ContentIdentifierTypeGitOID:
+			commit = strings.TrimPrefix(id.Value, "gitoid:commit:sha1:")
+		case ContentIdentifierTypeSWHID:
+			commit = strings.TrimPrefix(id.Value, "swh:1:rev:")
+		}
+		if commit == "" || commit == id.Value {
+			continue
+		}
+
+		if c.Commit != "" && c.Commit != commit {
+			return nil, fmt.Errorf(
+				"download location commit %s does not match content identifier %s", c.Commit, id.Value,
+			)
+		}
+		c.RefString = commit
+		c.Commit = commit
+		c.Tag = ""
+		c.Branch = ""
+		c.AsOf = time.Time{}
+	}
+	return c, nil
+}
+
+// sourceInfo returns the software_sourceInfo text describing the origin of
+// the artifact.
+func (c *Components) sourceInfo(commit string) string {
+	repo := c.RepoURL()
+	if c.Transport == TransportFile {
+		repo = c.fetchURL()
+	}
+	info := "acquired from git repository " + repo
+	if c.RefString != "" && c.RefString != commit {
+		info += " at " + c.RefString
+	}
+	if commit != "" {
+		info += " (commit " + commit + ")"
+	}
+	if c.SubPath != "" {
+		info += ", path " + c.SubPath
+	}
+	return info
+}
+
+// addRevision adds the gitoid and SWHID of a commit
+func (a *SPDX3SourceArtifact) addRevision(commit string) {
+	a.ContentIdentifiers = append(a.ContentIdentifiers,
+		SPDX3ContentIdentifier{spdx3ContentIdentifierType, ContentIdentifierTypeGitOID, "gitoid:commit:sha1:" + commit},
+		SPDX3ContentIdentifier{spdx3ContentIdentifierType, ContentIdentifierTypeSWHID, "swh:1:rev:" + commit},
+	)
+}
+
+// addObject adds the gitoid and SWHID of a blob or tree
+func (a *SPDX3SourceArtifact) addObject(objectType, hash string) {
+	swhType := "cnt"
+	if objectType == GitOIDTypeTree {
+		swhType = "dir"
+	}
+	a.ContentIdentifiers = append(a.ContentIdentifiers,
+		SPDX3ContentIdentifier{spdx3ContentIdentifierType, ContentIdentifierTypeGitOID, "gitoid:" + objectType + ":sha1:" + hash},
+		SPDX3ContentIdentifier{spdx3ContentIdentifierType, ContentIdentifierTypeSWHID, "swh:1:" + swhType + ":" + hash},
+	)
+}
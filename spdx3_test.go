@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentsSPDX3SourceArtifact(t *testing.T) {
+	t.Parallel()
+
+	commit := "25c779ba165d1f4fac6fc2ce938bf40c1f8ab1a6"
+	c, err := Locator("https://github.com/example/test@" + commit + "#file.txt").Parse()
+	require.NoError(t, err)
+
+	a := c.SPDX3SourceArtifact()
+	require.Equal(t, "git+https://github.com/example/test@"+commit+"#file.txt", a.DownloadLocation)
+	require.Contains(t, a.SourceInfo, commit)
+	require.Len(t, a.ContentIdentifiers, 2)
+
+	data, err := json.Marshal(a)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"software_downloadLocation"`)
+	require.Contains(t, string(data), `{"type":"software_ContentIdentifier","software_contentIdentifierType":"swhid","software_contentIdentifierValue":"swh:1:rev:`+commit+`"}`)
+
+	// Refs without a commit have no identifiers
+	c, err = Locator("https://github.com/example/test@v1").Parse()
+	require.NoError(t, err)
+	require.Empty(t, c.SPDX3SourceArtifact().ContentIdentifiers)
+}
+
+func TestGetSPDX3SourceArtifact(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"hello.txt":     "hello",
+		"docs/guide.md": "# Guide",
+	})
+	tagTestRepo(t, repoDir, "v1.0.0", commitHash, "")
+
+	a, err := GetSPDX3SourceArtifact(fileLocator(repoDir, "v1.0.0", "hello.txt"), noAuth)
+	require.NoError(t, err)
+
+	blob := plumbing.ComputeHash(plumbing.BlobObject, []byte("hello")).String()
+	values := []string{}
+	for _, id := range a.ContentIdentifiers {
+		values = append(values, id.Value)
+	}
+	require.Equal(t, []string{
+		"gitoid:commit:sha1:" + commitHash,
+		"swh:1:rev:" + commitHash,
+		"gitoid:blob:sha1:" + blob,
+		"swh:1:cnt:" + blob,
+	}, values)
+
+	a, err = GetSPDX3SourceArtifact(fileLocator(repoDir, "v1.0.0", "docs"), noAuth)
+	require.NoError(t, err)
+	require.Len(t, a.ContentIdentifiers, 4)
+	require.Equal(t, ContentIdentifierTypeSWHID, a.ContentIdentifiers[3].Kind)
+	require.Contains(t, a.ContentIdentifiers[3].Value, "swh:1:dir:")
+
+	// Consuming the artifact pins the locator to the commit
+	c, err := ComponentsFromSPDX3(a)
+	require.NoError(t, err)
+	require.Equal(t, commitHash, c.Commit)
+	require.Equal(t, "docs", c.SubPath)
+	require.Empty(t, c.Tag)
+}
+
+func TestComponentsFromSPDX3(t *testing.T) {
+	t.Parallel()
+
+	commit := "25c779ba165d1f4fac6fc2ce938bf40c1f8ab1a6"
+	for _, tc := range []struct {
+		name     string
+		artifact *SPDX3SourceArtifact
+		commit   string
+		mustErr  bool
+	}{
+		{"location-only", &SPDX3SourceArtifact{DownloadLocation: "git+https://github.com/example/test@v1"}, "", false},
+		{"swhid", &SPDX3SourceArtifact{
+			DownloadLocation: "git+https://github.com/example/test@v1",
+			ContentIdentifiers: []SPDX3ContentIdentifier{
+				{spdx3ContentIdentifierType, ContentIdentifierTypeSWHID, "swh:1:rev:" + commit},
+			},
+		}, commit, false},
+		{"conflict", &SPDX3SourceArtifact{
+			DownloadLocation: "git+https://github.com/example/test@" + commit,
+			ContentIdentifiers: []SPDX3ContentIdentifier{
+				{spdx3ContentIdentifierType, ContentIdentifierTypeGitOID, "gitoid:commit:sha1:0000000000000000000000000000000000000000"},
+			},
+		}, "", true},
+		{"noassertion", &SPDX3SourceArtifact{DownloadLocation: DownloadLocationNoAssertion}, "", true},
+		{"nil", nil, "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ComponentsFromSPDX3(tc.artifact)
+			if tc.mustErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.commit, c.Commit)
+		})
+	}
+}
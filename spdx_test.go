@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDownloadLocation(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name     string
+		location string
+		expect   string
+		mustErr  bool
+	}{
+		{"none", "NONE", DownloadLocationNone, false},
+		{"none-lowercase", " none ", DownloadLocationNone, false},
+		{"noassertion", "NoAssertion", DownloadLocationNoAssertion, false},
+		{"vcs", "git+https://github.com/example/test@v1#file.txt", "git+https://github.com/example/test@v1#file.txt", false},
+		{"vcs-host-case", "git+https://GitHub.com/example/test", "git+https://github.com/example/test", false},
+		{"vcs-git-transport", "git+git://git.example.com/test.git", "git+git://git.example.com/test.git", false},
+		{"vcs-hg", "hg+https://hg.example.com/repo@default", "hg+https://hg.example.com/repo@default", false},
+		{"file-url", "file:///tmp/repo@main", "file:///tmp/repo@main", false},
+		{"vcs-file", "git+file:///tmp/repo@main", "git+file:///tmp/repo@main", false},
+		{"slug", "example/test@v1", "git+https://github.com/example/test@v1", false},
+		{"url", "https://Example.com/archive.tar.gz", "https://example.com/archive.tar.gz", false},
+		{"bad-tool", "cvs+https://example.com/repo", "", true},
+		{"no-host", "https:///archive.tar.gz", "", true},
+		{"not-a-url", "some text", "", true},
+		{"empty", "  ", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			res, err := NormalizeDownloadLocation(tc.location)
+			if tc.mustErr {
+				require.Error(t, err)
+				require.Error(t, ValidateDownloadLocation(tc.location))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, res)
+			require.NoError(t, ValidateDownloadLocation(tc.location))
+		})
+	}
+}
+
+func TestComponentsDownloadLocation(t *testing.T) {
+	t.Parallel()
+	for locator, expect := range map[string]string{
+		"https://github.com/example/test@v1":   "git+https://github.com/example/test@v1",
+		"git+ssh://github.com/example/test#a/": "git+ssh://github.com/example/test#a/",
+		"example/test":                         "git+https://github.com/example/test",
+		"file:///tmp/repo#file.txt":            "git+file:///tmp/repo#file.txt",
+	} {
+		c, err := Locator(locator).Parse()
+		require.NoError(t, err)
+		require.Equal(t, expect, c.DownloadLocation(), locator)
+	}
+}
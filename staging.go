@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// stagingPrefix is the name prefix of the staging directories
+const stagingPrefix = ".vcslocator-staging-"
+
+// newStagingDir resolves the download directory and creates a staging
+// directory to write files to. If localDir does not exist, the staging
+// directory is created next to it so it can be renamed into place in one
+// step. Otherwise it is created inside localDir, on the same filesystem.
+func newStagingDir(localDir string) (root, staging string, err error) {
+	root, err = filepath.Abs(localDir)
+	if err != nil {
+		return "", "", fmt.Errorf("resolving destination directory: %w", err)
+	}
+
+	parent := filepath.Dir(root)
+	if err := os.MkdirAll(parent, os.FileMode(0o755)); err != nil {
+		return "", "", fmt.Errorf("creating destination parent directory: %w", err)
+	}
+	parent, err = filepath.EvalSymlinks(parent)
+	if err != nil {
+		return "", "", fmt.Errorf("resolving destination directory: %w", err)
+	}
+	root = filepath.Join(parent, filepath.Base(root))
+
+	stagingParent := parent
+	if _, err := os.Lstat(root); err == nil {
+		root, err = filepath.EvalSymlinks(root)
+		if err != nil {
+			return "", "", fmt.Errorf("resolving destination directory: %w", err)
+		}
+		stagingParent = root
+	}
+
+	staging, err = os.MkdirTemp(stagingParent, stagingPrefix)
+	if err != nil {
+		return "", "", fmt.Errorf("creating staging directory: %w", err)
+	}
+	if err := os.Chmod(staging, os.FileMode(0o755)); err != nil {
+		os.RemoveAll(staging) //nolint:errcheck,gosec
+		return "", "", fmt.Errorf("setting staging directory mode: %w", err)
+	}
+	return root, staging, nil
+}
+
+// commitStagingDir moves the staged files to root. If root does not exist
+// the whole staging directory is renamed, otherwise each file is renamed
+// into its place, replacing existing files.
+func commitStagingDir(staging, root string) error {
+	if _, err := os.Lstat(root); errors.Is(err, fs.ErrNotExist) {
+		if err := os.Rename(staging, root); err != nil {
+			return fmt.Errorf("moving staging directory into place: %w", err)
+		}
+		return nil
+	}
+
+	return filepath.WalkDir(staging, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return fmt.Errorf("computing staged file path: %w", err)
+		}
+
+		// Renaming replaces links at the destination without following them
+		dest, err := destinationPath(root, filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(path, dest); err != nil {
+			return fmt.Errorf("moving %q into place: %w", rel, err)
+		}
+		return nil
+	})
+}
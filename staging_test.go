@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadStaging(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		"data/a.txt": "a",
+		"data/b.txt": "b",
+	})
+	commit := commitTestSymlinks(t, repoDir, map[string]string{
+		"data/z-link": "a.txt",
+	})
+	locator := fileLocator(repoDir, commit, "data")
+
+	t.Run("new-dir", func(t *testing.T) {
+		t.Parallel()
+		dest := filepath.Join(t.TempDir(), "sub", "dest")
+		require.NoError(t, Download(locator, dest, noAuth))
+
+		data, err := os.ReadFile(filepath.Join(dest, "data", "a.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "a", string(data))
+
+		entries, err := os.ReadDir(filepath.Dir(dest))
+		require.NoError(t, err)
+		require.Len(t, entries, 1, "staging directory left behind")
+	})
+
+	t.Run("new-dir-failure", func(t *testing.T) {
+		t.Parallel()
+		base := t.TempDir()
+		dest := filepath.Join(base, "dest")
+		require.Error(t, Download(locator, dest, noAuth, WithSymlinks(SymlinksReject)))
+		require.NoDirExists(t, dest)
+
+		entries, err := os.ReadDir(base)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("existing-dir", func(t *testing.T) {
+		t.Parallel()
+		dest := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dest, "data"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "data", "a.txt"), []byte("old"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "keep.txt"), []byte("keep"), 0o600))
+
+		require.NoError(t, Download(locator, dest, noAuth))
+
+		data, err := os.ReadFile(filepath.Join(dest, "data", "a.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "a", string(data))
+		require.FileExists(t, filepath.Join(dest, "data", "b.txt"))
+		require.FileExists(t, filepath.Join(dest, "keep.txt"))
+
+		entries, err := os.ReadDir(dest)
+		require.NoError(t, err)
+		require.Len(t, entries, 2, "staging directory left behind")
+	})
+
+	t.Run("existing-dir-failure", func(t *testing.T) {
+		t.Parallel()
+		dest := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dest, "data"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "data", "a.txt"), []byte("old"), 0o600))
+
+		// The link is walked last, after all the files were written
+		require.Error(t, Download(locator, dest, noAuth, WithSymlinks(SymlinksReject)))
+
+		data, err := os.ReadFile(filepath.Join(dest, "data", "a.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "old", string(data))
+		require.NoFileExists(t, filepath.Join(dest, "data", "b.txt"))
+
+		entries, err := os.ReadDir(dest)
+		require.NoError(t, err)
+		require.Len(t, entries, 1, "staging directory left behind")
+	})
+}
@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Types of the entries returned by Stat
+const (
+	EntryTypeFile      = "file"
+	EntryTypeDir       = "dir"
+	EntryTypeSymlink   = "symlink"
+	EntryTypeSubmodule = "submodule"
+)
+
+// EntryInfo describes a path in the repository at the locator ref
+type EntryInfo struct {
+	// Name is the base name of the entry
+	Name string
+
+	// Path is the path of the entry relative to the repository root
+	Path string
+
+	// Type is the kind of entry: file, dir, symlink or submodule
+	Type string
+
+	// Mode is the file mode recorded in the git tree
+	Mode os.FileMode
+
+	// Size is the size of the blob in bytes. It is zero for directories
+	// and submodules.
+	Size int64
+
+	// Hash is the git object id of the entry. For submodules it is the
+	// hash of the commit the submodule points to.
+	Hash string
+}
+
+// Stat returns information about the path referenced by the locator subpath.
+// The entry is looked up in the git tree of the commit, files are not read.
+// If the path does not exist at the ref, the error wraps fs.ErrNotExist.
+// Locators without a subpath describe the repository root directory.
+func Stat[T ~string](locator T, funcs ...fnOpt) (*EntryInfo, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit tree: %w", err)
+	}
+
+	subpath := strings.Trim(cloned.Components.SubPath, "/")
+	if subpath == "" {
+		return &EntryInfo{
+			Type: EntryTypeDir,
+			Mode: fs.ModeDir | 0o755,
+			Hash: tree.Hash.String(),
+		}, nil
+	}
+
+	entry, err := lookupEntry(tree, subpath, cloned.Commit)
+	if err != nil {
+		return nil, err
+	}
+	return newEntryInfo(cloned, subpath, entry)
+}
+
+// lookupEntry finds the entry at path p in a tree. If the path does not
+// exist, the returned error wraps fs.ErrNotExist.
+func lookupEntry(tree *object.Tree, p, commit string) (*object.TreeEntry, error) {
+	entry, err := tree.FindEntry(p)
+	if err != nil {
+		if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil, fmt.Errorf("%q not found at %s: %w", p, commit, fs.ErrNotExist)
+		}
+		return nil, fmt.Errorf("looking up %q: %w", p, err)
+	}
+	return entry, nil
+}
+
+// newEntryInfo builds the info of a tree entry found at path p
+func newEntryInfo(cloned *clonedRepo, p string, entry *object.TreeEntry) (*EntryInfo, error) {
+	info := &EntryInfo{
+		Name: path.Base(p),
+		Path: p,
+		Hash: entry.Hash.String(),
+	}
+	switch entry.Mode {
+	case filemode.Dir:
+		info.Type = EntryTypeDir
+		info.Mode = fs.ModeDir | 0o755
+		return info, nil
+	case filemode.Submodule:
+		info.Type = EntryTypeSubmodule
+		info.Mode = fs.ModeDir
+		return info, nil
+	case filemode.Symlink:
+		info.Type = EntryTypeSymlink
+		info.Mode = fs.ModeSymlink | 0o777
+	default:
+		info.Type = EntryTypeFile
+		info.Mode = entryPerm(entry.Mode)
+	}
+
+	blob, err := cloned.Repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading blob %q: %w", p, err)
+	}
+	info.Size = blob.Size
+	return info, nil
+}
+
+// List returns the entries of the directory referenced by the locator
+// subpath, sorted by name. Locators without a subpath list the repository
+// root. Only the direct children of the directory are returned.
+func List[T ~string](locator T, funcs ...fnOpt) ([]EntryInfo, error) {
+	opts := defaultOptions
+	for _, fn := range funcs {
+		if err := fn(&opts); err != nil {
+			return nil, err
+		}
+	}
+
+	cloned, err := cloneRepo(Locator(locator), &opts, funcs...)
+	if err != nil {
+		return nil, err
+	}
+	defer cloned.Close() //nolint:errcheck
+
+	commit, err := cloned.Repo.CommitObject(plumbing.NewHash(cloned.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("reading commit: %w", err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit tree: %w", err)
+	}
+
+	subpath := strings.Trim(cloned.Components.SubPath, "/")
+	if subpath != "" {
+		entry, err := lookupEntry(tree, subpath, cloned.Commit)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Mode != filemode.Dir {
+			return nil, fmt.Errorf("%q is not a directory", subpath)
+		}
+		if tree, err = tree.Tree(subpath); err != nil {
+			return nil, fmt.Errorf("reading tree %q: %w", subpath, err)
+		}
+	}
+
+	ret := make([]EntryInfo, 0, len(tree.Entries))
+	for i := range tree.Entries {
+		info, err := newEntryInfo(cloned, path.Join(subpath, tree.Entries[i].Name), &tree.Entries[i])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, *info)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+// Exists returns true if the locator subpath exists at the locator ref. A
+// missing path is not an error, errors are only returned when the repository
+// cannot be read.
+func Exists[T ~string](locator T, funcs ...fnOpt) (bool, error) {
+	if _, err := Stat(locator, funcs...); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetTreeHash returns the hash of the git tree object of the directory
+// referenced by the locator subpath at the resolved commit. Directories with
+// the same contents have the same tree hash, so comparing them is a cheap way
+// to check if a directory changed between two refs. Locators without a
+// subpath return the hash of the root tree.
+func GetTreeHash[T ~string](locator T, funcs ...fnOpt) (string, error) {
+	info, err := Stat(locator, funcs...)
+	if err != nil {
+		return "", err
+	}
+	if info.Type != EntryTypeDir {
+		return "", fmt.Errorf("%q is not a directory", info.Path)
+	}
+	return info.Hash, nil
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStat(t *testing.T) {
+	t.Parallel()
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		"SECURITY.md":  "report here\n",
+		"docs/main.md": "docs\n",
+	})
+	commit := commitTestSymlinks(t, repoDir, map[string]string{"link": "SECURITY.md"})
+
+	for _, tc := range []struct {
+		name     string
+		fragment string
+		expected *EntryInfo
+		mustErr  bool
+	}{
+		{"file", "SECURITY.md", &EntryInfo{Name: "SECURITY.md", Path: "SECURITY.md", Type: EntryTypeFile, Mode: 0o644, Size: 12}, false},
+		{"dir", "docs", &EntryInfo{Name: "docs", Path: "docs", Type: EntryTypeDir, Mode: fs.ModeDir | 0o755}, false},
+		{"symlink", "link", &EntryInfo{Name: "link", Path: "link", Type: EntryTypeSymlink, Mode: fs.ModeSymlink | 0o777, Size: 11}, false},
+		{"root", "", &EntryInfo{Type: EntryTypeDir, Mode: fs.ModeDir | 0o755}, false},
+		{"missing", "CONTRIBUTING.md", nil, true},
+		{"missing-dir", "nope/file.md", nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			info, err := Stat(fileLocator(repoDir, commit, tc.fragment))
+			if tc.mustErr {
+				require.ErrorIs(t, err, fs.ErrNotExist)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, info.Hash)
+			info.Hash = ""
+			require.Equal(t, tc.expected, info)
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		"README.md":        "readme\n",
+		"docs/index.md":    "index\n",
+		"docs/api/spec.md": "spec\n",
+	})
+	commit := commitTestSymlinks(t, repoDir, map[string]string{"docs/home.md": "index.md"})
+
+	t.Run("subdir", func(t *testing.T) {
+		t.Parallel()
+		entries, err := List(fileLocator(repoDir, commit, "docs"))
+		require.NoError(t, err)
+		for i := range entries {
+			require.NotEmpty(t, entries[i].Hash)
+			entries[i].Hash = ""
+		}
+		require.Equal(t, []EntryInfo{
+			{Name: "api", Path: "docs/api", Type: EntryTypeDir, Mode: fs.ModeDir | 0o755},
+			{Name: "home.md", Path: "docs/home.md", Type: EntryTypeSymlink, Mode: fs.ModeSymlink | 0o777, Size: 8},
+			{Name: "index.md", Path: "docs/index.md", Type: EntryTypeFile, Mode: 0o644, Size: 6},
+		}, entries)
+	})
+
+	t.Run("root", func(t *testing.T) {
+		t.Parallel()
+		entries, err := List(fileLocator(repoDir, commit, ""))
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, "README.md", entries[0].Name)
+		require.Equal(t, "docs", entries[1].Name)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		_, err := List(fileLocator(repoDir, commit, "README.md"))
+		require.Error(t, err)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		_, err := List(fileLocator(repoDir, commit, "src"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
+
+func TestExists(t *testing.T) {
+	t.Parallel()
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		"SECURITY.md": "report here\n",
+	})
+
+	exists, err := Exists(fileLocator(repoDir, commit, "SECURITY.md"))
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = Exists(fileLocator(repoDir, commit, "SECURITY.txt"))
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	_, err = Exists(fileLocator(repoDir, "0000000000000000000000000000000000000000", "SECURITY.md"))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestGetTreeHash(t *testing.T) {
+	t.Parallel()
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"docs/index.md": "index\n",
+		"src/main.go":   "package main\n",
+	})
+	second := commitTestFile(t, repoDir, "src/util.go", "package main\n")
+
+	docs1, err := GetTreeHash(fileLocator(repoDir, first, "docs"))
+	require.NoError(t, err)
+	docs2, err := GetTreeHash(fileLocator(repoDir, second, "docs"))
+	require.NoError(t, err)
+	require.Equal(t, docs1, docs2)
+
+	src1, err := GetTreeHash(fileLocator(repoDir, first, "src"))
+	require.NoError(t, err)
+	src2, err := GetTreeHash(fileLocator(repoDir, second, "src"))
+	require.NoError(t, err)
+	require.NotEqual(t, src1, src2)
+
+	info, err := GetCommitInfo(fileLocator(repoDir, second, ""))
+	require.NoError(t, err)
+	root, err := GetTreeHash(fileLocator(repoDir, second, ""))
+	require.NoError(t, err)
+	require.Equal(t, info.TreeHash, root)
+
+	_, err = GetTreeHash(fileLocator(repoDir, second, "src/main.go"))
+	require.Error(t, err)
+}
@@ -0,0 +1,325 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// Kinds of size limits
+const (
+	SizeLimitRepository = "repository"
+	SizeLimitFile       = "file"
+)
+
+// SizeLimitError is returned when the data fetched from a repository
+// exceeds the limits set with WithMaxRepoSize or WithMaxFileSize.
+type SizeLimitError struct {
+	// Kind is the limit that was exceeded: repository or file
+	Kind string
+
+	// Limit is the configured limit in bytes
+	Limit int64
+
+	// Size is the size that exceeded the limit
+	Size int64
+
+	// Object is the hash of the object that exceeded the limit
+	Object string
+}
+
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("%s size limit of %d bytes exceeded (%d bytes, object %s)", e.Kind, e.Limit, e.Size, e.Object)
+}
+
+// StorerFactory returns the go-git storer to write the repository
+// referenced by a locator to. It is called once for each repository
+// fetched, the storer must be empty.
+type StorerFactory func(components *Components) (storage.Storer, error)
+
+// newStorage returns the storer where the objects fetched from the remote
+// are written, configured according to the options. Repositories are kept
+// in memory unless disk storage or a custom storer is set. The returned
+// function removes the temporary storage once the repository is no longer
+// needed.
+func newStorage(opts *options, components *Components) (storage.Storer, func() error, error) {
+	noop := func() error { return nil }
+	switch {
+	case opts.StorerFactory != nil:
+		// Custom storers are owned by the caller
+		st, err := opts.StorerFactory(components)
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating storer: %w", err)
+		}
+		if st == nil {
+			return nil, nil, errors.New("storer factory returned a nil storer")
+		}
+		return wrapStorage(st, opts), noop, nil
+	case opts.DiskStorage && opts.ClonePath != "":
+		// The clone path is written as a regular repository
+		st := filesystem.NewStorage(osfs.New(filepath.Join(opts.ClonePath, git.GitDirName)), cache.NewObjectLRUDefault())
+		return wrapStorage(st, opts), noop, nil
+	case opts.DiskStorage:
+		dir, err := os.MkdirTemp(opts.StorageDir, "vcslocator-")
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating storage directory: %w", err)
+		}
+		st := filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault())
+		return wrapStorage(st, opts), func() error { return os.RemoveAll(dir) }, nil
+	case opts.MemoryBudget > 0:
+		st := &spillStorer{Storer: memory.NewStorage(), budget: opts.MemoryBudget, tempDir: opts.StorageDir}
+		return wrapStorage(st, opts), st.remove, nil
+	default:
+		return wrapStorage(memory.NewStorage(), opts), noop, nil
+	}
+}
+
+// wrapStorage wraps a storer to enforce the size and bandwidth limits set
+// in the options.
+func wrapStorage(st storage.Storer, opts *options) storage.Storer {
+	if opts.MaxRepoSize > 0 || opts.MaxFileSize > 0 {
+		st = &limitedStorer{
+			Storer:      st,
+			maxRepoSize: opts.MaxRepoSize,
+			maxFileSize: opts.MaxFileSize,
+		}
+	}
+	if opts.BandwidthLimit > 0 {
+		st = &throttledStorer{Storer: st, bytesPerSecond: opts.BandwidthLimit}
+	}
+	return st
+}
+
+// spillStorer keeps a repository in memory until the objects stored exceed
+// a budget. It then moves the repository to a temporary directory and keeps
+// writing to it on disk. The repository is laid out as a worktree so the
+// clone can be checked out to the same directory.
+//
+// The switch happens while objects are written, which go-git does from a
+// single goroutine when fetching.
+type spillStorer struct {
+	storage.Storer
+	budget int64
+	size   int64
+
+	// tempDir is where the temporary directory is created, the default
+	// directory for temporary files if empty
+	tempDir string
+
+	// dir is the temporary directory, set once the repository spills
+	dir string
+}
+
+// SetEncodedObject moves the repository to disk when the object makes it
+// go over the budget
+func (s *spillStorer) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	if s.dir == "" {
+		s.size += obj.Size()
+		if s.size > s.budget {
+			if err := s.spill(); err != nil {
+				return plumbing.ZeroHash, err
+			}
+		}
+	}
+	return s.Storer.SetEncodedObject(obj)
+}
+
+// spill copies the repository in memory to a filesystem storer in a
+// temporary directory and switches to it.
+func (s *spillStorer) spill() error {
+	dir, err := os.MkdirTemp(s.tempDir, "vcslocator-")
+	if err != nil {
+		return fmt.Errorf("creating spill directory: %w", err)
+	}
+	s.dir = dir
+	disk := filesystem.NewStorage(osfs.New(filepath.Join(dir, git.GitDirName)), cache.NewObjectLRUDefault())
+
+	objects, err := s.Storer.IterEncodedObjects(plumbing.AnyObject)
+	if err != nil {
+		return fmt.Errorf("listing objects to spill: %w", err)
+	}
+	if err := objects.ForEach(func(obj plumbing.EncodedObject) error {
+		_, err := disk.SetEncodedObject(obj)
+		return err
+	}); err != nil {
+		return fmt.Errorf("spilling objects: %w", err)
+	}
+
+	refs, err := s.Storer.IterReferences()
+	if err != nil {
+		return fmt.Errorf("listing references to spill: %w", err)
+	}
+	if err := refs.ForEach(disk.SetReference); err != nil {
+		return fmt.Errorf("spilling references: %w", err)
+	}
+
+	shallow, err := s.Storer.Shallow()
+	if err != nil {
+		return fmt.Errorf("reading shallow commits: %w", err)
+	}
+	if err := disk.SetShallow(shallow); err != nil {
+		return fmt.Errorf("spilling shallow commits: %w", err)
+	}
+
+	cfg, err := s.Storer.Config()
+	if err != nil {
+		return fmt.Errorf("reading repository config: %w", err)
+	}
+	if err := disk.SetConfig(cfg); err != nil {
+		return fmt.Errorf("spilling repository config: %w", err)
+	}
+
+	s.Storer = disk
+	return nil
+}
+
+// remove deletes the spill directory
+func (s *spillStorer) remove() error {
+	if s.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.dir)
+}
+
+// findSpillStorer returns the spill storer beneath the wrappers of the
+// storage limits, or nil if the repository is not stored in one.
+func findSpillStorer(st storage.Storer) *spillStorer {
+	if ref, ok := st.(*referenceStorer); ok {
+		st = ref.Storer
+	}
+	s, _ := unwrapLimits(st).(*spillStorer) //nolint:errcheck
+	return s
+}
+
+// unwrapLimits returns the storer wrapped to enforce the storage limits
+func unwrapLimits(st storage.Storer) storage.Storer {
+	for {
+		switch s := st.(type) {
+		case *limitedStorer:
+			st = s.Storer
+		case *throttledStorer:
+			st = s.Storer
+		default:
+			return st
+		}
+	}
+}
+
+// limitedStorer wraps a storer and fails when the objects written to it
+// exceed the configured sizes. As objects are written while the packfile
+// is received, the transfer is aborted as soon as a limit is crossed.
+type limitedStorer struct {
+	storage.Storer
+	maxRepoSize int64
+	maxFileSize int64
+
+	mu   sync.Mutex
+	size int64
+}
+
+// SetEncodedObject checks the limits before storing the object
+func (s *limitedStorer) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	if s.maxFileSize > 0 && obj.Type() == plumbing.BlobObject && obj.Size() > s.maxFileSize {
+		return plumbing.ZeroHash, &SizeLimitError{
+			Kind: SizeLimitFile, Limit: s.maxFileSize, Size: obj.Size(), Object: obj.Hash().String(),
+		}
+	}
+
+	if s.maxRepoSize > 0 {
+		s.mu.Lock()
+		s.size += obj.Size()
+		size := s.size
+		s.mu.Unlock()
+		if size > s.maxRepoSize {
+			return plumbing.ZeroHash, &SizeLimitError{
+				Kind: SizeLimitRepository, Limit: s.maxRepoSize, Size: size, Object: obj.Hash().String(),
+			}
+		}
+	}
+
+	return s.Storer.SetEncodedObject(obj)
+}
+
+// throttledStorer wraps a storer to limit the rate at which packfiles are
+// received. It implements storer.PackfileWriter so go-git streams the raw
+// packfile to it, the data is then parsed into the wrapped storer. Writing
+// slowly makes the transport read slowly from the connection, limiting the
+// bandwidth used by the transfer.
+type throttledStorer struct {
+	storage.Storer
+	bytesPerSecond int64
+}
+
+// PackfileWriter returns a writer that throttles the packfile stream
+func (s *throttledStorer) PackfileWriter() (io.WriteCloser, error) {
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := packfile.UpdateObjectStorage(s.Storer, pr)
+		pr.CloseWithError(err) //nolint:errcheck,gosec // Unblocks the writer on errors
+		done <- err
+	}()
+
+	return &throttledWriter{
+		w:              pw,
+		bytesPerSecond: s.bytesPerSecond,
+		start:          time.Now(),
+		done:           done,
+	}, nil
+}
+
+// throttledWriter writes to the underlying pipe at a limited rate
+type throttledWriter struct {
+	w              *io.PipeWriter
+	bytesPerSecond int64
+	start          time.Time
+	written        int64
+	done           chan error
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	// Write in chunks of at most a tenth of a second worth of data to keep
+	// the transfer smooth
+	chunk := max(tw.bytesPerSecond/10, 1)
+	total := 0
+	for len(p) > 0 {
+		n := int(min(int64(len(p)), chunk))
+		written, err := tw.w.Write(p[:n])
+		total += written
+		tw.written += int64(written)
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+
+		expected := time.Duration(float64(tw.written) / float64(tw.bytesPerSecond) * float64(time.Second))
+		if wait := expected - time.Since(tw.start); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+	return total, nil
+}
+
+// Close finishes the packfile stream and waits for it to be stored
+func (tw *throttledWriter) Close() error {
+	if err := tw.w.Close(); err != nil {
+		return err
+	}
+	return <-tw.done
+}
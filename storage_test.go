@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeLimits(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"small.txt": "hello",
+		"large.txt": strings.Repeat("x", 4096),
+	})
+	locator := fileLocator(repoDir, commitHash, "small.txt")
+
+	for _, tc := range []struct {
+		name  string
+		opts  []fnOpt
+		limit string
+	}{
+		{"no-limits", nil, ""},
+		{"within-limits", []fnOpt{WithMaxFileSize(8192), WithMaxRepoSize(1 << 20)}, ""},
+		{"file-too-large", []fnOpt{WithMaxFileSize(1024)}, SizeLimitFile},
+		{"repo-too-large", []fnOpt{WithMaxRepoSize(2048)}, SizeLimitRepository},
+		{"throttled-too-large", []fnOpt{WithMaxFileSize(1024), WithBandwidthLimit(1 << 30)}, SizeLimitFile},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := CopyFile(locator, io.Discard, append(tc.opts, noAuth)...)
+			if tc.limit == "" {
+				require.NoError(t, err)
+				return
+			}
+			var sle *SizeLimitError
+			require.True(t, errors.As(err, &sle), err)
+			require.Equal(t, tc.limit, sle.Kind)
+		})
+	}
+
+	t.Run("negative", func(t *testing.T) {
+		t.Parallel()
+		opts := defaultOptions
+		require.Error(t, WithMaxFileSize(-1)(&opts))
+		require.Error(t, WithMaxRepoSize(-1)(&opts))
+	})
+}
+
+func TestBandwidthLimit(t *testing.T) {
+	t.Parallel()
+
+	// Random data does not compress, the packfile is about the file size
+	data := make([]byte, 64*1024)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{"random.bin": string(data)})
+
+	var buf bytes.Buffer
+	start := time.Now()
+	err = CopyFile(
+		fileLocator(repoDir, commitHash, "random.bin"), &buf,
+		WithSystemCredentials(false), WithBandwidthLimit(256*1024),
+	)
+	require.NoError(t, err)
+	require.Equal(t, data, buf.Bytes())
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	opts := defaultOptions
+	require.Error(t, WithBandwidthLimit(-1)(&opts))
+}
+
+func TestDiskStorage(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"README.md":     "readme",
+		"docs/guide.md": "guide",
+	})
+
+	t.Run("temporary", func(t *testing.T) {
+		t.Parallel()
+		storageDir := t.TempDir()
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(fileLocator(repoDir, commitHash, "docs/guide.md"), &buf, noAuth, WithDiskStorage(storageDir)))
+		require.Equal(t, "guide", buf.String())
+
+		// The storage is removed when done
+		entries, err := os.ReadDir(storageDir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		t.Parallel()
+		storageDir := t.TempDir()
+		r, err := GetReader(fileLocator(repoDir, commitHash, "README.md"), noAuth, WithDiskStorage(storageDir))
+		require.NoError(t, err)
+		entries, err := os.ReadDir(storageDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "readme", string(data))
+		require.NoError(t, r.Close())
+		entries, err = os.ReadDir(storageDir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("clone-path", func(t *testing.T) {
+		t.Parallel()
+		clonePath := t.TempDir()
+		fsys, err := CloneRepository(fileLocator(repoDir, commitHash, ""), noAuth, WithClonePath(clonePath), WithDiskStorage(""))
+		require.NoError(t, err)
+		data, err := fs.ReadFile(fsys, "docs/guide.md")
+		require.NoError(t, err)
+		require.Equal(t, "guide", string(data))
+
+		// The clone path is a regular repository
+		repo, err := git.PlainOpen(clonePath)
+		require.NoError(t, err)
+		head, err := repo.Head()
+		require.NoError(t, err)
+		require.Equal(t, commitHash, head.Hash().String())
+	})
+}
+
+func TestStorerFactory(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{"README.md": "readme"})
+
+	var mu sync.Mutex
+	storers := map[string]*memory.Storage{}
+	factory := func(c *Components) (storage.Storer, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		st := memory.NewStorage()
+		storers[c.RepoPath] = st
+		return st, nil
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, CopyFile(fileLocator(repoDir, commitHash, "README.md"), &buf, noAuth, WithStorerFactory(factory)))
+	require.Equal(t, "readme", buf.String())
+
+	// The objects were written to the supplied storer
+	require.Len(t, storers, 1)
+	st, ok := storers[filepath.ToSlash(repoDir)]
+	require.True(t, ok)
+	require.NoError(t, st.HasEncodedObject(plumbing.NewHash(commitHash)))
+
+	failing := func(*Components) (storage.Storer, error) { return nil, errors.New("no storage") }
+	err := CopyFile(fileLocator(repoDir, commitHash, "README.md"), &buf, noAuth, WithStorerFactory(failing))
+	require.ErrorContains(t, err, "no storage")
+}
+
+func TestMemoryBudget(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+	repoDir, commitHash := initTestRepoWithFiles(t, map[string]string{
+		"README.md": "readme",
+		"large.txt": strings.Repeat("x", 4096),
+	})
+
+	for _, tc := range []struct {
+		name    string
+		ref     string
+		budget  int64
+		spilled bool
+	}{
+		{"in-memory", commitHash, 1 << 20, false},
+		{"spilled-commit", commitHash, 1024, true},
+		{"spilled-branch", "refs/heads/master", 1024, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			storageDir := t.TempDir()
+			opts := defaultOptions
+			require.NoError(t, noAuth(&opts))
+			require.NoError(t, WithMemoryBudget(tc.budget)(&opts))
+			require.NoError(t, WithStorageDir(storageDir)(&opts))
+
+			cloned, err := cloneRepo(Locator(fileLocator(repoDir, tc.ref, "")), &opts)
+			require.NoError(t, err)
+			require.Equal(t, commitHash, cloned.Commit)
+
+			data, err := util.ReadFile(cloned.FS, "large.txt")
+			require.NoError(t, err)
+			require.Len(t, data, 4096)
+
+			spill := findSpillStorer(cloned.Repo.Storer)
+			require.NotNil(t, spill)
+			if !tc.spilled {
+				require.Empty(t, spill.dir)
+				require.NoError(t, cloned.Close())
+				return
+			}
+
+			// The repository and its worktree are on disk until closed
+			require.Equal(t, storageDir, filepath.Dir(spill.dir))
+			_, err = os.Stat(filepath.Join(spill.dir, git.GitDirName))
+			require.NoError(t, err)
+			_, err = os.Stat(filepath.Join(spill.dir, "large.txt"))
+			require.NoError(t, err)
+			require.NoError(t, cloned.Close())
+			_, err = os.Stat(spill.dir)
+			require.ErrorIs(t, err, os.ErrNotExist)
+		})
+	}
+
+	opts := defaultOptions
+	require.Error(t, WithMemoryBudget(-1)(&opts))
+}
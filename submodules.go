@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// checkoutSubmodules clones the submodules of a repository into its worktree
+// at the commits recorded in its tree, recursing into nested submodules up
+// to depth levels (zero for no limit). It returns the trees of the commits
+// checked out in the submodules keyed by their path in the worktree.
+func checkoutSubmodules(repo *git.Repository, parent *Components, opts *options, depth int) (map[string]*object.Tree, error) {
+	levels := depth
+	if depth == 0 {
+		levels = int(git.DefaultSubmoduleRecursionDepth) + 1
+	}
+	if err := updateSubmodules(repo, parent, opts, levels); err != nil {
+		return nil, err
+	}
+
+	trees := map[string]*object.Tree{}
+	if err := submoduleTrees(repo, "", trees); err != nil {
+		return nil, err
+	}
+	return trees, nil
+}
+
+// updateSubmodules clones the submodules of a repository and, while levels
+// remain, their nested submodules. The submodule URLs are chosen by the
+// repository, so each one goes through the preflight checks of the remotes
+// and gets the credentials of its own host.
+func updateSubmodules(repo *git.Repository, parent *Components, opts *options, levels int) error {
+	if levels <= 0 {
+		return nil
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("getting repository worktree: %w", err)
+	}
+	subs, err := wt.Submodules()
+	if err != nil {
+		return fmt.Errorf("reading submodules: %w", err)
+	}
+
+	for _, sub := range subs {
+		subURL, err := resolveSubmoduleURL(sub.Config().URL, parent.fetchURL())
+		if err != nil {
+			return fmt.Errorf("resolving URL of submodule %q: %w", sub.Config().Path, err)
+		}
+		l, components, err := submoduleComponents(subURL)
+		if err != nil {
+			return fmt.Errorf("parsing URL of submodule %q: %w", sub.Config().Path, err)
+		}
+		subOpts := submoduleOptions(opts, parent, components)
+		auth, err := prepareRemote(l, components, &subOpts)
+		if err != nil {
+			return err
+		}
+
+		if err := sub.Update(&git.SubmoduleUpdateOptions{
+			Init:              true,
+			RecurseSubmodules: git.NoRecurseSubmodules,
+			Auth:              auth,
+		}); err != nil {
+			return fmt.Errorf("updating submodule %q: %w", sub.Config().Path, err)
+		}
+
+		subrepo, err := sub.Repository()
+		if err != nil {
+			return fmt.Errorf("opening submodule %q: %w", sub.Config().Path, err)
+		}
+		if err := updateSubmodules(subrepo, components, opts, levels-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// submoduleComponents returns the locator and components of the
+// repository at a submodule URL
+func submoduleComponents(subURL string) (Locator, *Components, error) {
+	locator, err := remoteURLToLocator(subURL, "")
+	if err != nil {
+		return "", nil, err
+	}
+	l := Locator(strings.TrimSuffix(locator, "@"))
+	components, err := l.Parse()
+	if err != nil {
+		return "", nil, err
+	}
+	return l, components, nil
+}
+
+// submoduleOptions returns the options to fetch a submodule. The
+// credentials set for the parent remote are only kept when the submodule
+// lives in the same host.
+func submoduleOptions(opts *options, parent, sub *Components) options {
+	o := *opts
+	if !strings.EqualFold(parent.Hostname, sub.Hostname) {
+		o.AuthMethod = nil
+		o.TokenSource = nil
+		o.HttpUsername, o.HttpPassword = "", ""
+	}
+	return o
+}
+
+// submoduleTrees collects the trees of the commits checked out in the
+// submodules of a repository, keyed by their path under prefix.
+func submoduleTrees(repo *git.Repository, prefix string, trees map[string]*object.Tree) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("getting repository worktree: %w", err)
+	}
+	subs, err := wt.Submodules()
+	if err != nil {
+		return fmt.Errorf("reading submodules: %w", err)
+	}
+
+	for _, sub := range subs {
+		p := path.Join(prefix, sub.Config().Path)
+		subrepo, err := sub.Repository()
+		if errors.Is(err, git.ErrSubmoduleNotInitialized) {
+			// Nested deeper than the requested depth
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("opening submodule %q: %w", p, err)
+		}
+		head, err := subrepo.Head()
+		if err != nil {
+			return fmt.Errorf("reading HEAD of submodule %q: %w", p, err)
+		}
+		commit, err := subrepo.CommitObject(head.Hash())
+		if err != nil {
+			return fmt.Errorf("reading commit of submodule %q: %w", p, err)
+		}
+		if trees[p], err = commit.Tree(); err != nil {
+			return fmt.Errorf("reading tree of submodule %q: %w", p, err)
+		}
+		if err := submoduleTrees(subrepo, p, trees); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repoTree looks up entries in the tree of a commit and in the trees of the
+// submodules checked out in its worktree.
+type repoTree struct {
+	*object.Tree
+	submodules map[string]*object.Tree
+}
+
+// FindEntry returns the tree entry of a path, which may be in a submodule
+func (t *repoTree) FindEntry(p string) (*object.TreeEntry, error) {
+	// Nested submodules are matched by the longest path
+	sub := ""
+	for sp := range t.submodules {
+		if strings.HasPrefix(p, sp+"/") && len(sp) > len(sub) {
+			sub = sp
+		}
+	}
+	if sub != "" {
+		return t.submodules[sub].FindEntry(strings.TrimPrefix(p, sub+"/"))
+	}
+	return t.Tree.FindEntry(p)
+}
+
+// submoduleBoundaryError is returned when a path looked up in a tree
+// continues inside a submodule, whose contents are in another repository.
+type submoduleBoundaryError struct {
+	// Path is the path of the submodule in the tree
+	Path string
+
+	// Commit is the submodule commit recorded in the tree
+	Commit plumbing.Hash
+
+	// Rest is the path inside the submodule
+	Rest string
+}
+
+func (e *submoduleBoundaryError) Error() string {
+	return fmt.Sprintf("%q is in submodule %q", path.Join(e.Path, e.Rest), e.Path)
+}
+
+// openSubmoduleFile opens a file in a submodule by cloning the submodule
+// repository at the commit pinned in the parent tree.
+func openSubmoduleFile(cloned *clonedRepo, tree *object.Tree, sbe *submoduleBoundaryError) (io.ReadCloser, error) {
+	subURL, err := submoduleURL(tree, sbe.Path, cloned.Components.fetchURL())
+	if err != nil {
+		return nil, err
+	}
+	locator, err := remoteURLToLocator(subURL, sbe.Commit.String())
+	if err != nil {
+		return nil, fmt.Errorf("building locator of submodule %q: %w", sbe.Path, err)
+	}
+
+	subLocator := Locator(locator + "#" + sbe.Rest)
+	components, err := subLocator.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("parsing locator of submodule %q: %w", sbe.Path, err)
+	}
+	opts := submoduleOptions(&cloned.opts, cloned.Components, components)
+	opts.ClonePath = ""
+	opts.refreshStorer = nil
+	opts.noCheckout = true
+	sub, err := cloneRepo(subLocator, &opts, cloned.funcs...)
+	if err != nil {
+		return nil, fmt.Errorf("cloning submodule %q: %w", sbe.Path, err)
+	}
+	f, err := openRepoFile(sub, sbe.Rest)
+	if err != nil {
+		sub.Close() //nolint:errcheck,gosec
+		return nil, err
+	}
+	return &closingReader{ReadCloser: f, closer: sub}, nil
+}
+
+// submoduleURL reads the URL of the submodule at subPath from the
+// .gitmodules file in the tree. Relative URLs are resolved against the URL
+// of the parent repository, just as git does.
+func submoduleURL(tree *object.Tree, subPath, parentURL string) (string, error) {
+	f, err := tree.File(".gitmodules")
+	if err != nil {
+		return "", fmt.Errorf("reading .gitmodules: %w", err)
+	}
+	data, err := f.Contents()
+	if err != nil {
+		return "", fmt.Errorf("reading .gitmodules: %w", err)
+	}
+	modules := config.NewModules()
+	if err := modules.Unmarshal([]byte(data)); err != nil {
+		return "", fmt.Errorf("parsing .gitmodules: %w", err)
+	}
+
+	for _, m := range modules.Submodules {
+		if m.Path == subPath {
+			return resolveSubmoduleURL(m.URL, parentURL)
+		}
+	}
+	return "", fmt.Errorf("submodule %q not found in .gitmodules", subPath)
+}
+
+// resolveSubmoduleURL resolves relative submodule URLs against the URL of
+// the parent repository
+func resolveSubmoduleURL(subURL, parentURL string) (string, error) {
+	if !strings.HasPrefix(subURL, "./") && !strings.HasPrefix(subURL, "../") {
+		return subURL, nil
+	}
+	u, err := url.Parse(parentURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing repository URL: %w", err)
+	}
+	u.Path = path.Join(u.Path, subURL)
+	return u.String(), nil
+}
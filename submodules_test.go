@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+// commitTestSubmodule records a submodule at subPath in the test repository
+// pointing to a commit of the repository in subDir.
+func commitTestSubmodule(t *testing.T, repoDir, subPath, subDir, commit string) string {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	gitmodules := filepath.Join(repoDir, ".gitmodules")
+	f, err := os.OpenFile(gitmodules, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(f, "[submodule %q]\n\tpath = %s\n\turl = %s\n", subPath, subPath, subDir)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = wt.Add(".gitmodules")
+	require.NoError(t, err)
+
+	idx, err := repo.Storer.Index()
+	require.NoError(t, err)
+	idx.Entries = append(idx.Entries, &index.Entry{
+		Name: subPath,
+		Hash: plumbing.NewHash(commit),
+		Mode: filemode.Submodule,
+	})
+	require.NoError(t, repo.Storer.SetIndex(idx))
+
+	hash, err := wt.Commit("add submodule "+subPath, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+func TestSubmodules(t *testing.T) {
+	t.Parallel()
+	noAuth := WithSystemCredentials(false)
+
+	nestedDir, nestedCommit := initTestRepoWithFiles(t, map[string]string{"deep.txt": "deep"})
+	libDir, _ := initTestRepoWithFiles(t, map[string]string{"lib.go": "package lib"})
+	libCommit := commitTestSubmodule(t, libDir, "nested", nestedDir, nestedCommit)
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{"README.md": "readme"})
+	commitTestSubmodule(t, repoDir, "vendor/lib", libDir, libCommit)
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		fsys, err := OpenFS(fileLocator(repoDir, "refs/heads/master", ""), noAuth)
+		require.NoError(t, err)
+		_, err = fs.Stat(fsys, "vendor/lib/lib.go")
+		require.Error(t, err)
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		t.Parallel()
+		fsys, err := OpenFS(fileLocator(repoDir, "refs/heads/master", "vendor"), noAuth, WithSubmodules(true, 0))
+		require.NoError(t, err)
+		data, err := fs.ReadFile(fsys, "lib/lib.go")
+		require.NoError(t, err)
+		require.Equal(t, "package lib", string(data))
+		data, err = fs.ReadFile(fsys, "lib/nested/deep.txt")
+		require.NoError(t, err)
+		require.Equal(t, "deep", string(data))
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		t.Parallel()
+		fsys, err := OpenFS(fileLocator(repoDir, "refs/heads/master", ""), noAuth, WithSubmodules(true, 1))
+		require.NoError(t, err)
+		_, err = fs.Stat(fsys, "vendor/lib/lib.go")
+		require.NoError(t, err)
+		_, err = fs.Stat(fsys, "vendor/lib/nested/deep.txt")
+		require.Error(t, err)
+	})
+
+	t.Run("download", func(t *testing.T) {
+		t.Parallel()
+		dest := filepath.Join(t.TempDir(), "out")
+		require.NoError(t, Download(fileLocator(repoDir, "refs/heads/master", ""), dest, noAuth, WithSubmodules(true, 0)))
+		data, err := os.ReadFile(filepath.Join(dest, "vendor", "lib", "nested", "deep.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "deep", string(data))
+	})
+
+	_, err := OpenFS(fileLocator(repoDir, "", ""), WithSubmodules(true, -1))
+	require.Error(t, err)
+}
+
+func TestSubmoduleFiles(t *testing.T) {
+	t.Parallel()
+	noAuth := WithSystemCredentials(false)
+
+	nestedDir, nestedCommit := initTestRepoWithFiles(t, map[string]string{"deep.txt": "deep"})
+	libDir, _ := initTestRepoWithFiles(t, map[string]string{"lib.go": "package lib"})
+	libCommit := commitTestSubmodule(t, libDir, "nested", nestedDir, nestedCommit)
+	// Newer commits in the submodule are not read
+	commitTestFile(t, libDir, "lib.go", "package lib // v2")
+
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{"README.md": "readme"})
+	commitTestSubmodule(t, repoDir, "vendor/lib", libDir, libCommit)
+
+	// Relative URLs are resolved against the parent repository URL
+	require.Equal(t, filepath.Dir(repoDir), filepath.Dir(libDir))
+	relDir, _ := initTestRepoWithFiles(t, map[string]string{"README.md": "readme"})
+	commitTestSubmodule(t, relDir, "lib", "../"+filepath.Base(libDir), libCommit)
+
+	for _, tc := range []struct {
+		name     string
+		repoDir  string
+		fragment string
+		expected string
+	}{
+		{"file", repoDir, "vendor/lib/lib.go", "package lib"},
+		{"nested", repoDir, "vendor/lib/nested/deep.txt", "deep"},
+		{"relative-url", relDir, "lib/lib.go", "package lib"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, CopyFile(fileLocator(tc.repoDir, "refs/heads/master", tc.fragment), &buf, noAuth))
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("submodule-dir", func(t *testing.T) {
+		t.Parallel()
+		err := CopyFile(fileLocator(repoDir, "refs/heads/master", "vendor/lib"), &bytes.Buffer{}, noAuth)
+		require.Error(t, err)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		err := CopyFile(fileLocator(repoDir, "refs/heads/master", "vendor/lib/nope.go"), &bytes.Buffer{}, noAuth)
+		require.Error(t, err)
+	})
+}
+
+func TestSubmodulePolicy(t *testing.T) {
+	t.Parallel()
+	libDir, libCommit := initTestRepoWithFiles(t, map[string]string{"lib.go": "package lib"})
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{"README.md": "readme"})
+	commitTestSubmodule(t, repoDir, "vendor/lib", libDir, libCommit)
+	commitTestSubmodule(t, repoDir, "vendor/remote", "https://blocked.example.com/org/repo", libCommit)
+
+	// Submodules are checked against the policies like any other remote
+	_, err := OpenFS(
+		fileLocator(repoDir, "refs/heads/master", ""), WithSystemCredentials(false),
+		WithSubmodules(true, 0), WithBlockedHosts("blocked.example.com"),
+	)
+	var pve *PolicyViolationError
+	require.True(t, errors.As(err, &pve), "unexpected error: %v", err)
+	require.Equal(t, "blocked.example.com", pve.Hostname)
+}
+
+func TestSubmoduleOptions(t *testing.T) {
+	t.Parallel()
+	opts := defaultOptions
+	for _, fn := range []fnOpt{
+		WithHttpAuth("user", "pass"),
+		WithAuthMethod(&githttp.TokenAuth{Token: "secret"}),
+		WithTokenSource(func(context.Context) (string, error) { return "token", nil }),
+	} {
+		require.NoError(t, fn(&opts))
+	}
+	parent := &Components{Hostname: "git.example.com"}
+
+	same := submoduleOptions(&opts, parent, &Components{Hostname: "GIT.example.com"})
+	require.NotNil(t, same.AuthMethod)
+	require.NotNil(t, same.TokenSource)
+	require.Equal(t, "pass", same.HttpPassword)
+
+	// The credentials of the parent are not sent to other hosts
+	other := submoduleOptions(&opts, parent, &Components{Hostname: "other.example.com"})
+	require.Nil(t, other.AuthMethod)
+	require.Nil(t, other.TokenSource)
+	require.Empty(t, other.HttpUsername)
+	require.Empty(t, other.HttpPassword)
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+)
+
+// Policies to handle symbolic links when downloading files
+const (
+	// SymlinksSkip does not write symbolic links (default)
+	SymlinksSkip = "skip"
+
+	// SymlinksReject fails the download when a symbolic link is found
+	SymlinksReject = "reject"
+
+	// SymlinksResolve writes the contents of the file a link points to.
+	// Links resolving outside of the repository or to directories fail.
+	SymlinksResolve = "resolve"
+
+	// SymlinksPreserve recreates relative links that stay within the
+	// destination directory. Any other link fails.
+	SymlinksPreserve = "preserve"
+)
+
+// maxSymlinkHops is the maximum number of links followed to resolve a path
+const maxSymlinkHops = 40
+
+// errSymlinkEscapes is returned when a link resolves outside the repository
+var errSymlinkEscapes = errors.New("symbolic link resolves outside of the repository")
+
+// resolveRepoSymlink resolves all the symbolic links in a path of the
+// repository worktree, returning the path of the final target. It fails
+// if any link (or ..) leads out of the repository.
+func resolveRepoSymlink(fsys billy.Filesystem, p string) (string, error) {
+	resolved := ""
+	parts := strings.Split(p, "/")
+	hops := 0
+	for len(parts) > 0 {
+		part := parts[0]
+		parts = parts[1:]
+
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if resolved == "" {
+				return "", fmt.Errorf("resolving %q: %w", p, errSymlinkEscapes)
+			}
+			resolved = strings.TrimPrefix(path.Dir(resolved), ".")
+			continue
+		}
+
+		next := path.Join(resolved, part)
+		info, err := fsys.Lstat(next)
+		if err != nil {
+			return "", fmt.Errorf("resolving %q: %w", p, err)
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		hops++
+		if hops > maxSymlinkHops {
+			return "", fmt.Errorf("resolving %q: too many levels of symbolic links", p)
+		}
+		link, err := fsys.Readlink(next)
+		if err != nil {
+			return "", fmt.Errorf("reading link %q: %w", next, err)
+		}
+		if path.IsAbs(link) || filepath.IsAbs(link) {
+			return "", fmt.Errorf("resolving %q: %w", p, errSymlinkEscapes)
+		}
+		parts = append(strings.Split(link, "/"), parts...)
+	}
+	return resolved, nil
+}
+
+// isWithin returns true if p is root or a path under it
+func isWithin(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// prepareDestination returns the path where a repository file is written
+// under root, creating its parent directories. It fails if the path leads
+// out of root (see destinationPath) or if the destination itself is a
+// symbolic link.
+func prepareDestination(root, p string) (string, error) {
+	dest, err := destinationPath(root, p)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Lstat(dest); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("destination %q is a symbolic link", dest)
+	}
+	return dest, nil
+}
+
+// destinationPath returns the path of p under root, creating its parent
+// directories. It fails if the path, or any link in its parent directories,
+// leads out of root. The root must be an absolute path without symbolic links.
+func destinationPath(root, p string) (string, error) {
+	dest := filepath.Join(root, filepath.FromSlash(p))
+	if !isWithin(root, dest) {
+		return "", fmt.Errorf("path %q escapes the destination directory", p)
+	}
+
+	dir := filepath.Dir(dest)
+	if err := os.MkdirAll(dir, os.FileMode(0o755)); err != nil {
+		return "", fmt.Errorf("creating destination dir: %w", err)
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("resolving destination dir: %w", err)
+	}
+	if !isWithin(root, realDir) {
+		return "", fmt.Errorf("destination of %q escapes the destination directory", p)
+	}
+	return dest, nil
+}
+
+// preserveSymlink recreates the link at p in the destination if it is
+// relative and stays within the destination root.
+func preserveSymlink(fsys billy.Filesystem, root, p, dest string) error {
+	link, err := fsys.Readlink(p)
+	if err != nil {
+		return fmt.Errorf("reading link %q: %w", p, err)
+	}
+	if path.IsAbs(link) || filepath.IsAbs(link) {
+		return fmt.Errorf("symbolic link %q has an absolute target", p)
+	}
+	target := filepath.Join(filepath.Dir(dest), filepath.FromSlash(link))
+	if !isWithin(root, target) {
+		return fmt.Errorf("symbolic link %q points outside of the destination directory", p)
+	}
+	if err := os.Symlink(link, dest); err != nil {
+		return fmt.Errorf("creating symbolic link: %w", err)
+	}
+	return nil
+}
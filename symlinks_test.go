@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// commitTestSymlinks adds symbolic links to the test repository and commits
+// them, returning the new commit hash. The map is keyed by link path.
+func commitTestSymlinks(t *testing.T, repoDir string, links map[string]string) string {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	for relPath, target := range links {
+		abs := filepath.Join(repoDir, relPath)
+		require.NoError(t, os.MkdirAll(filepath.Dir(abs), 0o750))
+		require.NoError(t, os.Symlink(target, abs))
+		_, err := wt.Add(relPath)
+		require.NoError(t, err)
+	}
+
+	hash, err := wt.Commit("add links", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+func TestDownloadSymlinks(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{
+		"data/hello.txt":  "hello",
+		"other/world.txt": "world",
+	})
+	commit := commitTestSymlinks(t, repoDir, map[string]string{
+		"data/inside.txt": "../other/world.txt",
+		"data/dir":        "../other",
+	})
+
+	escapeDir, _ := initTestRepoWithFiles(t, map[string]string{"data/hello.txt": "hello"})
+	escapeCommit := commitTestSymlinks(t, escapeDir, map[string]string{
+		"data/passwd": "/etc/passwd",
+		"data/up":     "../../../../outside.txt",
+	})
+
+	for _, tc := range []struct {
+		name    string
+		repo    string
+		commit  string
+		subpath string
+		policy  string
+		mustErr bool
+		check   func(t *testing.T, dir string)
+	}{
+		{
+			name: "skip", repo: repoDir, commit: commit, subpath: "data", policy: SymlinksSkip,
+			check: func(t *testing.T, dir string) {
+				t.Helper()
+				require.FileExists(t, filepath.Join(dir, "data", "hello.txt"))
+				require.NoFileExists(t, filepath.Join(dir, "data", "inside.txt"))
+				require.NoFileExists(t, filepath.Join(dir, "data", "dir"))
+			},
+		},
+		{name: "reject", repo: repoDir, commit: commit, subpath: "data", policy: SymlinksReject, mustErr: true},
+		{
+			name: "resolve file", repo: repoDir, commit: commit, subpath: "data/inside.txt", policy: SymlinksResolve,
+			check: func(t *testing.T, dir string) {
+				t.Helper()
+				info, err := os.Lstat(filepath.Join(dir, "data", "inside.txt"))
+				require.NoError(t, err)
+				require.True(t, info.Mode().IsRegular())
+				data, err := os.ReadFile(filepath.Join(dir, "data", "inside.txt"))
+				require.NoError(t, err)
+				require.Equal(t, "world", string(data))
+			},
+		},
+		{name: "resolve dir", repo: repoDir, commit: commit, subpath: "data/dir", policy: SymlinksResolve, mustErr: true},
+		{name: "resolve absolute", repo: escapeDir, commit: escapeCommit, subpath: "data/passwd", policy: SymlinksResolve, mustErr: true},
+		{name: "resolve escaping", repo: escapeDir, commit: escapeCommit, subpath: "data/up", policy: SymlinksResolve, mustErr: true},
+		{
+			name: "preserve within", repo: repoDir, commit: commit, subpath: "data", policy: SymlinksPreserve,
+			check: func(t *testing.T, dir string) {
+				t.Helper()
+				link, err := os.Readlink(filepath.Join(dir, "data", "inside.txt"))
+				require.NoError(t, err)
+				require.Equal(t, "../other/world.txt", link)
+			},
+		},
+		{name: "preserve absolute", repo: escapeDir, commit: escapeCommit, subpath: "data/passwd", policy: SymlinksPreserve, mustErr: true},
+		{name: "preserve escaping", repo: escapeDir, commit: escapeCommit, subpath: "data/up", policy: SymlinksPreserve, mustErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			dir := filepath.Join(t.TempDir(), "dest")
+			err := Download(
+				fileLocator(tc.repo, tc.commit, tc.subpath), dir,
+				noAuth, WithSymlinks(tc.policy),
+			)
+			if tc.mustErr {
+				require.Error(t, err)
+				require.NoFileExists(t, filepath.Join(filepath.Dir(dir), "outside.txt"))
+				return
+			}
+			require.NoError(t, err)
+			if tc.check != nil {
+				tc.check(t, dir)
+			}
+		})
+	}
+}
+
+func TestDownloadDestinationSymlink(t *testing.T) {
+	t.Parallel()
+
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		"data/hello.txt": "hello",
+	})
+
+	// A link in the destination must not redirect writes out of it
+	base := t.TempDir()
+	dest := filepath.Join(base, "dest")
+	outside := filepath.Join(base, "outside")
+	require.NoError(t, os.MkdirAll(dest, 0o750))
+	require.NoError(t, os.MkdirAll(outside, 0o750))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dest, "data")))
+
+	err := Download(fileLocator(repoDir, commit, "data"), dest, WithSystemCredentials(false))
+	require.Error(t, err)
+	require.NoFileExists(t, filepath.Join(outside, "hello.txt"))
+}
+
+func TestResolveRepoSymlinkLoop(t *testing.T) {
+	t.Parallel()
+
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{"a.txt": "a"})
+	commit := commitTestSymlinks(t, repoDir, map[string]string{
+		"loop1": "loop2",
+		"loop2": "loop1",
+	})
+	err := Download(
+		fileLocator(repoDir, commit, "loop1"), t.TempDir(),
+		WithSystemCredentials(false), WithSymlinks(SymlinksResolve),
+	)
+	require.Error(t, err)
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// removeStaleFiles deletes the files under the subtree of root that are not
+// in the keep set, along with the directories left empty. Paths in keep are
+// relative to root. Staging directories of in-progress downloads and git
+// directories are preserved.
+func removeStaleFiles(root, subtree string, keep map[string]struct{}) error {
+	top := filepath.Join(root, subtree)
+	if !isWithin(root, top) {
+		return fmt.Errorf("subtree %q escapes the destination directory", subtree)
+	}
+
+	info, err := os.Lstat(top)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+
+	dirs := []string{}
+	if err := filepath.WalkDir(top, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), stagingPrefix) || d.Name() == gitDirName {
+				return filepath.SkipDir
+			}
+			if path != top {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if _, ok := keep[rel]; ok {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("removing %q: %w", rel, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Remove empty directories, deepest first
+	slices.Reverse(dirs)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		if err := os.Remove(dir); err != nil {
+			return fmt.Errorf("removing directory: %w", err)
+		}
+	}
+	return nil
+}
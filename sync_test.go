@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadSync(t *testing.T) {
+	t.Parallel()
+
+	noAuth := WithSystemCredentials(false)
+
+	repoDir, commit := initTestRepoWithFiles(t, map[string]string{
+		"data/a.txt":     "a",
+		"data/sub/b.txt": "b",
+		"other/c.txt":    "c",
+	})
+
+	prepare := func(t *testing.T) string {
+		t.Helper()
+		dest := t.TempDir()
+		for _, f := range []string{"data/stale.txt", "data/old/deep/x.txt", "data/sub/y.txt", "unrelated.txt"} {
+			p := filepath.Join(dest, filepath.FromSlash(f))
+			require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+			require.NoError(t, os.WriteFile(p, []byte("stale"), 0o600))
+		}
+		return dest
+	}
+
+	t.Run("sync", func(t *testing.T) {
+		t.Parallel()
+		dest := prepare(t)
+		require.NoError(t, Download(fileLocator(repoDir, commit, "data"), dest, noAuth, WithSync(true)))
+
+		require.FileExists(t, filepath.Join(dest, "data", "a.txt"))
+		require.FileExists(t, filepath.Join(dest, "data", "sub", "b.txt"))
+		require.FileExists(t, filepath.Join(dest, "unrelated.txt"))
+		require.NoFileExists(t, filepath.Join(dest, "data", "stale.txt"))
+		require.NoFileExists(t, filepath.Join(dest, "data", "sub", "y.txt"))
+		require.NoDirExists(t, filepath.Join(dest, "data", "old"))
+	})
+
+	t.Run("no-sync", func(t *testing.T) {
+		t.Parallel()
+		dest := prepare(t)
+		require.NoError(t, Download(fileLocator(repoDir, commit, "data"), dest, noAuth))
+
+		require.FileExists(t, filepath.Join(dest, "data", "a.txt"))
+		require.FileExists(t, filepath.Join(dest, "data", "stale.txt"))
+		require.FileExists(t, filepath.Join(dest, "data", "old", "deep", "x.txt"))
+	})
+
+	t.Run("whole-repo", func(t *testing.T) {
+		t.Parallel()
+		dest := prepare(t)
+		require.NoError(t, Download(fileLocator(repoDir, commit, ""), dest, noAuth, WithSync(true)))
+		require.FileExists(t, filepath.Join(dest, "other", "c.txt"))
+		require.NoFileExists(t, filepath.Join(dest, "unrelated.txt"))
+		require.NoFileExists(t, filepath.Join(dest, "data", "stale.txt"))
+	})
+
+	t.Run("file-subpath", func(t *testing.T) {
+		t.Parallel()
+		dest := prepare(t)
+		require.NoError(t, Download(fileLocator(repoDir, commit, "data/a.txt"), dest, noAuth, WithSync(true)))
+		require.FileExists(t, filepath.Join(dest, "data", "a.txt"))
+		require.FileExists(t, filepath.Join(dest, "data", "stale.txt"))
+	})
+}
@@ -0,0 +1,279 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// treeFS is a read-only billy filesystem serving the files of a git tree
+// straight from the object store, used to read repositories without a
+// worktree to check them out to. Files are loaded in memory when opened.
+type treeFS struct {
+	st   storer.EncodedObjectStorer
+	tree *object.Tree
+}
+
+// newTreeFS returns a filesystem with the files of the tree of a commit
+func newTreeFS(st storer.EncodedObjectStorer, commit *object.Commit) (*treeFS, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return &treeFS{st: st, tree: tree}, nil
+}
+
+// treePath converts a filesystem path to a path in the tree
+func treePath(name string) string {
+	p := path.Clean("/" + filepath.ToSlash(name))
+	return strings.TrimPrefix(p, "/")
+}
+
+// treeFileInfo describes a tree entry as a file
+type treeFileInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+}
+
+func (fi *treeFileInfo) Name() string       { return fi.name }
+func (fi *treeFileInfo) Size() int64        { return fi.size }
+func (fi *treeFileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi *treeFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi *treeFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *treeFileInfo) Sys() any           { return nil }
+
+// info returns the file info of a tree entry. Submodules are reported as
+// empty directories, as they are in fresh checkouts.
+func (t *treeFS) info(name string, entry *object.TreeEntry) (os.FileInfo, error) {
+	fi := &treeFileInfo{name: path.Base(name)}
+	if name == "" {
+		fi.name = "/"
+	}
+	switch entry.Mode {
+	case filemode.Dir, filemode.Submodule:
+		fi.mode = os.ModeDir | 0o755
+		return fi, nil
+	case filemode.Symlink:
+		fi.mode = os.ModeSymlink | 0o777
+	default:
+		fi.mode = entryPerm(entry.Mode)
+	}
+	size, err := t.st.EncodedObjectSize(entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	fi.size = size
+	return fi, nil
+}
+
+// stat looks up a path following the symbolic links in it
+func (t *treeFS) stat(op, name string) (string, *object.TreeEntry, error) {
+	p := treePath(name)
+	entry, err := resolveTreeSymlink(t.tree, p)
+	var sbe *submoduleBoundaryError
+	if errors.As(err, &sbe) {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		return "", nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return p, entry, nil
+}
+
+// lstat looks up a path without following a symbolic link at its end
+func (t *treeFS) lstat(op, name string) (string, *object.TreeEntry, error) {
+	p := treePath(name)
+	if p == "" {
+		return t.stat(op, name)
+	}
+	_, dir, err := t.stat(op, path.Dir(p))
+	if err != nil {
+		return "", nil, err
+	}
+	if dir.Mode == filemode.Dir {
+		tree, err := object.GetTree(t.st, dir.Hash)
+		if err != nil {
+			return "", nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		for i := range tree.Entries {
+			if tree.Entries[i].Name == path.Base(p) {
+				return p, &tree.Entries[i], nil
+			}
+		}
+	}
+	return "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// Stat returns the info of a file, following symbolic links
+func (t *treeFS) Stat(filename string) (os.FileInfo, error) {
+	p, entry, err := t.stat("stat", filename)
+	if err != nil {
+		return nil, err
+	}
+	return t.info(p, entry)
+}
+
+// Lstat returns the info of a file without following symbolic links
+func (t *treeFS) Lstat(filename string) (os.FileInfo, error) {
+	p, entry, err := t.lstat("lstat", filename)
+	if err != nil {
+		return nil, err
+	}
+	return t.info(p, entry)
+}
+
+// Readlink returns the target of a symbolic link
+func (t *treeFS) Readlink(link string) (string, error) {
+	_, entry, err := t.lstat("readlink", link)
+	if err != nil {
+		return "", err
+	}
+	if entry.Mode != filemode.Symlink {
+		return "", &fs.PathError{Op: "readlink", Path: link, Err: fs.ErrInvalid}
+	}
+	data, err := t.read(entry)
+	if err != nil {
+		return "", &fs.PathError{Op: "readlink", Path: link, Err: err}
+	}
+	return string(data), nil
+}
+
+// ReadDir lists the entries of a directory
+func (t *treeFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	p, entry, err := t.stat("readdir", dirname)
+	if err != nil {
+		return nil, err
+	}
+	switch entry.Mode {
+	case filemode.Submodule:
+		return []os.FileInfo{}, nil
+	case filemode.Dir:
+	default:
+		return nil, &fs.PathError{Op: "readdir", Path: dirname, Err: errors.New("not a directory")}
+	}
+
+	tree, err := object.GetTree(t.st, entry.Hash)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: dirname, Err: err}
+	}
+	infos := make([]os.FileInfo, 0, len(tree.Entries))
+	for i := range tree.Entries {
+		fi, err := t.info(path.Join(p, tree.Entries[i].Name), &tree.Entries[i])
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: dirname, Err: err}
+		}
+		infos = append(infos, fi)
+	}
+	return infos, nil
+}
+
+// Open opens a file for reading
+func (t *treeFS) Open(filename string) (billy.File, error) {
+	_, entry, err := t.stat("open", filename)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Mode == filemode.Dir || entry.Mode == filemode.Submodule {
+		return nil, &fs.PathError{Op: "open", Path: filename, Err: errors.New("is a directory")}
+	}
+	data, err := t.read(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: filename, Err: err}
+	}
+	return &treeFile{Reader: bytes.NewReader(data), name: filename}, nil
+}
+
+// OpenFile opens a file for reading, any other flag fails
+func (t *treeFS) OpenFile(filename string, flag int, _ os.FileMode) (billy.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0 {
+		return nil, billy.ErrReadOnly
+	}
+	return t.Open(filename)
+}
+
+// read returns the contents of the blob of a tree entry
+func (t *treeFS) read(entry *object.TreeEntry) ([]byte, error) {
+	blob, err := object.GetBlob(t.st, entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close() //nolint:errcheck
+	return io.ReadAll(r)
+}
+
+// Join joins path elements
+func (t *treeFS) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+// Root returns the root of the filesystem
+func (t *treeFS) Root() string {
+	return "/"
+}
+
+// Create fails, the filesystem is read-only
+func (t *treeFS) Create(string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+// Rename fails, the filesystem is read-only
+func (t *treeFS) Rename(string, string) error {
+	return billy.ErrReadOnly
+}
+
+// Remove fails, the filesystem is read-only
+func (t *treeFS) Remove(string) error {
+	return billy.ErrReadOnly
+}
+
+// TempFile fails, the filesystem is read-only
+func (t *treeFS) TempFile(string, string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+// MkdirAll fails, the filesystem is read-only
+func (t *treeFS) MkdirAll(string, os.FileMode) error {
+	return billy.ErrReadOnly
+}
+
+// Symlink fails, the filesystem is read-only
+func (t *treeFS) Symlink(string, string) error {
+	return billy.ErrReadOnly
+}
+
+// Chroot is not supported
+func (t *treeFS) Chroot(string) (billy.Filesystem, error) {
+	return nil, billy.ErrNotSupported
+}
+
+// treeFile is a file of a treeFS loaded in memory
+type treeFile struct {
+	*bytes.Reader
+	name string
+}
+
+func (f *treeFile) Name() string              { return f.name }
+func (f *treeFile) Write([]byte) (int, error) { return 0, billy.ErrReadOnly }
+func (f *treeFile) Truncate(int64) error      { return billy.ErrReadOnly }
+func (f *treeFile) Lock() error               { return nil }
+func (f *treeFile) Unlock() error             { return nil }
+func (f *treeFile) Close() error              { return nil }
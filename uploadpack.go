@@ -0,0 +1,364 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/sideband"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+// uploadPackSession is an open connection to the upload-pack service of a
+// remote along with the references and capabilities it advertised.
+type uploadPackSession struct {
+	transport.UploadPackSession
+	advRefs *packp.AdvRefs
+}
+
+// openUploadPack connects to the upload-pack service of the remote
+func openUploadPack(repourl string, auth transport.AuthMethod) (*uploadPackSession, error) {
+	ep, err := transport.NewEndpoint(repourl)
+	if err != nil {
+		return nil, fmt.Errorf("parsing remote URL: %w", err)
+	}
+	c, err := client.NewClient(ep)
+	if err != nil {
+		return nil, fmt.Errorf("creating transport client: %w", err)
+	}
+	sess, err := c.NewUploadPackSession(ep, auth)
+	if err != nil {
+		return nil, fmt.Errorf("opening upload-pack session: %w", err)
+	}
+	ar, err := sess.AdvertisedReferences()
+	if err != nil {
+		sess.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("reading advertised references: %w", err)
+	}
+	return &uploadPackSession{UploadPackSession: sess, advRefs: ar}, nil
+}
+
+// fetchPack requests objects from the remote and writes them to the storer.
+// A non-nil depth makes the fetch shallow, recording the shallow commits in
+// the storer. A filter requests a partial packfile. The progress messages
+// of the remote are written to progress, they are not requested if it is
+// nil.
+func (s *uploadPackSession) fetchPack(st storage.Storer, wants []plumbing.Hash, depth packp.Depth, filter packp.Filter, progress io.Writer) (err error) {
+	// Objects borrowed from a reference repository are not fetched again
+	missing := make([]plumbing.Hash, 0, len(wants))
+	for _, h := range wants {
+		if st.HasEncodedObject(h) != nil {
+			missing = append(missing, h)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	caps := s.advRefs.Capabilities
+	req := packp.NewUploadPackRequestFromCapabilities(caps)
+	req.Wants = missing
+	if ref := findReferenceStorer(st); ref != nil {
+		if req.Haves, err = ref.haves(); err != nil {
+			return err
+		}
+	}
+	if progress == nil && caps.Supports(capability.NoProgress) {
+		if err := req.Capabilities.Set(capability.NoProgress); err != nil {
+			return err
+		}
+	}
+	if depth != nil && !depth.IsZero() {
+		req.Depth = depth
+		if err := req.Capabilities.Set(capability.Shallow); err != nil {
+			return err
+		}
+		if _, ok := depth.(packp.DepthSince); ok {
+			if !caps.Supports(capability.DeepenSince) {
+				return errors.New("remote does not support shallow fetches by date")
+			}
+			if err := req.Capabilities.Set(capability.DeepenSince); err != nil {
+				return err
+			}
+		}
+	}
+	if filter != "" {
+		if !caps.Supports(capability.Filter) {
+			return errors.New("remote does not support partial clone filters")
+		}
+		req.Filter = filter
+		if err := req.Capabilities.Set(capability.Filter); err != nil {
+			return err
+		}
+	}
+
+	resp, err := s.UploadPack(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("requesting packfile: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if len(resp.Shallows) > 0 {
+		shallows, err := st.Shallow()
+		if err != nil {
+			return fmt.Errorf("reading shallow commits: %w", err)
+		}
+		if err := st.SetShallow(append(shallows, resp.Shallows...)); err != nil {
+			return fmt.Errorf("recording shallow commits: %w", err)
+		}
+	}
+
+	var r io.Reader = resp
+	var demuxer *sideband.Demuxer
+	switch {
+	case req.Capabilities.Supports(capability.Sideband64k):
+		demuxer = sideband.NewDemuxer(sideband.Sideband64k, resp)
+	case req.Capabilities.Supports(capability.Sideband):
+		demuxer = sideband.NewDemuxer(sideband.Sideband, resp)
+	}
+	if demuxer != nil {
+		demuxer.Progress = progress
+		r = demuxer
+	}
+	if err := packfile.UpdateObjectStorage(st, r); err != nil {
+		return fmt.Errorf("storing packfile: %w", err)
+	}
+	return nil
+}
+
+// promisorStorer wraps the storer of a partial clone to fetch the blobs
+// missing in it from the remote when they are read, just as git does with
+// promisor remotes.
+type promisorStorer struct {
+	storage.Storer
+	repourl string
+	auth    transport.AuthMethod
+
+	mu sync.Mutex
+}
+
+// EncodedObject returns an object from the storer, fetching missing blobs
+func (s *promisorStorer) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := s.Storer.EncodedObject(t, h)
+	if t != plumbing.BlobObject || !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return obj, err
+	}
+	if err := s.fetchBlobs([]plumbing.Hash{h}); err != nil {
+		return nil, err
+	}
+	return s.Storer.EncodedObject(t, h)
+}
+
+// fetchBlobs fetches the listed blobs that are missing in the storer
+func (s *promisorStorer) fetchBlobs(hashes []plumbing.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	missing := []plumbing.Hash{}
+	for _, h := range hashes {
+		if err := s.Storer.HasEncodedObject(h); errors.Is(err, plumbing.ErrObjectNotFound) {
+			missing = append(missing, h)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	sess, err := openUploadPack(s.repourl, s.auth)
+	if err != nil {
+		return err
+	}
+	defer sess.Close() //nolint:errcheck
+	if err := sess.fetchPack(s.Storer, missing, nil, "", nil); err != nil {
+		return fmt.Errorf("fetching %d missing blobs: %w", len(missing), err)
+	}
+	return nil
+}
+
+// packClone initializes a repository and fetches a reference from the
+// remote speaking the upload-pack protocol directly, which allows requests
+// not supported by go-git clones: shallow fetches by date and partial
+// clones. If filter is set, the repository storer fetches the objects
+// omitted by the filter when read. An empty ref fetches the remote HEAD.
+// It returns the commit the reference points to. The progress messages of
+// the remote are written to progress, if set.
+func packClone(st storage.Storer, fsobj billy.Filesystem, repourl, ref string, auth transport.AuthMethod, depth packp.Depth, filter packp.Filter, progress io.Writer) (*git.Repository, plumbing.Hash, error) {
+	sess, err := openUploadPack(repourl, auth)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+	defer sess.Close() //nolint:errcheck
+
+	refs, err := sess.advRefs.AllReferences()
+	if err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("reading advertised references: %w", err)
+	}
+	name := plumbing.HEAD
+	if ref != "" {
+		name = plumbing.ReferenceName(ref)
+	}
+	target, err := storer.ResolveReference(refs, name)
+	if err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("resolving remote reference %q: %w", name, err)
+	}
+
+	repo, err := initPackRepo(st, fsobj, repourl, auth, filter)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	if err := sess.fetchPack(st, []plumbing.Hash{target.Hash()}, depth, filter, progress); err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("fetching %q: %w", name, err)
+	}
+
+	// Record the reference and point HEAD to it as a clone would
+	if err := st.SetReference(plumbing.NewHashReference(target.Name(), target.Hash())); err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("storing reference: %w", err)
+	}
+	head := plumbing.NewHashReference(plumbing.HEAD, target.Hash())
+	if target.Name().IsBranch() {
+		head = plumbing.NewSymbolicReference(plumbing.HEAD, target.Name())
+	}
+	if err := st.SetReference(head); err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("storing HEAD: %w", err)
+	}
+
+	commit, err := peelToCommit(repo, target.Hash())
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+	return repo, commit.Hash, nil
+}
+
+// errWantNotAllowed is returned when the remote does not allow fetching
+// commits by hash
+var errWantNotAllowed = errors.New("remote does not allow fetching commits by hash")
+
+// commitClone initializes a repository and fetches a single commit by its
+// hash, without the history of the branches containing it. The remote must
+// advertise allow-reachable-sha1-in-want, otherwise errWantNotAllowed is
+// returned so the caller can fall back to cloning a branch. HEAD is left
+// detached at the commit. The progress messages of the remote are written
+// to progress, if set.
+func commitClone(st storage.Storer, fsobj billy.Filesystem, repourl string, commit plumbing.Hash, auth transport.AuthMethod, depth packp.Depth, filter packp.Filter, progress io.Writer) (*git.Repository, error) {
+	sess, err := openUploadPack(repourl, auth)
+	if err != nil {
+		return nil, err
+	}
+	defer sess.Close() //nolint:errcheck
+
+	if !sess.advRefs.Capabilities.Supports(capability.AllowReachableSHA1InWant) {
+		return nil, errWantNotAllowed
+	}
+
+	repo, err := initPackRepo(st, fsobj, repourl, auth, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sess.fetchPack(st, []plumbing.Hash{commit}, depth, filter, progress); err != nil {
+		return nil, fmt.Errorf("fetching commit %s: %w", commit, err)
+	}
+	if err := st.SetReference(plumbing.NewHashReference(plumbing.HEAD, commit)); err != nil {
+		return nil, fmt.Errorf("storing HEAD: %w", err)
+	}
+	return repo, nil
+}
+
+// initPackRepo initializes the repository fetched by packClone and
+// commitClone with the remote as its origin. When a filter is set, the
+// storer is wrapped to fetch the objects it omits when read.
+func initPackRepo(st storage.Storer, fsobj billy.Filesystem, repourl string, auth transport.AuthMethod, filter packp.Filter) (*git.Repository, error) {
+	var repoStorer storage.Storer = st
+	if filter != "" {
+		repoStorer = &promisorStorer{Storer: st, repourl: repourl, auth: auth}
+	}
+	repo, err := git.Init(repoStorer, fsobj)
+	if err != nil {
+		return nil, fmt.Errorf("initializing repo: %w", err)
+	}
+	if _, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repourl},
+	}); err != nil {
+		return nil, fmt.Errorf("creating remote: %w", err)
+	}
+	return repo, nil
+}
+
+// prefetchBlobs fetches in a single request the blobs under subpath in the
+// tree of a commit of a partial clone, so reading them does not trigger a
+// request per file. It is a no-op if the repository is not a partial clone
+// or the subpath does not exist.
+func prefetchBlobs(repo *git.Repository, commitHash, subpath string) error {
+	promisor, ok := repo.Storer.(*promisorStorer)
+	if !ok {
+		return nil
+	}
+
+	commit, err := repo.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return fmt.Errorf("reading commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("reading commit tree: %w", err)
+	}
+
+	subpath = strings.Trim(subpath, "/")
+	if subpath != "" {
+		entry, err := tree.FindEntry(subpath)
+		if err != nil {
+			return nil
+		}
+		switch entry.Mode {
+		case filemode.Dir:
+			if tree, err = tree.Tree(subpath); err != nil {
+				return fmt.Errorf("reading tree %q: %w", subpath, err)
+			}
+		case filemode.Submodule:
+			return nil
+		default:
+			return promisor.fetchBlobs([]plumbing.Hash{entry.Hash})
+		}
+	}
+
+	hashes := []plumbing.Hash{}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		_, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("walking tree: %w", err)
+		}
+		if entry.Mode != filemode.Dir && entry.Mode != filemode.Submodule {
+			hashes = append(hashes, entry.Hash)
+		}
+	}
+	return promisor.fetchBlobs(hashes)
+}
@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+// allowPartialClones configures a test repository to serve partial clones
+func allowPartialClones(t *testing.T, repoDir string) {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.Raw.Section("uploadpack").SetOption("allowFilter", "true")
+	cfg.Raw.Section("uploadpack").SetOption("allowAnySHA1InWant", "true")
+	require.NoError(t, repo.SetConfig(cfg))
+}
+
+func TestPartialClone(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git-upload-pack"); err != nil {
+		t.Skip("git-upload-pack binary not found")
+	}
+
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{
+		"docs/guide.md": "guide\n",
+		"docs/api.md":   "api\n",
+		"big.bin":       "large file\n",
+	})
+	second := commitTestFile(t, repoDir, "docs/guide.md", "guide v2\n")
+	allowPartialClones(t, repoDir)
+
+	bigBlob := plumbing.ComputeHash(plumbing.BlobObject, []byte("large file\n"))
+
+	t.Run("blobs-fetched-lazily", func(t *testing.T) {
+		t.Parallel()
+		opts := defaultOptions
+		opts.PartialClone = true
+		opts.noCheckout = true
+		cloned, err := cloneRepo(Locator(fileLocator(repoDir, "refs/heads/master", "docs/guide.md")), &opts)
+		require.NoError(t, err)
+		require.Equal(t, second, cloned.Commit)
+
+		promisor, ok := cloned.Repo.Storer.(*promisorStorer)
+		require.True(t, ok)
+		require.ErrorIs(t, promisor.Storer.HasEncodedObject(bigBlob), plumbing.ErrObjectNotFound)
+
+		// Reading the blob fetches it from the remote
+		blob, err := cloned.Repo.BlobObject(bigBlob)
+		require.NoError(t, err)
+		require.Equal(t, int64(11), blob.Size)
+		require.NoError(t, promisor.Storer.HasEncodedObject(bigBlob))
+	})
+
+	t.Run("copy-file", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(fileLocator(repoDir, "", "docs/guide.md"), &buf, WithPartialClone(true)))
+		require.Equal(t, "guide v2\n", buf.String())
+
+		buf.Reset()
+		require.NoError(t, CopyFile(fileLocator(repoDir, first, "docs/guide.md"), &buf, WithPartialClone(true)))
+		require.Equal(t, "guide\n", buf.String())
+	})
+
+	t.Run("download", func(t *testing.T) {
+		t.Parallel()
+		dest := filepath.Join(t.TempDir(), "out")
+		require.NoError(t, Download(fileLocator(repoDir, "refs/heads/master", "docs"), dest, WithPartialClone(true)))
+		data, err := os.ReadFile(filepath.Join(dest, "docs", "api.md"))
+		require.NoError(t, err)
+		require.Equal(t, "api\n", string(data))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+		plainDir, commit := initTestRepoWithFiles(t, map[string]string{"a.txt": "a"})
+		err := CopyFile(fileLocator(plainDir, commit, "a.txt"), &bytes.Buffer{}, WithPartialClone(true))
+		require.Error(t, err)
+	})
+}
+
+func TestShallowSince(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git-upload-pack"); err != nil {
+		t.Skip("git-upload-pack binary not found")
+	}
+
+	repoDir, _ := initTestRepoWithFiles(t, map[string]string{"a.txt": "a"})
+	old := commitTestFileAt(t, repoDir, "a.txt", "old", time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC))
+	mid := commitTestFileAt(t, repoDir, "a.txt", "mid", time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC))
+	last := commitTestFileAt(t, repoDir, "a.txt", "last", time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC))
+
+	commits, err := Log(
+		fileLocator(repoDir, "refs/heads/master", "a.txt"), 0,
+		WithShallowSince(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)),
+	)
+	require.NoError(t, err)
+	hashes := []string{}
+	for i := range commits {
+		hashes = append(hashes, commits[i].Hash)
+	}
+	require.Equal(t, []string{last, mid}, hashes)
+
+	var buf bytes.Buffer
+	require.NoError(t, CopyFile(
+		fileLocator(repoDir, mid, "a.txt"), &buf,
+		WithShallowSince(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)),
+	))
+	require.Equal(t, "mid", buf.String())
+
+	err = CopyFile(
+		fileLocator(repoDir, old, "a.txt"), &buf,
+		WithShallowSince(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)),
+	)
+	require.Error(t, err)
+}
+
+func TestCommitClone(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git-upload-pack"); err != nil {
+		t.Skip("git-upload-pack binary not found")
+	}
+
+	// The pinned commit is only reachable from a branch other than HEAD
+	repoDir, first := initTestRepoWithFiles(t, map[string]string{"a.txt": "a"})
+	feature := commitTestFile(t, repoDir, "a.txt", "feature")
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/feature", plumbing.NewHash(feature))))
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/master", plumbing.NewHash(first))))
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.Raw.Section("uploadpack").SetOption("allowReachableSHA1InWant", "true")
+	require.NoError(t, repo.SetConfig(cfg))
+
+	t.Run("by-hash", func(t *testing.T) {
+		t.Parallel()
+		opts := defaultOptions
+		opts.noCheckout = true
+		cloned, err := cloneRepo(Locator(fileLocator(repoDir, feature, "a.txt")), &opts)
+		require.NoError(t, err)
+		require.Equal(t, feature, cloned.Commit)
+
+		// Only the pinned commit is fetched
+		shallows, err := cloned.Repo.Storer.Shallow()
+		require.NoError(t, err)
+		require.Equal(t, []plumbing.Hash{plumbing.NewHash(feature)}, shallows)
+
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(fileLocator(repoDir, feature, "a.txt"), &buf))
+		require.Equal(t, "feature", buf.String())
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		t.Parallel()
+		var progress bytes.Buffer
+		opts := defaultOptions
+		opts.noCheckout = true
+		opts.Progress = &progress
+		_, err := cloneRepo(Locator(fileLocator(repoDir, feature, "a.txt")), &opts)
+		require.NoError(t, err)
+		require.Contains(t, progress.String(), "objects")
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		t.Parallel()
+		plainDir, commit := initTestRepoWithFiles(t, map[string]string{"a.txt": "a"})
+		var buf bytes.Buffer
+		require.NoError(t, CopyFile(fileLocator(plainDir, commit, "a.txt"), &buf))
+		require.Equal(t, "a", buf.String())
+	})
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Policies to handle repository paths that cannot be written on Windows
+const (
+	// IllegalNamesReject fails the download (default)
+	IllegalNamesReject = "reject"
+
+	// IllegalNamesSkip does not write the file
+	IllegalNamesSkip = "skip"
+
+	// IllegalNamesRename replaces the offending characters with an
+	// underscore and appends one to reserved device names (ie CON_)
+	IllegalNamesRename = "rename"
+)
+
+// windowsPaths is true when files are written to a Windows filesystem
+var windowsPaths = runtime.GOOS == "windows"
+
+// windowsReservedNames are the device names windows reserves, with or
+// without an extension, in every directory.
+var windowsReservedNames = map[string]struct{}{
+	"CON": {}, "PRN": {}, "AUX": {}, "NUL": {}, "CONIN$": {}, "CONOUT$": {},
+	"COM0": {}, "COM1": {}, "COM2": {}, "COM3": {}, "COM4": {},
+	"COM5": {}, "COM6": {}, "COM7": {}, "COM8": {}, "COM9": {},
+	"COM¹": {}, "COM²": {}, "COM³": {},
+	"LPT0": {}, "LPT1": {}, "LPT2": {}, "LPT3": {}, "LPT4": {},
+	"LPT5": {}, "LPT6": {}, "LPT7": {}, "LPT8": {}, "LPT9": {},
+	"LPT¹": {}, "LPT²": {}, "LPT³": {},
+}
+
+// windowsReservedChars cannot be used in windows file names. The backslash
+// is included as windows would read it as a path separator.
+const windowsReservedChars = `<>:"/\|?*`
+
+// invalidWindowsName returns the reason a path element cannot be used as a
+// file name on windows or an empty string if it is valid.
+func invalidWindowsName(name string) string {
+	for _, r := range name {
+		if r < 32 || strings.ContainsRune(windowsReservedChars, r) {
+			return "contains reserved characters"
+		}
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return "ends with a dot or space"
+	}
+	if _, ok := windowsReservedNames[windowsNameStem(name)]; ok {
+		return "is a reserved device name"
+	}
+	return ""
+}
+
+// windowsNameStem returns the part of a name windows checks against the
+// reserved device names: up to the first dot, without trailing spaces.
+func windowsNameStem(name string) string {
+	stem, _, _ := strings.Cut(name, ".")
+	return strings.ToUpper(strings.TrimRight(stem, " "))
+}
+
+// renameWindowsName rewrites a path element so it can be used on windows
+func renameWindowsName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || strings.ContainsRune(windowsReservedChars, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if trimmed := strings.TrimRight(name, ". "); trimmed != name {
+		name = trimmed + strings.Repeat("_", len(name)-len(trimmed))
+	}
+
+	if _, ok := windowsReservedNames[windowsNameStem(name)]; ok {
+		stem, ext, found := strings.Cut(name, ".")
+		name = stem + "_"
+		if found {
+			name += "." + ext
+		}
+	}
+	return name
+}
+
+// windowsSafePath checks every element of a slash separated repository path
+// and applies the policy to invalid ones. It returns the path to write the
+// file to or an empty string if the file is to be skipped.
+func windowsSafePath(p, policy string) (string, error) {
+	elements := strings.Split(p, "/")
+	for i, name := range elements {
+		reason := invalidWindowsName(name)
+		if reason == "" {
+			continue
+		}
+		switch policy {
+		case IllegalNamesSkip:
+			return "", nil
+		case IllegalNamesRename:
+			elements[i] = renameWindowsName(name)
+		default:
+			return "", fmt.Errorf("path %q cannot be written on windows: %q %s", p, name, reason)
+		}
+	}
+	return strings.Join(elements, "/"), nil
+}
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: Copyright 2025 Carabiner Systems, Inc
+// SPDX-License-Identifier: Apache-2.0
+
+package vcslocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowsSafePath(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name    string
+		path    string
+		renamed string
+	}{
+		{"valid", "src/main.go", "src/main.go"},
+		{"valid-dots", "src/.config/v1.2.txt", "src/.config/v1.2.txt"},
+		{"reserved", "docs/CON", "docs/CON_"},
+		{"reserved-lowercase-ext", "nul.txt", "nul_.txt"},
+		{"reserved-dir", "com1/file.txt", "com1_/file.txt"},
+		{"reserved-superscript", "LPT¹.log", "LPT¹_.log"},
+		{"reserved-trailing-space", "aux .c", "aux _.c"},
+		{"not-reserved", "console.txt", "console.txt"},
+		{"backslash", `dir/..\..\evil`, "dir/.._.._evil"},
+		{"reserved-chars", `a<b>:c"d|e?f*`, "a_b__c_d_e_f_"},
+		{"control-char", "tab\there", "tab_here"},
+		{"trailing-dot", "file.", "file_"},
+		{"trailing-spaces", "dir  /file", "dir__/file"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			valid := tc.path == tc.renamed
+
+			res, err := windowsSafePath(tc.path, IllegalNamesReject)
+			if valid {
+				require.NoError(t, err)
+				require.Equal(t, tc.path, res)
+			} else {
+				require.Error(t, err)
+			}
+
+			res, err = windowsSafePath(tc.path, IllegalNamesSkip)
+			require.NoError(t, err)
+			if valid {
+				require.Equal(t, tc.path, res)
+			} else {
+				require.Empty(t, res)
+			}
+
+			res, err = windowsSafePath(tc.path, IllegalNamesRename)
+			require.NoError(t, err)
+			require.Equal(t, tc.renamed, res)
+			_, err = windowsSafePath(res, IllegalNamesReject)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWithIllegalNames(t *testing.T) {
+	t.Parallel()
+	opts := defaultOptions
+	require.Equal(t, IllegalNamesReject, opts.IllegalNames)
+	require.NoError(t, WithIllegalNames(IllegalNamesRename)(&opts))
+	require.Equal(t, IllegalNamesRename, opts.IllegalNames)
+	require.Error(t, WithIllegalNames("bogus")(&opts))
+}